@@ -18,6 +18,20 @@ type Action interface {
 	Run(atMost time.Duration) Status
 }
 
+// A Cancellable action needs to know when it gets dropped before it finishes, to clean up after itself.
+type Cancellable interface {
+	Action
+	// Cancel is called at most once, after which the action does not get run anymore.
+	Cancel()
+}
+
+// Cancel tells the action that it got dropped before it finished, when it needs to know that.
+func Cancel(action Action) {
+	if c, ok := action.(Cancellable); ok {
+		c.Cancel()
+	}
+}
+
 // An Status says whether an action completed.
 // It also says how much time is left after running it.
 type Status struct {
@@ -48,10 +48,17 @@ func (c *Countdown) Action(lasting time.Duration) Action {
 	return &_CountdownAction{lasting: lasting, countdown: c}
 }
 
+// Resume creates an action that makes the countdown progress run, without resetting the progress made so far.
+// The countdown completes when the action does.
+func (c *Countdown) Resume() Action {
+	return &_CountdownAction{countdown: c, resumed: true}
+}
+
 type _CountdownAction struct {
 	once      sync.Once
 	lasting   time.Duration
 	countdown *Countdown
+	resumed   bool
 }
 
 func (action *_CountdownAction) Run(atMost time.Duration) Status {
@@ -64,6 +71,9 @@ func (action *_CountdownAction) Run(atMost time.Duration) Status {
 }
 
 func (action *_CountdownAction) init() {
+	if action.resumed {
+		return
+	}
 	needed := int(action.lasting)
 	action.countdown.ResetTarget(needed)
 }
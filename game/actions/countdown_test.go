@@ -190,3 +190,61 @@ func TestCountdownOver(t *testing.T) {
 		})
 	}
 }
+
+func TestCountdownResume(t *testing.T) {
+	tests := map[string]struct {
+		Elapsed time.Duration
+		Step    time.Duration
+
+		Status   actions.Status
+		Progress float64
+	}{
+		"NotStarted": {
+			Step: time.Second,
+
+			Progress: 0.5,
+		},
+		"Halfway": {
+			Elapsed: time.Second,
+			Step:    time.Second / 2,
+
+			Progress: 0.75,
+		},
+		"Completed": {
+			Elapsed: time.Second,
+			Step:    time.Second,
+
+			Status:   actions.Done(0),
+			Progress: 1,
+		},
+		"Completed/WithTimeToSpare": {
+			Elapsed: time.Second,
+			Step:    2 * time.Second,
+
+			Status:   actions.Done(time.Second),
+			Progress: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			countdown := actions.Countdown{}
+			countdown.ResetTarget(int(2 * time.Second))
+			countdown.CountDown(int(tt.Elapsed))
+
+			action := countdown.Resume()
+
+			// when
+			status := action.Run(tt.Step)
+
+			// then
+			assert.That(
+				status == tt.Status,
+				t.Errorf, "got status %#v, want %#v", status, tt.Status)
+			assert.That(
+				countdown.Progress() == tt.Progress,
+				t.Errorf, "got progress %f, want %f", countdown.Progress(), tt.Progress)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package actions
+
+import (
+	"time"
+)
+
+// A Queue runs actions one after another, while letting actions be added and replaced as it goes.
+//
+// Unlike a sequence, a queue carries on with the next action when one gets interrupted.
+// Once it runs out of actions it is done, until more get pushed.
+//
+// The zero value is an empty queue.
+type Queue struct {
+	actions []Action
+}
+
+var _ Action = new(Queue)
+
+// Push adds an action at the end of the queue.
+func (q *Queue) Push(action Action) {
+	q.actions = append(q.actions, action)
+}
+
+// Replace drops all the queued actions, including the one running at the moment, and queues the new ones instead.
+// The dropped actions get cancelled.
+func (q *Queue) Replace(actions ...Action) {
+	q.cancelAll()
+	q.actions = append(q.actions[:0:0], actions...)
+}
+
+// Clear drops all the queued actions, cancelling them.
+func (q *Queue) Clear() {
+	q.cancelAll()
+	q.actions = nil
+}
+
+func (q *Queue) cancelAll() {
+	for _, action := range q.actions {
+		Cancel(action)
+	}
+}
+
+// Idle says whether the queue has no actions left to run.
+func (q *Queue) Idle() bool { return len(q.actions) == 0 }
+
+// Len is the number of actions left in the queue, counting the one running at the moment.
+func (q *Queue) Len() int { return len(q.actions) }
+
+func (q *Queue) Run(atMost time.Duration) Status {
+	timeLeft := atMost
+	for len(q.actions) > 0 {
+		status := q.actions[0].Run(timeLeft)
+		if !status.Done() && !status.Interrupted() {
+			return Paused()
+		}
+		q.actions = q.actions[1:]
+		timeLeft = status.TimeLeft()
+	}
+	return Done(timeLeft)
+}
@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package actions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+func TestQueue(t *testing.T) {
+	tests := map[string]struct {
+		Actions []actions.Action
+		Times   []time.Duration
+
+		Status actions.Status
+		Left   int
+	}{
+		"Empty": {
+			Times:  []time.Duration{time.Second},
+			Status: actions.Done(time.Second),
+		},
+
+		"SingleAction/InsufficientTime": {
+			Actions: []actions.Action{actions.Wait(3 * time.Second)},
+			Times:   []time.Duration{2 * time.Second},
+			Status:  actions.Paused(),
+			Left:    1,
+		},
+		"SingleAction/TimeToSpare": {
+			Actions: []actions.Action{actions.Wait(time.Second)},
+			Times:   []time.Duration{3 * time.Second},
+			Status:  actions.Done(2 * time.Second),
+		},
+
+		"TwoActions/FirstCompleted": {
+			Actions: []actions.Action{actions.Wait(time.Second), actions.Wait(time.Second)},
+			Times:   []time.Duration{3 * time.Second / 2},
+			Status:  actions.Paused(),
+			Left:    1,
+		},
+		"TwoActions/BothCompleted": {
+			Actions: []actions.Action{actions.Wait(time.Second), actions.Wait(time.Second)},
+			Times:   []time.Duration{time.Second, 2 * time.Second},
+			Status:  actions.Done(time.Second),
+		},
+
+		"Interrupted/CarriesOn": {
+			Actions: []actions.Action{actions.Interrupt(), actions.Wait(time.Second)},
+			Times:   []time.Duration{3 * time.Second / 2},
+			Status:  actions.Done(time.Second / 2),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			queue := actions.Queue{}
+			for _, action := range tt.Actions {
+				queue.Push(action)
+			}
+
+			// when
+			var status actions.Status
+			for _, dt := range tt.Times {
+				status = queue.Run(dt)
+			}
+
+			// then
+			assert.That(
+				status == tt.Status,
+				t.Errorf, "got status %#v, want %#v", status, tt.Status)
+			assert.That(
+				queue.Len() == tt.Left,
+				t.Errorf, "got %d actions left, want %d", queue.Len(), tt.Left)
+		})
+	}
+}
+
+func TestQueueReplace(t *testing.T) {
+	// given
+	queue := actions.Queue{}
+	queue.Push(actions.Wait(time.Hour))
+	queue.Run(time.Second)
+
+	// when
+	queue.Replace(actions.Wait(time.Second))
+	status := queue.Run(2 * time.Second)
+
+	// then
+	assert.That(
+		status == actions.Done(time.Second),
+		t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second))
+	assert.That(queue.Idle(), t.Errorf, "the queue is not idle")
+}
+
+func TestQueueClear(t *testing.T) {
+	// given
+	queue := actions.Queue{}
+	queue.Push(actions.Wait(time.Hour))
+
+	// when
+	queue.Clear()
+
+	// then
+	assert.That(queue.Idle(), t.Errorf, "the queue is not idle")
+}
+
+func TestQueueCancelsDroppedActions(t *testing.T) {
+	tests := map[string]struct {
+		Drop func(queue *actions.Queue)
+	}{
+		"Replace": {Drop: func(queue *actions.Queue) { queue.Replace(actions.Wait(time.Second)) }},
+		"Clear":   {Drop: (*actions.Queue).Clear},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			running, queued := &_Cancellable{}, &_Cancellable{}
+			queue := actions.Queue{}
+			queue.Push(actions.Sequence(running))
+			queue.Push(queued)
+			queue.Run(time.Second)
+
+			// when
+			tt.Drop(&queue)
+
+			// then
+			assert.That(running.cancelled == 1, t.Errorf, "the running action got cancelled %d times, want %d", running.cancelled, 1)
+			assert.That(queued.cancelled == 1, t.Errorf, "the queued action got cancelled %d times, want %d", queued.cancelled, 1)
+		})
+	}
+}
+
+func TestQueueDoesNotCancelFinishedActions(t *testing.T) {
+	// given
+	finished := &_Cancellable{done: true}
+	queue := actions.Queue{}
+	queue.Push(finished)
+	queue.Run(time.Second)
+
+	// when
+	queue.Clear()
+
+	// then
+	assert.That(finished.cancelled == 0, t.Errorf, "the finished action got cancelled %d times", finished.cancelled)
+}
+
+type _Cancellable struct {
+	done      bool
+	cancelled int
+}
+
+func (c *_Cancellable) Run(atMost time.Duration) actions.Status {
+	if c.done {
+		return actions.Done(atMost)
+	}
+	return actions.Paused()
+}
+
+func (c *_Cancellable) Cancel() { c.cancelled++ }
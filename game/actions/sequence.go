@@ -28,6 +28,14 @@ func (seq *_Sequence) Run(atMost time.Duration) Status {
 	return Paused()
 }
 
+// Cancel cancels the steps that are left.
+func (seq *_Sequence) Cancel() {
+	for _, step := range seq.steps {
+		Cancel(step)
+	}
+	seq.steps = nil
+}
+
 func (seq *_Sequence) hasStepsLeft() bool {
 	return len(seq.steps) > 0
 }
@@ -0,0 +1,241 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package ai makes entities act on their own.
+//
+// A brain looks at what each of its units could do, scores every option and has the unit do the one that scores best.
+// What the options are and how they score is up to the considerations the brain is given.
+// Units can also be made to follow a behaviour tree instead, built with the library of the brain.
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/behaviour"
+	"github.com/szabba/tob-cob/game/combat"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/game/random"
+)
+
+// An Option is something a unit could do.
+type Option struct {
+	// Name tells options apart.
+	// A unit keeps doing what it does when the best option has the same name.
+	Name string
+	// Score says how good the option is.
+	// Options that do not score above zero are never picked.
+	Score float64
+	// Action does what the option is about.
+	Action actions.Action
+}
+
+// A Situation is what a unit knows when it decides what to do.
+type Situation struct {
+	World *entity.World
+	// FindPath finds ways for the unit, going through the positions it can pass.
+	FindPath func(src, dst grid.Position) (grid.Path, bool)
+	// Passable says which taken positions the unit can path through.
+	Passable func(grid.Position) bool
+	// Rolls are used for what the unit ends up doing, like attacking.
+	Rolls *rand.Rand
+
+	Unit *entity.Entity
+	// Enemies are the hostile entities the unit can see, the nearest first.
+	Enemies []*entity.Entity
+	// SightRadius is how far the unit can see.
+	SightRadius float64
+}
+
+// A Consideration comes up with options for a unit in a situation.
+type Consideration func(s Situation) []Option
+
+// A Brain decides what its units do.
+type Brain struct {
+	World *entity.World
+	// Paths creates path searches that also go through the taken positions passable allows.
+	Paths func(passable func(grid.Position) bool) func(src, dst grid.Position) (grid.Path, bool)
+	// Choices breaks ties between equally good options.
+	Choices *rand.Rand
+	// Rolls are used for what units end up doing, like attacking.
+	Rolls *rand.Rand
+
+	// Hostile says whether one entity is the enemy of another.
+	// By default it follows the relations of the world.
+	Hostile func(a, b *entity.Entity) bool
+	// Passable says which taken positions a unit can path through.
+	// By default those are the ones its allies take.
+	Passable func(e *entity.Entity) func(grid.Position) bool
+	// SightRadius is how far units can see.
+	SightRadius float64
+	// ThinkEvery is how often busy units decide what to do again.
+	// Idle units decide right away.
+	ThinkEvery time.Duration
+	// Considerations come up with the options units choose from.
+	Considerations []Consideration
+
+	minds map[*entity.Entity]*_Mind
+}
+
+// _Mind is what the brain remembers about a unit.
+type _Mind struct {
+	sinceThought time.Duration
+	doing        string
+	tree         behaviour.Node
+}
+
+// NewBrain creates a brain with the default considerations: attacking, approaching enemies, retreating to cover and waiting.
+// It makes its choices with the AI stream of the service, and its units attack with the combat one.
+func NewBrain(
+	world *entity.World,
+	paths func(passable func(grid.Position) bool) func(src, dst grid.Position) (grid.Path, bool),
+	rng *random.Service,
+) *Brain {
+	return &Brain{
+		World:       world,
+		Paths:       paths,
+		Choices:     rng.Stream(random.AI),
+		Rolls:       rng.Stream(random.Combat),
+		Hostile:     world.Relations().Hostile,
+		Passable:    world.PassableFor,
+		SightRadius: 10,
+		ThinkEvery:  time.Second,
+		Considerations: []Consideration{
+			Attack(),
+			Approach(),
+			Retreat(),
+			Wait(),
+		},
+		minds: map[*entity.Entity]*_Mind{},
+	}
+}
+
+// Follow makes the unit follow the behaviour tree instead of weighing the considerations.
+// The tree is run again from the top each time the unit runs out of things to do.
+func (b *Brain) Follow(unit *entity.Entity, tree behaviour.Node) {
+	b.mind(unit).tree = tree
+}
+
+// Update lets the units decide what to do, when it is time for them to.
+// Units that change their mind have their actions replaced.
+// Units that follow a tree go through it again when they are idle.
+func (b *Brain) Update(dt time.Duration, units []*entity.Entity) {
+	for unit := range b.minds {
+		if !b.alive(unit) {
+			delete(b.minds, unit)
+		}
+	}
+
+	for _, unit := range units {
+		if !b.alive(unit) {
+			continue
+		}
+		mind := b.mind(unit)
+		if mind.tree != nil {
+			if unit.Actions.Idle() {
+				unit.Actions.Push(mind.tree())
+			}
+			continue
+		}
+
+		mind.sinceThought += dt
+		if !unit.Actions.Idle() && mind.sinceThought < b.ThinkEvery {
+			continue
+		}
+		mind.sinceThought = 0
+
+		option, ok := b.Decide(unit)
+		if !ok || (!unit.Actions.Idle() && option.Name == mind.doing) {
+			continue
+		}
+		mind.doing = option.Name
+		unit.Actions.Replace(option.Action)
+	}
+}
+
+// Decide picks the best option for the unit.
+// Ties are broken at random.
+// It reports false when no option scores above zero.
+func (b *Brain) Decide(unit *entity.Entity) (Option, bool) {
+	return b.pick(b.situation(unit), b.Considerations...)
+}
+
+// pick chooses the best option the considerations come up with in the situation.
+func (b *Brain) pick(s Situation, considerations ...Consideration) (Option, bool) {
+	var best []Option
+	for _, consider := range considerations {
+		for _, option := range consider(s) {
+			switch {
+			case option.Score <= 0:
+			case len(best) == 0 || option.Score > best[0].Score:
+				best = append(best[:0], option)
+			case option.Score == best[0].Score:
+				best = append(best, option)
+			}
+		}
+	}
+
+	if len(best) == 0 {
+		return Option{}, false
+	}
+	return best[b.Choices.Intn(len(best))], true
+}
+
+func (b *Brain) mind(unit *entity.Entity) *_Mind {
+	mind, ok := b.minds[unit]
+	if !ok {
+		mind = &_Mind{}
+		b.minds[unit] = mind
+	}
+	return mind
+}
+
+func (b *Brain) situation(unit *entity.Entity) Situation {
+	passable := b.Passable(unit)
+	s := Situation{
+		World:       b.World,
+		FindPath:    b.Paths(passable),
+		Passable:    passable,
+		Rolls:       b.Rolls,
+		Unit:        unit,
+		SightRadius: b.SightRadius,
+	}
+
+	at := unit.Placement.AtPoint()
+	for _, other := range b.World.Near(at, b.SightRadius) {
+		if other != unit && b.Hostile(unit, other) && combat.Unobstructed(b.World, at, other.Placement.AtPoint(), unit, other) {
+			s.Enemies = append(s.Enemies, other)
+		}
+	}
+	sort.SliceStable(s.Enemies, func(i, j int) bool {
+		di, dj := distance(at, s.Enemies[i].Placement.AtPoint()), distance(at, s.Enemies[j].Placement.AtPoint())
+		if di != dj {
+			return di < dj
+		}
+		return s.Enemies[i].ID() < s.Enemies[j].ID()
+	})
+	return s
+}
+
+func (b *Brain) alive(e *entity.Entity) bool {
+	found, ok := b.World.Get(e.ID())
+	return ok && found == e
+}
+
+// distance between two points in a straight line.
+func distance(a, b grid.Point) float64 {
+	return math.Hypot(float64(a.Row-b.Row), float64(a.Column-b.Column))
+}
+
+// healthLeft is the part of its health the entity still has.
+func healthLeft(e *entity.Entity) float64 {
+	if e.Stats.MaxHealth <= 0 {
+		return 0
+	}
+	return float64(e.Stats.Health) / float64(e.Stats.MaxHealth)
+}
@@ -0,0 +1,233 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ai_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/ai"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/game/random"
+)
+
+const (
+	red  entity.Faction = "red"
+	blue entity.Faction = "blue"
+)
+
+func TestUnitNextToEnemyAttacksIt(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	spawn(world, grid.P(0, 1), blue)
+	brain := newBrain(world, 1)
+
+	// when
+	option, ok := brain.Decide(unit)
+
+	// then
+	assert.That(ok && strings.HasPrefix(option.Name, "melee"), t.Errorf, "got option %q (%v), want a melee attack", option.Name, ok)
+}
+
+func TestUnitShootsEnemyInRange(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	spawn(world, grid.P(0, 4), blue)
+	brain := newBrain(world, 1)
+
+	// when
+	option, ok := brain.Decide(unit)
+
+	// then
+	assert.That(ok && strings.HasPrefix(option.Name, "shoot"), t.Errorf, "got option %q (%v), want shooting", option.Name, ok)
+}
+
+func TestUnitApproachesEnemyOutOfReach(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	unit.Stats.Range = 0
+	enemy := spawn(world, grid.P(0, 6), blue)
+	brain := newBrain(world, 1)
+
+	option, ok := brain.Decide(unit)
+	assert.That(ok && strings.HasPrefix(option.Name, "approach"), t.Fatalf, "got option %q (%v), want an approach", option.Name, ok)
+
+	// when
+	for tick := 0; tick < 40; tick++ {
+		brain.Update(time.Second/10, []*entity.Entity{unit})
+		world.Update(time.Second / 10)
+	}
+
+	// then
+	at, enemyAt := unit.Placement.AtPoint(), enemy.Placement.AtPoint()
+	assert.That(enemyAt.Column-at.Column == 1, t.Errorf, "unit at %v did not get next to the enemy at %v", at, enemyAt)
+}
+
+func TestUnitApproachesEnemyPastAlly(t *testing.T) {
+	// given
+	space := rectSpace(3, 3)
+	for _, pt := range []grid.Point{grid.P(1, 0), grid.P(2, 0), grid.P(2, 1)} {
+		space.At(pt).Destroy()
+	}
+	world := entity.NewWorld(space)
+	unit := spawn(world, grid.P(0, 0), red)
+	unit.Stats.Range = 0
+	spawn(world, grid.P(0, 1), red)
+	spawn(world, grid.P(2, 2), blue)
+	brain := newBrain(world, 1)
+
+	// when
+	option, ok := brain.Decide(unit)
+
+	// then
+	assert.That(ok && strings.HasPrefix(option.Name, "approach"), t.Errorf, "got option %q (%v), want an approach", option.Name, ok)
+}
+
+func TestHurtUnitRetreatsToCover(t *testing.T) {
+	// given
+	space := rectSpace(3, 7)
+	space.At(grid.P(0, 2)).Destroy()
+	space.At(grid.P(1, 2)).Destroy()
+	world := entity.NewWorld(space)
+
+	unit := spawn(world, grid.P(2, 3), red)
+	unit.Stats.Health = 2
+	enemy := spawn(world, grid.P(0, 5), blue)
+	brain := newBrain(world, 1)
+
+	assert.That(space.InSight(enemy.Placement.AtPoint(), unit.Placement.AtPoint()), t.Fatalf, "the unit starts out hidden")
+	option, ok := brain.Decide(unit)
+	assert.That(ok && option.Name == "retreat", t.Fatalf, "got option %q (%v), want a retreat", option.Name, ok)
+
+	// when
+	for tick := 0; tick < 40; tick++ {
+		brain.Update(time.Second/10, []*entity.Entity{unit})
+		world.Update(time.Second / 10)
+	}
+
+	// then
+	at := unit.Placement.AtPoint()
+	assert.That(!space.InSight(enemy.Placement.AtPoint(), at), t.Errorf, "unit at %v can still be seen", at)
+}
+
+func TestUnitWithoutEnemiesWaits(t *testing.T) {
+	kases := map[string]entity.Faction{
+		"ally":       red,
+		"no faction": entity.NoFaction,
+	}
+
+	for name, faction := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			world := entity.NewWorld(rectSpace(1, 8))
+			unit := spawn(world, grid.P(0, 0), red)
+			spawn(world, grid.P(0, 1), faction)
+			brain := newBrain(world, 1)
+
+			// when
+			option, ok := brain.Decide(unit)
+
+			// then
+			assert.That(ok && option.Name == "wait", t.Errorf, "got option %q (%v), want to wait", option.Name, ok)
+		})
+	}
+}
+
+func TestEnemiesOutOfSightAreIgnored(t *testing.T) {
+	// given
+	space := rectSpace(1, 8)
+	space.At(grid.P(0, 3)).Destroy()
+	world := entity.NewWorld(space)
+	unit := spawn(world, grid.P(0, 0), red)
+	spawn(world, grid.P(0, 5), blue)
+	brain := newBrain(world, 1)
+
+	// when
+	option, ok := brain.Decide(unit)
+
+	// then
+	assert.That(ok && option.Name == "wait", t.Errorf, "got option %q (%v), want to wait", option.Name, ok)
+}
+
+func TestTiesAreBrokenTheSameWayForTheSameSeed(t *testing.T) {
+	picked := map[string]bool{}
+	for seed := int64(0); seed < 20; seed++ {
+		// given
+		first, second := tiedScenario(seed), tiedScenario(seed)
+
+		// when
+		a, _ := first.brain.Decide(first.unit)
+		b, _ := second.brain.Decide(second.unit)
+
+		// then
+		assert.That(a.Name == b.Name, t.Errorf, "seed %d: got %q and %q", seed, a.Name, b.Name)
+		picked[a.Name] = true
+	}
+	assert.That(len(picked) == 2, t.Errorf, "got picks %v, want both tied options picked for some seeds", picked)
+}
+
+func TestUnitKeepsDoingWhatItChose(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	enemy := spawn(world, grid.P(0, 1), blue)
+	enemy.Stats.Defence = -100
+
+	brain := newBrain(world, 1)
+	brain.ThinkEvery = unit.Stats.AttackTime / 4
+
+	// when
+	for tick := 0; tick < 20; tick++ {
+		brain.Update(unit.Stats.AttackTime/4, []*entity.Entity{unit})
+		world.Update(unit.Stats.AttackTime / 4)
+	}
+
+	// then
+	assert.That(enemy.Stats.Health < entity.DefaultStats().Health, t.Errorf, "the attacks never landed")
+}
+
+type scenario struct {
+	brain *ai.Brain
+	unit  *entity.Entity
+}
+
+// tiedScenario has a unit right between two enemies that are just the same.
+func tiedScenario(seed int64) scenario {
+	world := entity.NewWorld(rectSpace(1, 3))
+	unit := spawn(world, grid.P(0, 1), red)
+	spawn(world, grid.P(0, 0), blue)
+	spawn(world, grid.P(0, 2), blue)
+	return scenario{newBrain(world, seed), unit}
+}
+
+func newBrain(world *entity.World, seed int64) *ai.Brain {
+	paths := func(passable func(grid.Position) bool) func(src, dst grid.Position) (grid.Path, bool) {
+		return grid.NewPathFinder(world.Space()).Through(passable).FindPath
+	}
+	return ai.NewBrain(world, paths, random.New(seed))
+}
+
+func spawn(world *entity.World, pt grid.Point, faction entity.Faction) *entity.Entity {
+	e, _ := world.Spawn(pt)
+	e.Faction = faction
+	return e
+}
+
+func rectSpace(rows, columns int) *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	return space
+}
@@ -0,0 +1,204 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ai
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/combat"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+// How many enemies are considered for approaching, and how many hiding places for retreating.
+const (
+	_ApproachCandidates = 3
+	_CoverCandidates    = 8
+)
+
+// Attack considers attacking every enemy in reach - hand to hand when right next to it, and shooting otherwise.
+//
+// It scores between 0.4 and 1.
+// Enemies that are easier to hit and ones that are more hurt score better.
+func Attack() Consideration {
+	return func(s Situation) []Option {
+		var options []Option
+		for _, enemy := range s.Enemies {
+			score := 0.4 + 0.4*combat.HitChance(s.Unit.Stats, enemy.Stats) + 0.2*(1-healthLeft(enemy))
+			switch {
+			case combat.InMeleeReach(s.World, s.Unit, enemy):
+				options = append(options, Option{
+					Name:   fmt.Sprintf("melee %d", enemy.ID()),
+					Score:  score,
+					Action: combat.Melee(s.World, s.Unit, enemy, s.Rolls),
+				})
+			case combat.InShootingRange(s.World, s.Unit, enemy):
+				options = append(options, Option{
+					Name:   fmt.Sprintf("shoot %d", enemy.ID()),
+					Score:  score,
+					Action: combat.Ranged(s.World, s.Unit, enemy, s.Rolls),
+				})
+			}
+		}
+		return options
+	}
+}
+
+// Approach considers chasing the enemy that is the fewest steps away, out of the few nearest ones.
+//
+// It scores between 0.1 and 0.4, more the healthier the unit is.
+func Approach() Consideration {
+	return func(s Situation) []Option {
+		var (
+			closest  Option
+			shortest int
+		)
+		for i, enemy := range s.Enemies {
+			if i >= _ApproachCandidates {
+				break
+			}
+			if combat.InMeleeReach(s.World, s.Unit, enemy) {
+				continue
+			}
+			steps, ok := stepsToNextTo(s, enemy.Placement.AtPoint())
+			if !ok || (closest.Action != nil && steps >= shortest) {
+				continue
+			}
+			shortest = steps
+			closest = Option{
+				Name:  fmt.Sprintf("approach %d", enemy.ID()),
+				Score: 0.1 + 0.3*healthLeft(s.Unit),
+				Action: s.Unit.Placement.Pursue(&enemy.Placement, grid.Pursuit{
+					Through:     s.Passable,
+					StepTime:    s.Unit.Stats.StepTime,
+					Range:       1,
+					ReplanEvery: time.Second,
+					MaxFailures: 3,
+				}),
+			}
+		}
+		if closest.Action == nil {
+			return nil
+		}
+		return []Option{closest}
+	}
+}
+
+// Retreat considers running to the nearest place where no enemy can see the unit.
+//
+// It scores more the more hurt the unit is, up to 1.
+// A unit that is not seen by any enemy has no reason to retreat.
+func Retreat() Consideration {
+	return func(s Situation) []Option {
+		score := math.Min(1, 1.2*(1-healthLeft(s.Unit)))
+		if len(s.Enemies) == 0 || score <= 0 {
+			return nil
+		}
+
+		dst, ok := cover(s)
+		if !ok {
+			return nil
+		}
+		return []Option{{
+			Name:  "retreat",
+			Score: score,
+			Action: s.Unit.Placement.Navigate(dst, grid.Navigation{
+				FindPath:   s.FindPath,
+				StepTime:   s.Unit.Stats.StepTime,
+				Patience:   time.Second / 2,
+				MaxRepaths: 3,
+			}),
+		}}
+	}
+}
+
+// Wait considers doing nothing for a second.
+// It scores so little that anything else is better.
+func Wait() Consideration {
+	return func(s Situation) []Option {
+		return []Option{{Name: "wait", Score: 0.05, Action: actions.Wait(time.Second)}}
+	}
+}
+
+// stepsToNextTo is how many steps it takes the unit to get next to the point.
+func stepsToNextTo(s Situation, pt grid.Point) (int, bool) {
+	space := s.World.Space()
+	from := space.At(setOff(s))
+
+	best, found := 0, false
+	for _, next := range []grid.Point{
+		grid.P(pt.Row, pt.Column+1), grid.P(pt.Row+1, pt.Column),
+		grid.P(pt.Row, pt.Column-1), grid.P(pt.Row-1, pt.Column),
+	} {
+		path, ok := s.FindPath(from, space.At(next))
+		if ok && (!found || len(path)-1 < best) {
+			best, found = len(path)-1, true
+		}
+	}
+	return best, found
+}
+
+// cover is the free position hidden from all the enemies that takes the fewest steps to get to.
+// Only the few nearest hiding places within half the sight radius are considered.
+func cover(s Situation) (grid.Position, bool) {
+	space := s.World.Space()
+	at := setOff(s)
+
+	var hidden []grid.Position
+	for _, pos := range space.InRadius(at, s.SightRadius/2) {
+		if !pos.Taken() && hiddenFrom(s, pos.AtPoint()) {
+			hidden = append(hidden, pos)
+		}
+	}
+	sort.SliceStable(hidden, func(i, j int) bool {
+		a, b := hidden[i].AtPoint(), hidden[j].AtPoint()
+		if da, db := distance(at, a), distance(at, b); da != db {
+			return da < db
+		}
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Column < b.Column
+	})
+
+	var (
+		best     grid.Position
+		shortest grid.Path
+	)
+	from := space.At(at)
+	for i, pos := range hidden {
+		if i >= _CoverCandidates {
+			break
+		}
+		path, ok := s.FindPath(from, pos)
+		if ok && (shortest == nil || len(path) < len(shortest)) {
+			best, shortest = pos, path
+		}
+	}
+	return best, shortest != nil
+}
+
+// setOff is where the unit starts to go anywhere from.
+// A unit in the middle of a step finishes it first.
+func setOff(s Situation) grid.Point {
+	if s.Unit.Placement.Headed() {
+		return s.Unit.Placement.Heading()
+	}
+	return s.Unit.Placement.AtPoint()
+}
+
+// hiddenFrom says whether none of the enemies could see the unit at the point.
+func hiddenFrom(s Situation, pt grid.Point) bool {
+	for _, enemy := range s.Enemies {
+		from := enemy.Placement.AtPoint()
+		if distance(from, pt) <= s.SightRadius && combat.Unobstructed(s.World, from, pt, enemy, s.Unit) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ai
+
+import (
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/behaviour"
+	"github.com/szabba/tob-cob/game/combat"
+	"github.com/szabba/tob-cob/game/entity"
+)
+
+// Library has what behaviour trees for the unit can be built from.
+// Everything looks at the situation the unit is in at the moment it is used.
+//
+// The conditions are:
+//   - "enemy-in-sight", when the unit can see an enemy,
+//   - "enemy-in-reach", when it can attack one of those, hand to hand or shooting,
+//   - "hurt", when it has less than half its health left.
+//
+// The actions "attack", "approach", "retreat" and "wait" do the best option the consideration of the same name comes up with.
+// They fail right away when it comes up with nothing.
+func (b *Brain) Library(unit *entity.Entity) behaviour.Library {
+	return behaviour.Library{
+		Conditions: map[string]func() bool{
+			"enemy-in-sight": func() bool { return len(b.situation(unit).Enemies) > 0 },
+			"enemy-in-reach": func() bool { return enemyInReach(b.situation(unit)) },
+			"hurt":           func() bool { return healthLeft(unit) < 0.5 },
+		},
+		Actions: map[string]func() actions.Action{
+			"attack":   b.option(unit, Attack()),
+			"approach": b.option(unit, Approach()),
+			"retreat":  b.option(unit, Retreat()),
+			"wait":     b.option(unit, Wait()),
+		},
+	}
+}
+
+// option makes an action out of the best option the consideration comes up with for the unit.
+func (b *Brain) option(unit *entity.Entity, consider Consideration) func() actions.Action {
+	return func() actions.Action {
+		option, ok := b.pick(b.situation(unit), consider)
+		if !ok {
+			return actions.Interrupt()
+		}
+		return option.Action
+	}
+}
+
+func enemyInReach(s Situation) bool {
+	for _, enemy := range s.Enemies {
+		if combat.InMeleeReach(s.World, s.Unit, enemy) || combat.InShootingRange(s.World, s.Unit, enemy) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ai_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/behaviour"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestLibraryConditions(t *testing.T) {
+	kases := map[string]struct {
+		EnemyAt grid.Point
+		Walled  bool
+		Health  int
+		Want    map[string]bool
+	}{
+		"enemy next to the unit": {
+			EnemyAt: grid.P(0, 1), Health: 10,
+			Want: map[string]bool{"enemy-in-sight": true, "enemy-in-reach": true, "hurt": false},
+		},
+		"enemy out of reach": {
+			EnemyAt: grid.P(0, 7), Health: 10,
+			Want: map[string]bool{"enemy-in-sight": true, "enemy-in-reach": false, "hurt": false},
+		},
+		"enemy out of sight": {
+			EnemyAt: grid.P(0, 5), Walled: true, Health: 4,
+			Want: map[string]bool{"enemy-in-sight": false, "enemy-in-reach": false, "hurt": true},
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := rectSpace(1, 8)
+			if kase.Walled {
+				space.At(grid.P(0, 3)).Destroy()
+			}
+			world := entity.NewWorld(space)
+			unit := spawn(world, grid.P(0, 0), red)
+			unit.Stats.Range = 3
+			unit.Stats.Health = kase.Health
+			spawn(world, kase.EnemyAt, blue)
+			lib := newBrain(world, 1).Library(unit)
+
+			for condition, want := range kase.Want {
+				// when
+				got := lib.Conditions[condition]()
+
+				// then
+				assert.That(got == want, t.Errorf, "%s: got %v, want %v", condition, got, want)
+			}
+		})
+	}
+}
+
+func TestLibraryActionFailsWithNothingToDo(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	lib := newBrain(world, 1).Library(unit)
+
+	// when
+	status := lib.Actions["attack"]().Run(time.Second)
+
+	// then
+	assert.That(status.Interrupted(), t.Errorf, "got status %#v, want it interrupted", status)
+}
+
+func TestUnitFollowsTree(t *testing.T) {
+	// given
+	world := entity.NewWorld(rectSpace(1, 8))
+	unit := spawn(world, grid.P(0, 0), red)
+	enemy := spawn(world, grid.P(0, 1), blue)
+	enemy.Stats.Defence = -100
+	brain := newBrain(world, 1)
+
+	tree, err := behaviour.Load(strings.NewReader(`{
+		"type": "selector",
+		"children": [
+			{"type": "sequence", "children": [
+				{"type": "condition", "name": "enemy-in-reach"},
+				{"type": "action", "name": "attack"}
+			]},
+			{"type": "action", "name": "wait"}
+		]
+	}`), brain.Library(unit))
+	assert.That(err == nil, t.Fatalf, "unexpected error: %v", err)
+	brain.Follow(unit, tree)
+
+	// when
+	for tick := 0; tick < 20; tick++ {
+		brain.Update(unit.Stats.AttackTime/4, []*entity.Entity{unit})
+		world.Update(unit.Stats.AttackTime / 4)
+	}
+
+	// then
+	assert.That(enemy.Stats.Health < entity.DefaultStats().Health, t.Errorf, "the attacks never landed")
+}
@@ -0,0 +1,284 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package behaviour builds behaviour trees out of actions.
+//
+// An action that is done counts as a success, one that is interrupted as a failure, and one that is paused as still running.
+// Nodes build their actions anew each time they are run, so that a tree can be run again and again, and parts of it can be retried.
+package behaviour
+
+import (
+	"sort"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// A Node is a part of a behaviour tree.
+// It builds the action it stands for anew every time it is called.
+type Node func() actions.Action
+
+// A Policy decides when running nodes in parallel succeeds.
+type Policy int
+
+const (
+	// RequireAll succeeds once all the nodes succeed and fails as soon as one of them fails.
+	RequireAll Policy = iota
+	// RequireOne succeeds as soon as one of the nodes succeeds and fails once all of them fail.
+	RequireOne
+)
+
+// Condition creates a node that succeeds when the check passes and fails otherwise.
+// It takes no time.
+func Condition(check func() bool) Node {
+	return func() actions.Action { return _Condition(check) }
+}
+
+type _Condition func() bool
+
+func (check _Condition) Run(atMost time.Duration) actions.Status {
+	if check() {
+		return actions.Done(atMost)
+	}
+	return actions.Interrupted(atMost)
+}
+
+// Wait creates a node that succeeds after some time passes.
+func Wait(d time.Duration) Node {
+	return func() actions.Action { return actions.Wait(d) }
+}
+
+// Sequence creates a node that runs its children one after another.
+// It fails as soon as one of them fails and succeeds when all of them succeed.
+func Sequence(children ...Node) Node {
+	return func() actions.Action { return &_Composite{children: children, proceedOnDone: true} }
+}
+
+// Selector creates a node that runs its children one after another, until one of them succeeds.
+// It succeeds as soon as one of them succeeds and fails when all of them fail.
+func Selector(children ...Node) Node {
+	return func() actions.Action { return &_Composite{children: children, proceedOnDone: false} }
+}
+
+// _Composite is either a sequence or a selector - they only differ in which outcome makes them go on to the next child.
+type _Composite struct {
+	children      []Node
+	next          int
+	current       actions.Action
+	proceedOnDone bool
+}
+
+func (c *_Composite) Run(atMost time.Duration) actions.Status {
+	for {
+		if c.current == nil {
+			if c.next >= len(c.children) {
+				return c.exhausted(atMost)
+			}
+			c.current = c.children[c.next]()
+			c.next++
+		}
+
+		status := c.current.Run(atMost)
+		if !status.Done() && !status.Interrupted() {
+			return actions.Paused()
+		}
+		c.current = nil
+		atMost = status.TimeLeft()
+		if status.Done() != c.proceedOnDone {
+			return status
+		}
+	}
+}
+
+// Cancel cancels the child that is running.
+func (c *_Composite) Cancel() {
+	if c.current != nil {
+		actions.Cancel(c.current)
+		c.current = nil
+	}
+	c.next = len(c.children)
+}
+
+func (c *_Composite) exhausted(timeLeft time.Duration) actions.Status {
+	if c.proceedOnDone {
+		return actions.Done(timeLeft)
+	}
+	return actions.Interrupted(timeLeft)
+}
+
+// Parallel creates a node that runs all its children at the same time.
+// When it succeeds or fails is up to the policy.
+// Children that are still running when that is decided get cancelled.
+// Within a single run, the outcome is decided by the children in the order they finished.
+func Parallel(policy Policy, children ...Node) Node {
+	return func() actions.Action {
+		running := make([]actions.Action, len(children))
+		for i, child := range children {
+			running[i] = child()
+		}
+		return &_Parallel{policy: policy, running: running}
+	}
+}
+
+type _Parallel struct {
+	policy              Policy
+	running             []actions.Action
+	succeeded, finished int
+}
+
+func (p *_Parallel) Run(atMost time.Duration) actions.Status {
+	var finished []actions.Status
+	for i, child := range p.running {
+		if child == nil {
+			continue
+		}
+		status := child.Run(atMost)
+		if status.Done() || status.Interrupted() {
+			p.running[i] = nil
+			finished = append(finished, status)
+		}
+	}
+
+	// The children that finished sooner have more time left - they decide first.
+	sort.SliceStable(finished, func(i, j int) bool { return finished[i].TimeLeft() > finished[j].TimeLeft() })
+	for _, status := range finished {
+		p.finished++
+		if status.Done() {
+			p.succeeded++
+		}
+		if decided, ok := p.decide(status.TimeLeft()); ok {
+			p.Cancel()
+			return decided
+		}
+	}
+	if decided, ok := p.decide(atMost); ok {
+		p.Cancel()
+		return decided
+	}
+	return actions.Paused()
+}
+
+// Cancel cancels the children that are still running.
+func (p *_Parallel) Cancel() {
+	for i, child := range p.running {
+		if child != nil {
+			actions.Cancel(child)
+			p.running[i] = nil
+		}
+	}
+}
+
+func (p *_Parallel) decide(timeLeft time.Duration) (actions.Status, bool) {
+	failed, all := p.finished-p.succeeded, len(p.running)
+	switch {
+	case p.policy == RequireAll && failed > 0:
+		return actions.Interrupted(timeLeft), true
+	case p.policy == RequireAll && p.succeeded == all:
+		return actions.Done(timeLeft), true
+	case p.policy == RequireOne && p.succeeded > 0:
+		return actions.Done(timeLeft), true
+	case p.policy == RequireOne && failed == all:
+		return actions.Interrupted(timeLeft), true
+	}
+	return actions.Status{}, false
+}
+
+// Invert creates a node that fails when its child succeeds, and succeeds when its child fails.
+func Invert(child Node) Node {
+	return func() actions.Action { return &_Invert{child()} }
+}
+
+type _Invert struct {
+	child actions.Action
+}
+
+func (inv *_Invert) Run(atMost time.Duration) actions.Status {
+	status := inv.child.Run(atMost)
+	switch {
+	case status.Done():
+		return actions.Interrupted(status.TimeLeft())
+	case status.Interrupted():
+		return actions.Done(status.TimeLeft())
+	}
+	return status
+}
+
+// Cancel cancels the child.
+func (inv *_Invert) Cancel() { actions.Cancel(inv.child) }
+
+// Retry creates a node that runs its child again when it fails, up to the given number of times.
+// It fails when the child fails on its last try.
+func Retry(times int, child Node) Node {
+	return func() actions.Action { return &_Retry{child: child, retriesLeft: times} }
+}
+
+type _Retry struct {
+	child       Node
+	current     actions.Action
+	retriesLeft int
+}
+
+func (r *_Retry) Run(atMost time.Duration) actions.Status {
+	for {
+		if r.current == nil {
+			r.current = r.child()
+		}
+		status := r.current.Run(atMost)
+		if !status.Interrupted() || r.retriesLeft <= 0 {
+			return status
+		}
+		r.retriesLeft--
+		r.current = nil
+		atMost = status.TimeLeft()
+	}
+}
+
+// Cancel cancels the try in progress.
+func (r *_Retry) Cancel() {
+	if r.current != nil {
+		actions.Cancel(r.current)
+		r.current = nil
+	}
+	r.retriesLeft = 0
+}
+
+// Timeout creates a node that fails when its child takes longer than the limit.
+// The child gets cancelled when it runs out of time.
+func Timeout(limit time.Duration, child Node) Node {
+	return func() actions.Action { return &_Timeout{child: child(), left: limit} }
+}
+
+type _Timeout struct {
+	child actions.Action
+	left  time.Duration
+}
+
+func (t *_Timeout) Run(atMost time.Duration) actions.Status {
+	budget := atMost
+	if t.left < budget {
+		budget = t.left
+	}
+
+	status := t.child.Run(budget)
+	finished := status.Done() || status.Interrupted()
+	used := budget
+	if finished {
+		used -= status.TimeLeft()
+	}
+	t.left -= used
+
+	switch {
+	case status.Done():
+		return actions.Done(atMost - used)
+	case status.Interrupted():
+		return actions.Interrupted(atMost - used)
+	case t.left <= 0:
+		actions.Cancel(t.child)
+		return actions.Interrupted(atMost - used)
+	}
+	return actions.Paused()
+}
+
+// Cancel cancels the child.
+func (t *_Timeout) Cancel() { actions.Cancel(t.child) }
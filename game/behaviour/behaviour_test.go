@@ -0,0 +1,243 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package behaviour_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/behaviour"
+)
+
+func TestNodes(t *testing.T) {
+	kases := map[string]struct {
+		Node behaviour.Node
+		Want actions.Status
+	}{
+		"condition that passes": {
+			Node: behaviour.Condition(func() bool { return true }),
+			Want: actions.Done(10 * time.Second),
+		},
+		"condition that does not pass": {
+			Node: behaviour.Condition(func() bool { return false }),
+			Want: actions.Interrupted(10 * time.Second),
+		},
+		"empty sequence": {
+			Node: behaviour.Sequence(),
+			Want: actions.Done(10 * time.Second),
+		},
+		"sequence that succeeds": {
+			Node: behaviour.Sequence(succeedAfter(time.Second), succeedAfter(2*time.Second)),
+			Want: actions.Done(7 * time.Second),
+		},
+		"sequence that fails": {
+			Node: behaviour.Sequence(succeedAfter(time.Second), failAfter(2*time.Second), succeedAfter(time.Second)),
+			Want: actions.Interrupted(7 * time.Second),
+		},
+		"empty selector": {
+			Node: behaviour.Selector(),
+			Want: actions.Interrupted(10 * time.Second),
+		},
+		"selector that succeeds": {
+			Node: behaviour.Selector(failAfter(time.Second), succeedAfter(2*time.Second), failAfter(time.Second)),
+			Want: actions.Done(7 * time.Second),
+		},
+		"selector that fails": {
+			Node: behaviour.Selector(failAfter(time.Second), failAfter(2*time.Second)),
+			Want: actions.Interrupted(7 * time.Second),
+		},
+		"parallel requiring all that succeeds": {
+			Node: behaviour.Parallel(behaviour.RequireAll, succeedAfter(time.Second), succeedAfter(3*time.Second)),
+			Want: actions.Done(7 * time.Second),
+		},
+		"parallel requiring all that fails": {
+			Node: behaviour.Parallel(behaviour.RequireAll, succeedAfter(time.Second), failAfter(2*time.Second), succeedAfter(5*time.Second)),
+			Want: actions.Interrupted(8 * time.Second),
+		},
+		"parallel requiring one that succeeds": {
+			Node: behaviour.Parallel(behaviour.RequireOne, failAfter(time.Second), succeedAfter(2*time.Second), failAfter(5*time.Second)),
+			Want: actions.Done(8 * time.Second),
+		},
+		"parallel requiring one that fails": {
+			Node: behaviour.Parallel(behaviour.RequireOne, failAfter(time.Second), failAfter(3*time.Second)),
+			Want: actions.Interrupted(7 * time.Second),
+		},
+		"inverted success": {
+			Node: behaviour.Invert(succeedAfter(time.Second)),
+			Want: actions.Interrupted(9 * time.Second),
+		},
+		"inverted failure": {
+			Node: behaviour.Invert(failAfter(time.Second)),
+			Want: actions.Done(9 * time.Second),
+		},
+		"timeout that is not reached": {
+			Node: behaviour.Timeout(3*time.Second, succeedAfter(2*time.Second)),
+			Want: actions.Done(8 * time.Second),
+		},
+		"timeout that is reached": {
+			Node: behaviour.Timeout(3*time.Second, succeedAfter(5*time.Second)),
+			Want: actions.Interrupted(7 * time.Second),
+		},
+		"timeout over a failure": {
+			Node: behaviour.Timeout(3*time.Second, failAfter(time.Second)),
+			Want: actions.Interrupted(9 * time.Second),
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			action := kase.Node()
+
+			// when
+			status := action.Run(10 * time.Second)
+
+			// then
+			assert.That(status == kase.Want, t.Errorf, "got status %#v, want %#v", status, kase.Want)
+		})
+	}
+}
+
+func TestNodesKeepRunningAcrossRuns(t *testing.T) {
+	// given
+	action := behaviour.Sequence(
+		succeedAfter(time.Second),
+		behaviour.Timeout(2*time.Second, succeedAfter(time.Second)),
+		succeedAfter(time.Second),
+	)()
+
+	// when
+	var statuses []actions.Status
+	for i := 0; i < 5; i++ {
+		statuses = append(statuses, action.Run(time.Second/2))
+	}
+	last := action.Run(time.Second)
+
+	// then
+	for i, status := range statuses {
+		assert.That(status == actions.Paused(), t.Errorf, "got status %#v at %d, want %#v", status, i, actions.Paused())
+	}
+	assert.That(last == actions.Done(time.Second/2), t.Errorf, "got status %#v, want %#v", last, actions.Done(time.Second/2))
+}
+
+func TestRetryBuildsChildAgainAfterFailure(t *testing.T) {
+	kases := map[string]struct {
+		Failures, Times int
+		WantBuilt       int
+		Want            actions.Status
+	}{
+		"succeeds the first time": {Failures: 0, Times: 3, WantBuilt: 1, Want: actions.Done(9 * time.Second)},
+		"succeeds on a retry":     {Failures: 2, Times: 3, WantBuilt: 3, Want: actions.Done(7 * time.Second)},
+		"runs out of retries":     {Failures: 5, Times: 3, WantBuilt: 4, Want: actions.Interrupted(6 * time.Second)},
+		"never retried":           {Failures: 1, Times: 0, WantBuilt: 1, Want: actions.Interrupted(9 * time.Second)},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			built := 0
+			child := func() actions.Action {
+				built++
+				if built <= kase.Failures {
+					return failAfter(time.Second)()
+				}
+				return succeedAfter(time.Second)()
+			}
+
+			action := behaviour.Retry(kase.Times, child)()
+
+			// when
+			status := action.Run(10 * time.Second)
+
+			// then
+			assert.That(status == kase.Want, t.Errorf, "got status %#v, want %#v", status, kase.Want)
+			assert.That(built == kase.WantBuilt, t.Errorf, "the child got built %d times, want %d", built, kase.WantBuilt)
+		})
+	}
+}
+
+func TestTreeCanBeRunAgain(t *testing.T) {
+	// given
+	passes := true
+	node := behaviour.Sequence(behaviour.Condition(func() bool { return passes }), succeedAfter(time.Second))
+	first := node().Run(2 * time.Second)
+
+	// when
+	passes = false
+	second := node().Run(2 * time.Second)
+
+	// then
+	assert.That(first == actions.Done(time.Second), t.Errorf, "got status %#v the first time, want %#v", first, actions.Done(time.Second))
+	assert.That(second == actions.Interrupted(2*time.Second), t.Errorf, "got status %#v the second time, want %#v", second, actions.Interrupted(2*time.Second))
+}
+
+func TestNodesCancelChildrenTheyDrop(t *testing.T) {
+	kases := map[string]struct {
+		Node   func(child behaviour.Node) behaviour.Node
+		Cancel bool
+	}{
+		"timeout that is reached": {
+			Node: func(child behaviour.Node) behaviour.Node { return behaviour.Timeout(time.Second, child) },
+		},
+		"parallel that is decided": {
+			Node: func(child behaviour.Node) behaviour.Node {
+				return behaviour.Parallel(behaviour.RequireOne, succeedAfter(time.Second), child)
+			},
+		},
+		"cancelled sequence": {
+			Node:   func(child behaviour.Node) behaviour.Node { return behaviour.Sequence(child) },
+			Cancel: true,
+		},
+		"cancelled selector": {
+			Node:   func(child behaviour.Node) behaviour.Node { return behaviour.Selector(child) },
+			Cancel: true,
+		},
+		"cancelled invert": {
+			Node:   func(child behaviour.Node) behaviour.Node { return behaviour.Invert(child) },
+			Cancel: true,
+		},
+		"cancelled retry": {
+			Node:   func(child behaviour.Node) behaviour.Node { return behaviour.Retry(2, child) },
+			Cancel: true,
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			child := &_Cancellable{Action: actions.Wait(5 * time.Second)}
+			action := kase.Node(func() actions.Action { return child })()
+
+			// when
+			action.Run(2 * time.Second)
+			if kase.Cancel {
+				actions.Cancel(action)
+			}
+
+			// then
+			assert.That(child.cancelled == 1, t.Errorf, "the child got cancelled %d times, want 1", child.cancelled)
+		})
+	}
+}
+
+type _Cancellable struct {
+	actions.Action
+	cancelled int
+}
+
+func (c *_Cancellable) Cancel() { c.cancelled++ }
+
+func succeedAfter(d time.Duration) behaviour.Node {
+	return behaviour.Wait(d)
+}
+
+func failAfter(d time.Duration) behaviour.Node {
+	return func() actions.Action {
+		return actions.Sequence(actions.Wait(d), actions.Interrupt())
+	}
+}
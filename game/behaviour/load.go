@@ -0,0 +1,238 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package behaviour
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// ErrUnknownNode is wrapped by the errors for nodes of a type there is no such thing as.
+func ErrUnknownNode() error { return errUnknownNode }
+
+// ErrUnknownName is wrapped by the errors for conditions and actions missing from the library.
+func ErrUnknownName() error { return errUnknownName }
+
+// ErrMalformedNode is wrapped by the errors for nodes that are missing something, have something wrong with them, or have something their type does not use.
+func ErrMalformedNode() error { return errMalformedNode }
+
+var (
+	errUnknownNode   = errors.New("unknown node type")
+	errUnknownName   = errors.New("unknown name")
+	errMalformedNode = errors.New("malformed node")
+)
+
+// A Spec describes a behaviour tree the way it is written down in a data file.
+//
+// Each node has a type, which decides what else it needs:
+//
+//   - "sequence" and "selector" need children,
+//   - "parallel" needs children and a policy, either "all" or "one",
+//   - "invert" needs a child,
+//   - "retry" needs a child and the number of times to retry it,
+//   - "timeout" needs a child and a duration, like "1.5s",
+//   - "wait" needs a duration,
+//   - "condition" and "action" need the name they are found under in the library.
+//
+// Anything else a node has is a mistake.
+type Spec struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+	Times    *int   `json:"times,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Child    *Spec  `json:"child,omitempty"`
+	Children []Spec `json:"children,omitempty"`
+}
+
+// A Library has the conditions and actions that trees refer to by name.
+//
+// Trees for different entities are usually built from the same spec, each with a library that knows which entity it is for.
+type Library struct {
+	Conditions map[string]func() bool
+	Actions    map[string]func() actions.Action
+}
+
+// Load reads a tree written down as JSON and builds it with the library.
+func Load(r io.Reader, lib Library) (Node, error) {
+	spec, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Build(lib)
+}
+
+// Parse reads a tree written down as JSON.
+// Fields that are not a part of a spec are an error, so that typos do not go unnoticed.
+func Parse(r io.Reader) (Spec, error) {
+	var spec Spec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("cannot parse behaviour tree: %w", err)
+	}
+	return spec, nil
+}
+
+// Build turns the spec into a tree, with the conditions and actions taken from the library.
+// Errors say where in the tree the problem is.
+func (spec Spec) Build(lib Library) (Node, error) {
+	return spec.build(lib, "root")
+}
+
+func (spec Spec) build(lib Library, path string) (Node, error) {
+	fields, ok := _Fields[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("%s: %q: %w", path, spec.Type, ErrUnknownNode())
+	}
+	if err := spec.uses(path, fields); err != nil {
+		return nil, err
+	}
+
+	switch spec.Type {
+	case "sequence", "selector", "parallel":
+		return spec.buildComposite(lib, path)
+
+	case "invert", "retry", "timeout":
+		return spec.buildDecorator(lib, path)
+
+	case "wait":
+		d, err := spec.duration(path)
+		if err != nil {
+			return nil, err
+		}
+		return Wait(d), nil
+
+	case "condition":
+		check, ok := lib.Conditions[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: condition %q: %w", path, spec.Name, ErrUnknownName())
+		}
+		return Condition(check), nil
+
+	case "action":
+		action, ok := lib.Actions[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: action %q: %w", path, spec.Name, ErrUnknownName())
+		}
+		return Node(action), nil
+	}
+	panic(fmt.Sprintf("node type %q has fields listed but is not built", spec.Type))
+}
+
+// _Fields lists the fields each type of node uses, besides its type.
+var _Fields = map[string][]string{
+	"sequence":  {"children"},
+	"selector":  {"children"},
+	"parallel":  {"policy", "children"},
+	"invert":    {"child"},
+	"retry":     {"times", "child"},
+	"timeout":   {"duration", "child"},
+	"wait":      {"duration"},
+	"condition": {"name"},
+	"action":    {"name"},
+}
+
+// uses checks that the spec has nothing besides the fields its type uses.
+func (spec Spec) uses(path string, fields []string) error {
+	has := []struct {
+		field string
+		set   bool
+	}{
+		{"name", spec.Name != ""},
+		{"policy", spec.Policy != ""},
+		{"times", spec.Times != nil},
+		{"duration", spec.Duration != ""},
+		{"child", spec.Child != nil},
+		{"children", spec.Children != nil},
+	}
+	for _, f := range has {
+		if f.set && !contains(fields, f.field) {
+			return fmt.Errorf("%s: %s cannot have %s: %w", path, spec.Type, f.field, ErrMalformedNode())
+		}
+	}
+	return nil
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (spec Spec) buildComposite(lib Library, path string) (Node, error) {
+	children := make([]Node, len(spec.Children))
+	for i, childSpec := range spec.Children {
+		child, err := childSpec.build(lib, fmt.Sprintf("%s.children[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		children[i] = child
+	}
+
+	switch spec.Type {
+	case "sequence":
+		return Sequence(children...), nil
+	case "selector":
+		return Selector(children...), nil
+	}
+
+	switch spec.Policy {
+	case "all":
+		return Parallel(RequireAll, children...), nil
+	case "one":
+		return Parallel(RequireOne, children...), nil
+	}
+	return nil, fmt.Errorf("%s: policy %q is neither \"all\" nor \"one\": %w", path, spec.Policy, ErrMalformedNode())
+}
+
+func (spec Spec) buildDecorator(lib Library, path string) (Node, error) {
+	if spec.Child == nil {
+		return nil, fmt.Errorf("%s: %s without a child: %w", path, spec.Type, ErrMalformedNode())
+	}
+	child, err := spec.Child.build(lib, path+".child")
+	if err != nil {
+		return nil, err
+	}
+
+	switch spec.Type {
+	case "invert":
+		return Invert(child), nil
+
+	case "retry":
+		switch {
+		case spec.Times == nil:
+			return nil, fmt.Errorf("%s: retry without times: %w", path, ErrMalformedNode())
+		case *spec.Times < 0:
+			return nil, fmt.Errorf("%s: cannot retry %d times: %w", path, *spec.Times, ErrMalformedNode())
+		}
+		return Retry(*spec.Times, child), nil
+	}
+
+	d, err := spec.duration(path)
+	if err != nil {
+		return nil, err
+	}
+	return Timeout(d, child), nil
+}
+
+func (spec Spec) duration(path string) (time.Duration, error) {
+	d, err := time.ParseDuration(spec.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s: %w", path, err, ErrMalformedNode())
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s: negative duration %s: %w", path, d, ErrMalformedNode())
+	}
+	return d, nil
+}
@@ -0,0 +1,186 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package behaviour_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/behaviour"
+)
+
+const guardTree = `{
+	"type": "selector",
+	"children": [
+		{
+			"type": "sequence",
+			"children": [
+				{"type": "condition", "name": "enemy-in-sight"},
+				{"type": "timeout", "duration": "3s", "child": {"type": "action", "name": "attack"}}
+			]
+		},
+		{
+			"type": "parallel",
+			"policy": "one",
+			"children": [
+				{"type": "retry", "times": 2, "child": {"type": "action", "name": "patrol"}},
+				{"type": "invert", "child": {"type": "wait", "duration": "1s"}}
+			]
+		}
+	]
+}`
+
+func TestLoadedTreeRuns(t *testing.T) {
+	kases := map[string]struct {
+		EnemyInSight bool
+		Want         []string
+	}{
+		"attacks what it sees": {EnemyInSight: true, Want: []string{"attack"}},
+		"patrols otherwise":    {EnemyInSight: false, Want: []string{"patrol"}},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			var done []string
+			lib := behaviour.Library{
+				Conditions: map[string]func() bool{
+					"enemy-in-sight": func() bool { return kase.EnemyInSight },
+				},
+				Actions: map[string]func() actions.Action{
+					"attack": recording(&done, "attack"),
+					"patrol": recording(&done, "patrol"),
+				},
+			}
+
+			tree, err := behaviour.Load(strings.NewReader(guardTree), lib)
+			assert.That(err == nil, t.Fatalf, "unexpected error: %s", err)
+
+			// when
+			status := tree().Run(10 * time.Second)
+
+			// then
+			assert.That(status == actions.Done(9*time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Done(9*time.Second))
+			assert.That(stringsEqual(done, kase.Want), t.Errorf, "got %v done, want %v", done, kase.Want)
+		})
+	}
+}
+
+func TestLoadingBrokenTreeFails(t *testing.T) {
+	kases := map[string]struct {
+		Tree string
+		Want error
+	}{
+		"unknown type": {
+			Tree: `{"type": "sequence", "children": [{"type": "dance"}]}`,
+			Want: behaviour.ErrUnknownNode(),
+		},
+		"unknown condition": {
+			Tree: `{"type": "condition", "name": "is-raining"}`,
+			Want: behaviour.ErrUnknownName(),
+		},
+		"unknown action": {
+			Tree: `{"type": "invert", "child": {"type": "action", "name": "dance"}}`,
+			Want: behaviour.ErrUnknownName(),
+		},
+		"decorator without a child": {
+			Tree: `{"type": "retry", "times": 3}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"negative retries": {
+			Tree: `{"type": "retry", "times": -1, "child": {"type": "wait", "duration": "1s"}}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"bad duration": {
+			Tree: `{"type": "wait", "duration": "soon"}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"bad policy": {
+			Tree: `{"type": "parallel", "policy": "most", "children": []}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"retry without times": {
+			Tree: `{"type": "retry", "child": {"type": "wait", "duration": "1s"}}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"composite with a child": {
+			Tree: `{"type": "sequence", "child": {"type": "wait", "duration": "1s"}}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"decorator with children": {
+			Tree: `{"type": "invert", "child": {"type": "wait", "duration": "1s"}, "children": []}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+		"leaf with a policy": {
+			Tree: `{"type": "condition", "name": "is-raining", "policy": "all"}`,
+			Want: behaviour.ErrMalformedNode(),
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			_, err := behaviour.Load(strings.NewReader(kase.Tree), behaviour.Library{})
+
+			// then
+			assert.That(errors.Is(err, kase.Want), t.Errorf, "got error %v, want %v", err, kase.Want)
+		})
+	}
+}
+
+func TestErrorSaysWhereTheProblemIs(t *testing.T) {
+	// given
+	tree := `{"type": "selector", "children": [{"type": "wait", "duration": "1s"}, {"type": "invert", "child": {"type": "dance"}}]}`
+
+	// when
+	_, err := behaviour.Load(strings.NewReader(tree), behaviour.Library{})
+
+	// then
+	assert.That(err != nil && strings.HasPrefix(err.Error(), "root.children[1].child:"), t.Errorf, "got error %v", err)
+}
+
+func TestParsingRejectsUnknownFields(t *testing.T) {
+	// given
+	tree := `{"type": "wait", "durration": "1s"}`
+
+	// when
+	_, err := behaviour.Parse(strings.NewReader(tree))
+
+	// then
+	assert.That(err != nil, t.Errorf, "a misspelt field got accepted")
+}
+
+func recording(done *[]string, name string) func() actions.Action {
+	return func() actions.Action {
+		return actions.Sequence(actions.Wait(time.Second), &_Record{done, name})
+	}
+}
+
+type _Record struct {
+	done *[]string
+	name string
+}
+
+func (r *_Record) Run(atMost time.Duration) actions.Status {
+	*r.done = append(*r.done, r.name)
+	return actions.Done(atMost)
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package combat lets entities hurt each other.
+//
+// Attacks are actions, so that they can be queued and sequenced with anything else an entity does, like the animations that go with them.
+// All the randomness comes from a random number generator passed in, so that a seeded one makes fights repeat exactly.
+package combat
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+// A Hit is the outcome of a single attack.
+type Hit struct {
+	// Landed says whether the attack hurt the target at all.
+	Landed bool
+	// Critical attacks always land and do twice the damage.
+	Critical bool
+	// Damage is how much health the target loses.
+	// It is zero when the attack did not land.
+	Damage int
+}
+
+// Roll decides how an attack goes.
+//
+// The attacker rolls a twenty sided die.
+// The attack lands when the roll plus the attacker's attack is over ten plus the defender's defence.
+// A one always misses and a twenty is always a critical hit.
+// An attack that lands does between one and the attacker's attack of damage.
+func Roll(rng *rand.Rand, attacker, defender entity.Stats) Hit {
+	roll := rng.Intn(20) + 1
+	hit := Hit{
+		Critical: roll == 20,
+		Landed:   lands(roll, attacker, defender),
+	}
+	if !hit.Landed {
+		return hit
+	}
+
+	hit.Damage = 1 + rng.Intn(maxInt(attacker.Attack, 1))
+	if hit.Critical {
+		hit.Damage *= 2
+	}
+	return hit
+}
+
+// HitChance is how likely the attacker's attacks are to land on the defender.
+func HitChance(attacker, defender entity.Stats) float64 {
+	landing := 0
+	for roll := 1; roll <= 20; roll++ {
+		if lands(roll, attacker, defender) {
+			landing++
+		}
+	}
+	return float64(landing) / 20
+}
+
+func lands(roll int, attacker, defender entity.Stats) bool {
+	return roll == 20 || (roll > 1 && roll+attacker.Attack > 10+defender.Defence)
+}
+
+// Hurt takes away some of the entity's health.
+// An entity left with no health dies - it gets despawned, freeing its position.
+//
+// The return value says whether the entity died.
+// Entities that are not in the world are left alone.
+func Hurt(w *entity.World, e *entity.Entity, damage int) (died bool) {
+	if !alive(w, e) {
+		return false
+	}
+	e.Stats.Health -= damage
+	if e.Stats.Health > 0 {
+		return false
+	}
+	e.Stats.Health = 0
+	w.Despawn(e)
+	return true
+}
+
+// InMeleeReach says whether the attacker is right next to the target.
+func InMeleeReach(w *entity.World, attacker, target *entity.Entity) bool {
+	if !canAttack(w, attacker, target) {
+		return false
+	}
+	from, to := attacker.Placement.AtPoint(), target.Placement.AtPoint()
+	return abs(from.Row-to.Row)+abs(from.Column-to.Column) == 1
+}
+
+// InShootingRange says whether the target is within the attacker's range and nothing stands between them.
+// The range is measured in a straight line.
+func InShootingRange(w *entity.World, attacker, target *entity.Entity) bool {
+	if !canAttack(w, attacker, target) {
+		return false
+	}
+	from, to := attacker.Placement.AtPoint(), target.Placement.AtPoint()
+	distance := math.Hypot(float64(from.Row-to.Row), float64(from.Column-to.Column))
+	return distance <= float64(attacker.Stats.Range) && Unobstructed(w, from, to, attacker, target)
+}
+
+// Unobstructed says whether nothing stands between two points, other than the ignored entities.
+// Entities do not stand in their own way, even when they are in the middle of a step.
+func Unobstructed(w *entity.World, from, to grid.Point, ignored ...*entity.Entity) bool {
+	return w.Space().InSightThrough(from, to, func(pos grid.Position) bool {
+		return isOneOf(grid.OwnerAt(pos), ignored)
+	})
+}
+
+func isOneOf(owner any, entities []*entity.Entity) bool {
+	for _, e := range entities {
+		if owner == any(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Melee creates an action in which the attacker hits the target with whatever it holds.
+//
+// The attack takes the attacker's attack time.
+// It is interrupted when the target is not in melee reach, either as the attack starts or as it ends.
+func Melee(w *entity.World, attacker, target *entity.Entity, rng *rand.Rand) actions.Action {
+	return &_AttackAction{world: w, attacker: attacker, target: target, rng: rng, inReach: InMeleeReach}
+}
+
+// Ranged creates an action in which the attacker shoots at the target.
+//
+// The attack takes the attacker's attack time.
+// It is interrupted when the target is not in shooting range, either as the attack starts or as it ends.
+func Ranged(w *entity.World, attacker, target *entity.Entity, rng *rand.Rand) actions.Action {
+	return &_AttackAction{world: w, attacker: attacker, target: target, rng: rng, inReach: InShootingRange}
+}
+
+type _AttackAction struct {
+	world            *entity.World
+	attacker, target *entity.Entity
+	rng              *rand.Rand
+	inReach          func(w *entity.World, attacker, target *entity.Entity) bool
+
+	windup actions.Action
+}
+
+func (action *_AttackAction) Run(atMost time.Duration) actions.Status {
+	if action.windup == nil {
+		if !action.inReach(action.world, action.attacker, action.target) {
+			return actions.Interrupted(atMost)
+		}
+		action.windup = actions.Wait(action.attacker.Stats.AttackTime)
+	}
+
+	status := action.windup.Run(atMost)
+	if !status.Done() {
+		return status
+	}
+	if !action.inReach(action.world, action.attacker, action.target) {
+		return actions.Interrupted(status.TimeLeft())
+	}
+
+	hit := Roll(action.rng, action.attacker.Stats, action.target.Stats)
+	if hit.Landed {
+		Hurt(action.world, action.target, hit.Damage)
+	}
+	return actions.Done(status.TimeLeft())
+}
+
+func canAttack(w *entity.World, attacker, target *entity.Entity) bool {
+	return attacker != target && alive(w, attacker) && alive(w, target)
+}
+
+func alive(w *entity.World, e *entity.Entity) bool {
+	found, ok := w.Get(e.ID())
+	return ok && found == e
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
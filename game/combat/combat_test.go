@@ -0,0 +1,315 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package combat_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/combat"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestRollsRepeatForTheSameSeed(t *testing.T) {
+	// given
+	attacker, defender := entity.DefaultStats(), entity.DefaultStats()
+	first, second := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+
+	for i := 0; i < 100; i++ {
+		// when
+		a, b := combat.Roll(first, attacker, defender), combat.Roll(second, attacker, defender)
+
+		// then
+		assert.That(a == b, t.Fatalf, "roll %d differs: %#v and %#v", i, a, b)
+	}
+}
+
+func TestRolledDamageIsWithinBounds(t *testing.T) {
+	// given
+	attacker, defender := entity.DefaultStats(), entity.DefaultStats()
+	attacker.Attack = 4
+	rng := rand.New(rand.NewSource(1))
+
+	landed := 0
+	for i := 0; i < 1000; i++ {
+		// when
+		hit := combat.Roll(rng, attacker, defender)
+
+		// then
+		max := attacker.Attack
+		if hit.Critical {
+			max *= 2
+		}
+		switch {
+		case hit.Landed:
+			landed++
+			assert.That(1 <= hit.Damage && hit.Damage <= max, t.Errorf, "got damage %d, want it between 1 and %d", hit.Damage, max)
+		default:
+			assert.That(hit.Damage == 0 && !hit.Critical, t.Errorf, "an attack that missed did %#v", hit)
+		}
+	}
+	assert.That(0 < landed && landed < 1000, t.Errorf, "%d out of 1000 attacks landed", landed)
+}
+
+func TestDefenceMakesAttacksMiss(t *testing.T) {
+	// given
+	attacker, defender := entity.DefaultStats(), entity.DefaultStats()
+	defender.Defence = 100
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		// when
+		hit := combat.Roll(rng, attacker, defender)
+
+		// then
+		assert.That(!hit.Landed || hit.Critical, t.Fatalf, "got a regular hit %#v through overwhelming defence", hit)
+	}
+}
+
+func TestHitChance(t *testing.T) {
+	kases := map[string]struct {
+		Attack, Defence int
+		Want            float64
+	}{
+		"even":              {Attack: 0, Defence: 0, Want: 0.5},
+		"stronger attacker": {Attack: 5, Defence: 0, Want: 0.75},
+		"only criticals":    {Attack: 0, Defence: 100, Want: 0.05},
+		"all but ones":      {Attack: 100, Defence: 0, Want: 0.95},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			attacker, defender := entity.DefaultStats(), entity.DefaultStats()
+			attacker.Attack, defender.Defence = kase.Attack, kase.Defence
+
+			// when
+			chance := combat.HitChance(attacker, defender)
+
+			// then
+			assert.That(chance == kase.Want, t.Errorf, "got chance %v, want %v", chance, kase.Want)
+		})
+	}
+}
+
+func TestHurtEntityLosesHealth(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	e, _ := world.Spawn(grid.P(0, 0))
+
+	// when
+	died := combat.Hurt(world, e, 3)
+
+	// then
+	want := entity.DefaultStats().Health - 3
+	assert.That(!died, t.Errorf, "the entity died")
+	assert.That(e.Stats.Health == want, t.Errorf, "got health %d, want %d", e.Stats.Health, want)
+}
+
+func TestEntityLeftWithNoHealthDies(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	e, _ := world.Spawn(grid.P(0, 0))
+
+	// when
+	died := combat.Hurt(world, e, e.Stats.Health+5)
+
+	// then
+	_, inWorld := world.Get(e.ID())
+	assert.That(died, t.Errorf, "the entity did not die")
+	assert.That(e.Stats.Health == 0, t.Errorf, "got health %d, want %d", e.Stats.Health, 0)
+	assert.That(!inWorld, t.Errorf, "the entity is still in the world")
+	assert.That(!world.Space().At(grid.P(0, 0)).Taken(), t.Errorf, "the position of the entity is still taken")
+}
+
+func TestDeadEntityIsNotHurtAgain(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	e, _ := world.Spawn(grid.P(0, 0))
+	combat.Hurt(world, e, e.Stats.Health)
+
+	// when
+	died := combat.Hurt(world, e, 1)
+
+	// then
+	assert.That(!died, t.Errorf, "the entity died again")
+	assert.That(e.Stats.Health == 0, t.Errorf, "got health %d, want %d", e.Stats.Health, 0)
+}
+
+func TestAttackHurtsTargetAsRolled(t *testing.T) {
+	kases := map[string]struct {
+		Target grid.Point
+		Attack func(*entity.World, *entity.Entity, *entity.Entity, *rand.Rand) actions.Action
+	}{
+		"melee":  {Target: grid.P(0, 1), Attack: combat.Melee},
+		"ranged": {Target: grid.P(0, 4), Attack: combat.Ranged},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				// given
+				world := entity.NewWorld(lineSpace(6))
+				attacker, _ := world.Spawn(grid.P(0, 0))
+				target, _ := world.Spawn(kase.Target)
+
+				want := combat.Roll(rand.New(rand.NewSource(seed)), attacker.Stats, target.Stats)
+				action := kase.Attack(world, attacker, target, rand.New(rand.NewSource(seed)))
+
+				// when
+				status := action.Run(attacker.Stats.AttackTime + time.Second)
+
+				// then
+				wantHealth := entity.DefaultStats().Health - want.Damage
+				assert.That(status == actions.Done(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second))
+				assert.That(target.Stats.Health == wantHealth, t.Errorf, "seed %d: got health %d, want %d", seed, target.Stats.Health, wantHealth)
+			}
+		})
+	}
+}
+
+func TestAttackTakesTime(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	attacker, _ := world.Spawn(grid.P(0, 0))
+	target, _ := world.Spawn(grid.P(0, 1))
+	target.Stats.Defence = -100
+
+	action := combat.Melee(world, attacker, target, rand.New(rand.NewSource(1)))
+
+	// when
+	status := action.Run(attacker.Stats.AttackTime / 2)
+
+	// then
+	assert.That(status == actions.Paused(), t.Errorf, "got status %#v, want %#v", status, actions.Paused())
+	assert.That(target.Stats.Health == entity.DefaultStats().Health, t.Errorf, "the target got hurt before the attack ended")
+}
+
+func TestAttackOutOfReachIsInterrupted(t *testing.T) {
+	kases := map[string]struct {
+		Setup  func(w *entity.World)
+		Attack func(*entity.World, *entity.Entity, *entity.Entity, *rand.Rand) actions.Action
+	}{
+		"melee too far": {
+			Setup:  func(w *entity.World) {},
+			Attack: combat.Melee,
+		},
+		"ranged too far": {
+			Setup: func(w *entity.World) {
+				attacker, _ := w.At(grid.P(0, 0))
+				attacker.Stats.Range = 1
+			},
+			Attack: combat.Ranged,
+		},
+		"ranged with something in between": {
+			Setup:  func(w *entity.World) { w.Spawn(grid.P(0, 1)) },
+			Attack: combat.Ranged,
+		},
+		"ranged with no floor in between": {
+			Setup:  func(w *entity.World) { w.Space().At(grid.P(0, 1)).Destroy() },
+			Attack: combat.Ranged,
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			world := entity.NewWorld(lineSpace(4))
+			attacker, _ := world.Spawn(grid.P(0, 0))
+			target, _ := world.Spawn(grid.P(0, 3))
+			kase.Setup(world)
+
+			action := kase.Attack(world, attacker, target, rand.New(rand.NewSource(1)))
+
+			// when
+			status := action.Run(time.Second)
+
+			// then
+			assert.That(status == actions.Interrupted(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Interrupted(time.Second))
+			assert.That(target.Stats.Health == entity.DefaultStats().Health, t.Errorf, "the target got hurt")
+		})
+	}
+}
+
+func TestShooterDoesNotStandInItsOwnWay(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(6))
+	attacker, _ := world.Spawn(grid.P(0, 0))
+	target, _ := world.Spawn(grid.P(0, 4))
+	attacker.Placement.MoveTo(world.Space().At(grid.P(0, 1)), time.Second).Run(time.Second / 2)
+
+	// when
+	inRange := combat.InShootingRange(world, attacker, target)
+
+	// then
+	assert.That(inRange, t.Errorf, "the shooter blocks its own line of sight")
+}
+
+func TestPointIsUnobstructedFromItself(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	e, _ := world.Spawn(grid.P(0, 1))
+
+	// when
+	unobstructed := combat.Unobstructed(world, e.Placement.AtPoint(), e.Placement.AtPoint())
+
+	// then
+	assert.That(unobstructed, t.Errorf, "the point is obstructed from itself")
+}
+
+func TestAttackIsInterruptedWhenTargetGetsAway(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(4))
+	attacker, _ := world.Spawn(grid.P(0, 0))
+	target, _ := world.Spawn(grid.P(0, 1))
+	target.Stats.Defence = -100
+
+	action := combat.Melee(world, attacker, target, rand.New(rand.NewSource(1)))
+	action.Run(attacker.Stats.AttackTime / 2)
+
+	// when
+	target.Placement.Place(world.Space().At(grid.P(0, 3)))
+	status := action.Run(attacker.Stats.AttackTime)
+
+	// then
+	assert.That(status.Interrupted(), t.Errorf, "got status %#v, want it interrupted", status)
+	assert.That(target.Stats.Health == entity.DefaultStats().Health, t.Errorf, "the target got hurt")
+}
+
+func TestAttacksCanKill(t *testing.T) {
+	// given
+	world := entity.NewWorld(lineSpace(3))
+	attacker, _ := world.Spawn(grid.P(0, 0))
+	target, _ := world.Spawn(grid.P(0, 1))
+	rng := rand.New(rand.NewSource(1))
+
+	attacker.Actions.Push(combat.Melee(world, attacker, target, rng))
+
+	// when
+	for tick := 0; tick < 1000 && target.Stats.Health > 0; tick++ {
+		if attacker.Actions.Idle() {
+			attacker.Actions.Push(combat.Melee(world, attacker, target, rng))
+		}
+		world.Update(time.Second / 10)
+	}
+
+	// then
+	_, inWorld := world.Get(target.ID())
+	assert.That(!inWorld, t.Errorf, "the target is still in the world")
+	assert.That(!world.Space().At(grid.P(0, 1)).Taken(), t.Errorf, "the position of the target is still taken")
+}
+
+func lineSpace(n int) *grid.Space {
+	space := grid.NewSpace()
+	for column := 0; column < n; column++ {
+		space.At(grid.P(0, column)).Create()
+	}
+	return space
+}
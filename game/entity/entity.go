@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package entity ties together everything that makes up a unit of the game.
+//
+// Each entity owns its placement in the grid, its queue of actions, its stats and its faction.
+// Anything else, like how the entity looks, can be attached as a component.
+package entity
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+// An ID tells entities of the same world apart.
+// Entities get IDs in the order they are spawned, starting at 1.
+type ID uint64
+
+// An Entity is a single unit of the game.
+//
+// Entities get spawned by a world and have to be referred to by pointer.
+type Entity struct {
+	id ID
+
+	Placement grid.HeadedPlacement
+	Actions   actions.Queue
+	Stats     Stats
+	Faction   Faction
+
+	components map[reflect.Type]any
+}
+
+// Stats describe what an entity is capable of.
+type Stats struct {
+	MaxHealth, Health int
+	// Attack and Defence decide how likely the entity's attacks are to land, and how much they hurt.
+	Attack, Defence int
+	// Range is how many cells away the entity can shoot.
+	Range int
+	// StepTime is how long it takes the entity to move to a neighbouring cell.
+	StepTime time.Duration
+	// AttackTime is how long it takes the entity to attack.
+	AttackTime time.Duration
+}
+
+// DefaultStats are the stats entities get spawned with.
+func DefaultStats() Stats {
+	return Stats{
+		MaxHealth:  10,
+		Health:     10,
+		Attack:     3,
+		Defence:    1,
+		Range:      5,
+		StepTime:   time.Second / 4,
+		AttackTime: time.Second / 2,
+	}
+}
+
+// A Faction is the side an entity is on.
+// The zero value means the entity is on no side at all.
+type Faction string
+
+// NoFaction is the faction of entities that are on no side at all.
+const NoFaction Faction = ""
+
+// ID of the entity.
+func (e *Entity) ID() ID { return e.id }
+
+// Of finds the entity that a placement belongs to.
+// It reports false when the placement is not a part of an entity.
+func Of(placement *grid.HeadedPlacement) (*Entity, bool) {
+	e, ok := placement.Owner().(*Entity)
+	return e, ok
+}
+
+// Set attaches a component to the entity, replacing any other component of the same type.
+func Set[C any](e *Entity, component C) {
+	if e.components == nil {
+		e.components = map[reflect.Type]any{}
+	}
+	e.components[componentType[C]()] = component
+}
+
+// Get finds the entity's component of type C.
+// It reports false when there is none.
+func Get[C any](e *Entity) (C, bool) {
+	component, ok := e.components[componentType[C]()].(C)
+	return component, ok
+}
+
+// Remove detaches the entity's component of type C, if there is one.
+func Remove[C any](e *Entity) {
+	delete(e.components, componentType[C]())
+}
+
+func componentType[C any]() reflect.Type {
+	return reflect.TypeOf((*C)(nil)).Elem()
+}
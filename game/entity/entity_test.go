@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+type Name string
+
+type Appearance interface{ Look() string }
+
+type Sprite struct{ Image string }
+
+func (s Sprite) Look() string { return s.Image }
+
+func TestComponentThatWasNotSetIsMissing(t *testing.T) {
+	// given
+	e := &entity.Entity{}
+
+	// when
+	_, ok := entity.Get[Name](e)
+
+	// then
+	assert.That(!ok, t.Errorf, "got a component that was never set")
+}
+
+func TestComponentCanBeSetAndGot(t *testing.T) {
+	// given
+	e := &entity.Entity{}
+
+	// when
+	entity.Set(e, Name("bob"))
+
+	// then
+	name, ok := entity.Get[Name](e)
+	assert.That(ok, t.Errorf, "the component is missing")
+	assert.That(name == "bob", t.Errorf, "got component %q, want %q", name, "bob")
+}
+
+func TestComponentsAreKeyedByType(t *testing.T) {
+	// given
+	e := &entity.Entity{}
+
+	// when
+	entity.Set(e, Name("bob"))
+	entity.Set[Appearance](e, Sprite{Image: "bob.png"})
+	entity.Set(e, Name("alice"))
+
+	// then
+	name, _ := entity.Get[Name](e)
+	look, ok := entity.Get[Appearance](e)
+	_, concreteOK := entity.Get[Sprite](e)
+	assert.That(name == "alice", t.Errorf, "got name %q, want %q", name, "alice")
+	assert.That(ok && look.Look() == "bob.png", t.Errorf, "got appearance %#v, want %#v", look, Sprite{Image: "bob.png"})
+	assert.That(!concreteOK, t.Errorf, "a component set as an interface was found by its concrete type")
+}
+
+func TestComponentCanBeRemoved(t *testing.T) {
+	// given
+	e := &entity.Entity{}
+	entity.Set(e, Name("bob"))
+
+	// when
+	entity.Remove[Name](e)
+
+	// then
+	_, ok := entity.Get[Name](e)
+	assert.That(!ok, t.Errorf, "got a component that was removed")
+}
+
+func TestEntityCanBeFoundThroughItsPlacement(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	e, _ := world.Spawn(grid.P(0, 0))
+
+	// when
+	found, ok := entity.Of(&e.Placement)
+
+	// then
+	assert.That(ok && found == e, t.Errorf, "got entity %p, want %p", found, e)
+}
+
+func TestPlacementThatIsNotAPartOfAnEntity(t *testing.T) {
+	// given
+	placement := &grid.HeadedPlacement{}
+
+	// when
+	_, ok := entity.Of(placement)
+
+	// then
+	assert.That(!ok, t.Errorf, "found an entity for a placement that is not a part of one")
+}
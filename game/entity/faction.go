@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package entity
+
+import "fmt"
+
+// A Relation is how two factions stand towards each other.
+type Relation int
+
+const (
+	// Neutral factions leave each other alone.
+	Neutral Relation = iota
+	// Allied factions are on the same team.
+	Allied
+	// Hostile factions fight each other.
+	Hostile
+)
+
+func (rel Relation) String() string {
+	switch rel {
+	case Neutral:
+		return "neutral"
+	case Allied:
+		return "allied"
+	case Hostile:
+		return "hostile"
+	}
+	return fmt.Sprintf("Relation(%d)", int(rel))
+}
+
+// Relations keep track of how factions stand towards each other.
+//
+// Relations are the same both ways.
+// A faction is always allied with itself, and entities on no side at all are neutral towards everyone.
+// Factions whose relation was never set stand towards each other as the default says.
+type Relations struct {
+	def     Relation
+	between map[[2]Faction]Relation
+}
+
+// NewRelations creates relations where all factions are hostile towards each other until said otherwise.
+func NewRelations() *Relations {
+	return &Relations{def: Hostile, between: map[[2]Faction]Relation{}}
+}
+
+// SetDefault changes how factions whose relation was never set stand towards each other.
+func (r *Relations) SetDefault(rel Relation) { r.def = rel }
+
+// Set decides how the two factions stand towards each other.
+// Setting the relation of a faction with itself or with no faction has no effect.
+func (r *Relations) Set(a, b Faction, rel Relation) {
+	if a == b || a == NoFaction || b == NoFaction {
+		return
+	}
+	r.between[pair(a, b)] = rel
+}
+
+// Between says how the two factions stand towards each other.
+func (r *Relations) Between(a, b Faction) Relation {
+	switch {
+	case a == NoFaction || b == NoFaction:
+		return Neutral
+	case a == b:
+		return Allied
+	}
+	if rel, ok := r.between[pair(a, b)]; ok {
+		return rel
+	}
+	return r.def
+}
+
+// Hostile says whether the entities are on hostile sides.
+func (r *Relations) Hostile(a, b *Entity) bool {
+	return r.Between(a.Faction, b.Faction) == Hostile
+}
+
+// Allied says whether two different entities are on the same team.
+// An entity is not its own ally.
+func (r *Relations) Allied(a, b *Entity) bool {
+	return a != b && r.Between(a.Faction, b.Faction) == Allied
+}
+
+func pair(a, b Faction) [2]Faction {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]Faction{a, b}
+}
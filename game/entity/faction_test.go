@@ -0,0 +1,70 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/entity"
+)
+
+func TestRelationsBetweenFactions(t *testing.T) {
+	tests := map[string]struct {
+		A, B entity.Faction
+		Want entity.Relation
+	}{
+		"SameFaction":          {A: "red", B: "red", Want: entity.Allied},
+		"SetOneWay":            {A: "red", B: "green", Want: entity.Allied},
+		"SetTheOtherWay":       {A: "green", B: "red", Want: entity.Allied},
+		"NeverSet":             {A: "red", B: "blue", Want: entity.Hostile},
+		"NoFaction":            {A: entity.NoFaction, B: "red", Want: entity.Neutral},
+		"NoFactionWithItself":  {A: entity.NoFaction, B: entity.NoFaction, Want: entity.Neutral},
+		"SetToNeutral":         {A: "blue", B: "green", Want: entity.Neutral},
+		"SetWithItselfIgnored": {A: "blue", B: "blue", Want: entity.Allied},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			relations := entity.NewRelations()
+			relations.Set("red", "green", entity.Allied)
+			relations.Set("green", "blue", entity.Neutral)
+			relations.Set("blue", "blue", entity.Hostile)
+
+			// when
+			rel := relations.Between(tt.A, tt.B)
+
+			// then
+			assert.That(rel == tt.Want, t.Errorf, "got %s, want %s", rel, tt.Want)
+		})
+	}
+}
+
+func TestRelationsDefaultCanChange(t *testing.T) {
+	// given
+	relations := entity.NewRelations()
+	relations.Set("red", "blue", entity.Hostile)
+
+	// when
+	relations.SetDefault(entity.Neutral)
+
+	// then
+	assert.That(relations.Between("red", "green") == entity.Neutral, t.Errorf, "got %s, want %s", relations.Between("red", "green"), entity.Neutral)
+	assert.That(relations.Between("red", "blue") == entity.Hostile, t.Errorf, "got %s, want %s", relations.Between("red", "blue"), entity.Hostile)
+}
+
+func TestEntityIsNotItsOwnAlly(t *testing.T) {
+	// given
+	relations := entity.NewRelations()
+	e := &entity.Entity{Faction: "red"}
+
+	// when
+	allied := relations.Allied(e, e)
+
+	// then
+	assert.That(!allied, t.Errorf, "the entity is its own ally")
+}
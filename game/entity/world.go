@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package entity
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+// A World is a set of entities living in a space.
+type World struct {
+	space     *grid.Space
+	lastID    ID
+	entities  []*Entity
+	byID      map[ID]*Entity
+	relations *Relations
+}
+
+// NewWorld creates a world with no entities, living in space.
+func NewWorld(space *grid.Space) *World {
+	if space == nil {
+		panic("nil space")
+	}
+	return &World{
+		space:     space,
+		byID:      map[ID]*Entity{},
+		relations: NewRelations(),
+	}
+}
+
+// Space the entities live in.
+func (w *World) Space() *grid.Space { return w.space }
+
+// Relations between the factions of the world.
+func (w *World) Relations() *Relations { return w.relations }
+
+// Spawn creates an entity placed at pt, with default stats and no faction.
+// It reports false and creates nothing when the position at pt does not exist or is taken.
+func (w *World) Spawn(pt grid.Point) (*Entity, bool) {
+	e := &Entity{id: w.lastID + 1, Stats: DefaultStats()}
+	e.Placement.SetOwner(e)
+
+	if !e.Placement.Place(w.space.At(pt)) {
+		return nil, false
+	}
+
+	w.lastID = e.id
+	w.entities = append(w.entities, e)
+	w.byID[e.id] = e
+	return e, true
+}
+
+// Despawn removes the entity from the world, freeing the positions it takes.
+// It does nothing to entities that are not in the world.
+func (w *World) Despawn(e *Entity) {
+	if w.byID[e.id] != e {
+		return
+	}
+
+	e.Placement.Leave()
+	e.Actions.Clear()
+	delete(w.byID, e.id)
+	for i, other := range w.entities {
+		if other == e {
+			w.entities = append(w.entities[:i], w.entities[i+1:]...)
+			break
+		}
+	}
+}
+
+// Get finds the entity with the given ID.
+func (w *World) Get(id ID) (*Entity, bool) {
+	e, ok := w.byID[id]
+	return e, ok
+}
+
+// At finds the entity that takes the position at pt.
+// An entity that is moving takes both the position it is at and the one it is heading to.
+func (w *World) At(pt grid.Point) (*Entity, bool) {
+	e, ok := grid.OwnerAt(w.space.At(pt)).(*Entity)
+	if !ok || w.byID[e.id] != e {
+		return nil, false
+	}
+	return e, true
+}
+
+// Near finds the entities that take positions no further than radius from center.
+// They are ordered by the first position found for each, by row and then by column.
+func (w *World) Near(center grid.Point, radius float64) []*Entity {
+	return w.owners(grid.Takers(w.space.InRadius(center, radius)))
+}
+
+// InRect finds the entities that take positions within the rectangle with the corners min and max.
+// They are ordered by the first position found for each, by row and then by column.
+func (w *World) InRect(min, max grid.Point) []*Entity {
+	return w.owners(grid.Takers(w.space.InRect(min, max)))
+}
+
+func (w *World) owners(takers []grid.SpaceTaker) []*Entity {
+	var found []*Entity
+	seen := map[*Entity]bool{}
+	for _, taker := range takers {
+		owned, ok := taker.(grid.Owned)
+		if !ok {
+			continue
+		}
+		e, ok := owned.Owner().(*Entity)
+		if !ok || seen[e] || w.byID[e.id] != e {
+			continue
+		}
+		seen[e] = true
+		found = append(found, e)
+	}
+	return found
+}
+
+// HostileNear finds the entities hostile to e that take positions no further than radius from where e is.
+// They are ordered like the ones Near finds.
+func (w *World) HostileNear(e *Entity, radius float64) []*Entity {
+	var hostile []*Entity
+	for _, other := range w.Near(e.Placement.AtPoint(), radius) {
+		if w.relations.Hostile(e, other) {
+			hostile = append(hostile, other)
+		}
+	}
+	return hostile
+}
+
+// AllyAt says whether the position at pt is taken by an ally of e.
+func (w *World) AllyAt(e *Entity, pt grid.Point) bool {
+	other, ok := w.At(pt)
+	return ok && w.relations.Allied(e, other)
+}
+
+// PassableFor says which taken positions e can path through - the ones taken by itself or its allies.
+// It is meant for the Through of the path finders in grid.
+func (w *World) PassableFor(e *Entity) func(grid.Position) bool {
+	return func(pos grid.Position) bool {
+		other, ok := grid.OwnerAt(pos).(*Entity)
+		return ok && w.byID[other.id] == other && (other == e || w.relations.Allied(e, other))
+	}
+}
+
+// Faction finds the entities on the given side, in the order they were spawned in.
+func (w *World) Faction(f Faction) []*Entity {
+	var members []*Entity
+	for _, e := range w.entities {
+		if e.Faction == f {
+			members = append(members, e)
+		}
+	}
+	return members
+}
+
+// Factions lists the sides the entities in the world are on, in the order the first entity of each was spawned in.
+// Entities on no side at all are left out.
+// Together with Faction, it is what sides taking turns can be ordered by.
+func (w *World) Factions() []Faction {
+	var factions []Faction
+	seen := map[Faction]bool{}
+	for _, e := range w.entities {
+		if e.Faction == NoFaction || seen[e.Faction] {
+			continue
+		}
+		seen[e.Faction] = true
+		factions = append(factions, e.Faction)
+	}
+	return factions
+}
+
+// All the entities in the world, in the order they were spawned in.
+// The returned slice must not be modified.
+func (w *World) All() []*Entity { return w.entities }
+
+// Update runs the actions of all the entities for dt.
+// Entities that get despawned along the way do not get to run their actions.
+func (w *World) Update(dt time.Duration) {
+	entities := append([]*Entity(nil), w.entities...)
+	for _, e := range entities {
+		if w.byID[e.id] == e {
+			e.Actions.Run(dt)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/entity"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestSpawnedEntityIsPlaced(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+
+	// when
+	e, ok := world.Spawn(grid.P(1, 0))
+
+	// then
+	assert.That(ok, t.Fatalf, "spawning failed")
+	assert.That(e.Placement.Placed(), t.Errorf, "the entity is not placed")
+	assert.That(e.Placement.AtPoint() == grid.P(1, 0), t.Errorf, "entity at %v, want %v", e.Placement.AtPoint(), grid.P(1, 0))
+	assert.That(e.Stats == entity.DefaultStats(), t.Errorf, "got stats %#v, want %#v", e.Stats, entity.DefaultStats())
+}
+
+func TestSpawnFails(t *testing.T) {
+	tests := map[string]struct {
+		At grid.Point
+	}{
+		"WhereThereIsNoPosition": {At: grid.P(5, 5)},
+		"WherePositionIsTaken":   {At: grid.P(0, 0)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			world := entity.NewWorld(squareSpace(2))
+			world.Spawn(grid.P(0, 0))
+
+			// when
+			_, ok := world.Spawn(tt.At)
+
+			// then
+			assert.That(!ok, t.Errorf, "spawning succeeded")
+			assert.That(len(world.All()) == 1, t.Errorf, "got %d entities, want %d", len(world.All()), 1)
+		})
+	}
+}
+
+func TestSpawnedEntitiesGetDistinctIDs(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	world.Spawn(grid.P(0, 0))
+	// A failed spawn does not use up an ID.
+	world.Spawn(grid.P(0, 0))
+
+	// when
+	e, _ := world.Spawn(grid.P(0, 1))
+
+	// then
+	assert.That(e.ID() == 2, t.Errorf, "got ID %d, want %d", e.ID(), 2)
+	found, ok := world.Get(2)
+	assert.That(ok && found == e, t.Errorf, "got entity %p, want %p", found, e)
+}
+
+func TestWorldFindsEntityAtPoint(t *testing.T) {
+	tests := map[string]struct {
+		At    grid.Point
+		Found bool
+	}{
+		"WhereTheEntityIs": {At: grid.P(0, 0), Found: true},
+		"WhereItIsHeaded":  {At: grid.P(0, 1), Found: true},
+		"Elsewhere":        {At: grid.P(1, 1)},
+		"OutsideTheSpace":  {At: grid.P(5, 5)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := squareSpace(2)
+			world := entity.NewWorld(space)
+			e, _ := world.Spawn(grid.P(0, 0))
+			e.Actions.Push(e.Placement.MoveTo(space.At(grid.P(0, 1)), time.Second))
+			world.Update(0)
+
+			// when
+			found, ok := world.At(tt.At)
+
+			// then
+			assert.That(ok == tt.Found, t.Errorf, "found an entity: %v, want %v", ok, tt.Found)
+			assert.That(!ok || found == e, t.Errorf, "got entity %p, want %p", found, e)
+		})
+	}
+}
+
+func TestDespawnedEntityIsGone(t *testing.T) {
+	// given
+	space := squareSpace(2)
+	world := entity.NewWorld(space)
+	e, _ := world.Spawn(grid.P(0, 0))
+	other, _ := world.Spawn(grid.P(1, 1))
+
+	// when
+	world.Despawn(e)
+
+	// then
+	_, foundAt := world.At(grid.P(0, 0))
+	_, foundByID := world.Get(e.ID())
+	assert.That(!foundAt, t.Errorf, "the entity was found at its point")
+	assert.That(!foundByID, t.Errorf, "the entity was found by its ID")
+	assert.That(!space.At(grid.P(0, 0)).Taken(), t.Errorf, "the entity's position is still taken")
+	assert.That(
+		len(world.All()) == 1 && world.All()[0] == other,
+		t.Errorf, "got entities %v, want just %p", world.All(), other)
+}
+
+func TestUpdateRunsEntityActions(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	e, _ := world.Spawn(grid.P(0, 0))
+	e.Actions.Push(actions.Wait(time.Second))
+	e.Actions.Push(actions.Wait(time.Second))
+
+	// when
+	world.Update(3 * time.Second / 2)
+
+	// then
+	assert.That(e.Actions.Len() == 1, t.Errorf, "got %d actions left, want %d", e.Actions.Len(), 1)
+}
+
+func TestUpdateSkipsEntitiesDespawnedAlongTheWay(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	first, _ := world.Spawn(grid.P(0, 0))
+	second, _ := world.Spawn(grid.P(1, 1))
+
+	first.Actions.Push(despawn(world, second))
+	second.Actions.Push(actions.Wait(time.Second))
+
+	// when
+	world.Update(time.Second)
+
+	// then
+	assert.That(second.Actions.Len() == 0, t.Errorf, "the despawned entity still has actions")
+	assert.That(len(world.All()) == 1, t.Errorf, "got %d entities, want %d", len(world.All()), 1)
+}
+
+func despawn(world *entity.World, e *entity.Entity) actions.Action {
+	return actionFunc(func(atMost time.Duration) actions.Status {
+		world.Despawn(e)
+		return actions.Done(atMost)
+	})
+}
+
+type actionFunc func(atMost time.Duration) actions.Status
+
+func (f actionFunc) Run(atMost time.Duration) actions.Status { return f(atMost) }
+
+func squareSpace(size int) *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < size; row++ {
+		for column := 0; column < size; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	return space
+}
+
+func TestWorldFindsEntitiesNearby(t *testing.T) {
+	tests := map[string]struct {
+		Center grid.Point
+		Radius float64
+		Want   []int
+	}{
+		"Nobody":          {Center: grid.P(3, 3), Radius: 0.5},
+		"JustOne":         {Center: grid.P(0, 0), Radius: 0, Want: []int{0}},
+		"Both":            {Center: grid.P(0, 0), Radius: 1.5, Want: []int{0, 1}},
+		"MovingIsCounted": {Center: grid.P(2, 1), Radius: 0, Want: []int{1}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := squareSpace(3)
+			world := entity.NewWorld(space)
+			first, _ := world.Spawn(grid.P(0, 0))
+			second, _ := world.Spawn(grid.P(1, 1))
+			second.Actions.Push(second.Placement.MoveTo(space.At(grid.P(2, 1)), time.Second))
+			world.Update(0)
+			units := []*entity.Entity{first, second}
+
+			// when
+			found := world.Near(tt.Center, tt.Radius)
+
+			// then
+			assert.That(len(found) == len(tt.Want), t.Fatalf, "got %d entities, want %d", len(found), len(tt.Want))
+			for i, j := range tt.Want {
+				assert.That(found[i] == units[j], t.Errorf, "got entity %d: %p, want %p", i, found[i], units[j])
+			}
+		})
+	}
+}
+
+func TestWorldFindsEntitiesInRect(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(3))
+	world.Spawn(grid.P(0, 0))
+	inside, _ := world.Spawn(grid.P(1, 2))
+
+	// when
+	found := world.InRect(grid.P(1, 1), grid.P(2, 2))
+
+	// then
+	assert.That(len(found) == 1 && found[0] == inside, t.Errorf, "got entities %v, want just %p", found, inside)
+}
+
+func TestWorldFindsHostileEntitiesNearby(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(4))
+	world.Relations().Set("red", "green", entity.Allied)
+	e := spawnIn(world, grid.P(0, 0), "red")
+	spawnIn(world, grid.P(0, 1), "red")
+	spawnIn(world, grid.P(1, 0), "green")
+	spawnIn(world, grid.P(1, 1), entity.NoFaction)
+	near := spawnIn(world, grid.P(2, 0), "blue")
+	spawnIn(world, grid.P(3, 3), "blue")
+
+	// when
+	found := world.HostileNear(e, 2)
+
+	// then
+	assert.That(len(found) == 1 && found[0] == near, t.Errorf, "got entities %v, want just %p", found, near)
+}
+
+func TestWorldTellsAlliesApart(t *testing.T) {
+	tests := map[string]struct {
+		At   grid.Point
+		Want bool
+	}{
+		"Itself":  {At: grid.P(0, 0), Want: false},
+		"Ally":    {At: grid.P(0, 1), Want: true},
+		"Enemy":   {At: grid.P(1, 0), Want: false},
+		"Nobody":  {At: grid.P(1, 1), Want: false},
+		"Nowhere": {At: grid.P(5, 5), Want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			world := entity.NewWorld(squareSpace(2))
+			e := spawnIn(world, grid.P(0, 0), "red")
+			spawnIn(world, grid.P(0, 1), "red")
+			spawnIn(world, grid.P(1, 0), "blue")
+
+			// when
+			allied := world.AllyAt(e, tt.At)
+
+			// then
+			assert.That(allied == tt.Want, t.Errorf, "got %v, want %v", allied, tt.Want)
+		})
+	}
+}
+
+func TestPathsGoThroughAlliesOnly(t *testing.T) {
+	tests := map[string]struct {
+		Blocker entity.Faction
+		Found   bool
+	}{
+		"Ally":  {Blocker: "red", Found: true},
+		"Enemy": {Blocker: "blue", Found: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := grid.NewSpace()
+			for column := 0; column < 3; column++ {
+				space.At(grid.P(0, column)).Create()
+			}
+			world := entity.NewWorld(space)
+			e := spawnIn(world, grid.P(0, 0), "red")
+			spawnIn(world, grid.P(0, 1), tt.Blocker)
+			pf := grid.NewPathFinder(space).Through(world.PassableFor(e))
+
+			// when
+			_, found := pf.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 2)))
+
+			// then
+			assert.That(found == tt.Found, t.Errorf, "path found: %v, want %v", found, tt.Found)
+		})
+	}
+}
+
+func TestWorldFindsFactionMembersInSpawnOrder(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	first := spawnIn(world, grid.P(1, 1), "red")
+	spawnIn(world, grid.P(0, 0), "blue")
+	second := spawnIn(world, grid.P(0, 1), "red")
+
+	// when
+	found := world.Faction("red")
+
+	// then
+	assert.That(len(found) == 2 && found[0] == first && found[1] == second, t.Errorf, "got entities %v, want %p and %p", found, first, second)
+}
+
+func TestWorldListsFactionsInSpawnOrder(t *testing.T) {
+	// given
+	world := entity.NewWorld(squareSpace(2))
+	spawnIn(world, grid.P(1, 1), "red")
+	spawnIn(world, grid.P(0, 0), entity.NoFaction)
+	spawnIn(world, grid.P(0, 1), "blue")
+	spawnIn(world, grid.P(1, 0), "red")
+
+	// when
+	factions := world.Factions()
+
+	// then
+	assert.That(len(factions) == 2 && factions[0] == "red" && factions[1] == "blue", t.Errorf, "got factions %v, want [red blue]", factions)
+}
+
+func spawnIn(world *entity.World, pt grid.Point, faction entity.Faction) *entity.Entity {
+	e, _ := world.Spawn(pt)
+	e.Faction = faction
+	return e
+}
@@ -0,0 +1,130 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+// Positions are stored in square chunks, so that large spaces do not need a map entry per position.
+const (
+	_ChunkBits = 4
+	_ChunkSize = 1 << _ChunkBits
+	_ChunkMask = _ChunkSize - 1
+	_ChunkArea = _ChunkSize * _ChunkSize
+)
+
+// A _Chunk holds the positions in a _ChunkSize by _ChunkSize square of the grid.
+type _Chunk struct {
+	exists [_ChunkArea]bool
+	takers [_ChunkArea]SpaceTaker
+	count  int
+}
+
+func (chunk *_Chunk) each(key Point, f func(at Point, taker SpaceTaker)) {
+	for index := range chunk.exists {
+		if chunk.exists[index] {
+			f(chunkPoint(key, index), chunk.takers[index])
+		}
+	}
+}
+
+// chunkOf says which chunk the point is in and where in the chunk it is.
+// Arithmetic shifts round towards negative infinity, so points with negative coordinates end up in the right chunk.
+func chunkOf(pt Point) (key Point, index int) {
+	key = P(pt.Row>>_ChunkBits, pt.Column>>_ChunkBits)
+	index = (pt.Row&_ChunkMask)<<_ChunkBits | pt.Column&_ChunkMask
+	return key, index
+}
+
+// chunkPoint is the inverse of chunkOf.
+func chunkPoint(key Point, index int) Point {
+	return P(key.Row<<_ChunkBits|index>>_ChunkBits, key.Column<<_ChunkBits|index&_ChunkMask)
+}
+
+// _Bounds keep track of the rows and columns that have positions in them, to know the space's Min and Max without looking through all the positions.
+type _Bounds struct {
+	rows, columns map[int]int
+	min, max      Point
+}
+
+func newBounds() _Bounds {
+	return _Bounds{rows: map[int]int{}, columns: map[int]int{}}
+}
+
+func (b *_Bounds) add(pt Point) {
+	if len(b.rows) == 0 {
+		b.min, b.max = pt, pt
+	}
+	b.rows[pt.Row]++
+	b.columns[pt.Column]++
+	b.min = P(minInt(b.min.Row, pt.Row), minInt(b.min.Column, pt.Column))
+	b.max = P(maxInt(b.max.Row, pt.Row), maxInt(b.max.Column, pt.Column))
+}
+
+func (b *_Bounds) remove(pt Point) {
+	b.rows[pt.Row]--
+	b.columns[pt.Column]--
+	rowGone, columnGone := b.rows[pt.Row] == 0, b.columns[pt.Column] == 0
+	if rowGone {
+		delete(b.rows, pt.Row)
+	}
+	if columnGone {
+		delete(b.columns, pt.Column)
+	}
+
+	if len(b.rows) == 0 {
+		b.min, b.max = Point{}, Point{}
+		return
+	}
+	if rowGone {
+		shrink(b.rows, &b.min.Row, &b.max.Row, pt.Row)
+	}
+	if columnGone {
+		shrink(b.columns, &b.min.Column, &b.max.Column, pt.Column)
+	}
+}
+
+// shrink moves min or max inwards to the nearest coordinate that is still in use, after gone stopped being used.
+// It looks through the coordinates in use instead of stepping towards them, so far apart positions do not make it slow.
+// The coordinates in use are never empty when it is called.
+func shrink(inUse map[int]int, min, max *int, gone int) {
+	if gone != *min && gone != *max {
+		return
+	}
+	first := true
+	for c := range inUse {
+		if first {
+			*min, *max = c, c
+			first = false
+		}
+		*min, *max = minInt(*min, c), maxInt(*max, c)
+	}
+}
+
+// atMostCells says whether the rectangle with the corners min and max has no more than limit cells.
+// The spans are checked against the limit before they get multiplied, so that huge rectangles do not overflow.
+// The corners must be in order.
+func atMostCells(min, max Point, limit int) bool {
+	if limit < 1 {
+		return false
+	}
+	// Unsigned differences are exact even when the signed ones would overflow.
+	rows, columns := uint(max.Row)-uint(min.Row), uint(max.Column)-uint(min.Column)
+	if rows >= uint(limit) || columns >= uint(limit) {
+		return false
+	}
+	return (rows+1)*(columns+1) <= uint(limit)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import "fmt"
+
+// An EventKind says what kind of change happened to a position.
+type EventKind int
+
+const (
+	// PositionCreated is emitted when a position starts to exist.
+	PositionCreated EventKind = iota
+	// PositionDestroyed is emitted when a position stops to exist.
+	PositionDestroyed
+	// PositionTaken is emitted when a space taker takes a position.
+	PositionTaken
+	// PositionFreed is emitted when a space taker is forced off a position.
+	PositionFreed
+)
+
+func (kind EventKind) String() string {
+	switch kind {
+	case PositionCreated:
+		return "created"
+	case PositionDestroyed:
+		return "destroyed"
+	case PositionTaken:
+		return "taken"
+	case PositionFreed:
+		return "freed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(kind))
+	}
+}
+
+// An Event describes a change made to a position in a space.
+type Event struct {
+	Kind EventKind
+	At   Point
+	// Taker is what took or was forced off the position.
+	// It is nil for the events that do not involve a taker.
+	Taker SpaceTaker
+}
+
+// A Subscription to the events of a space.
+type Subscription struct {
+	space     *Space
+	handle    func(Event)
+	cancelled bool
+}
+
+// Subscribe makes the space call handle with an event for every change made to it.
+// That goes on until the subscription is cancelled.
+//
+// Events are emitted after the change is made and the space takers are notified of it.
+// The handler can change the space - the events for those changes are emitted before it returns.
+func (space *Space) Subscribe(handle func(Event)) *Subscription {
+	sub := &Subscription{space: space, handle: handle}
+	space.subs = append(space.subs, sub)
+	return sub
+}
+
+// Cancel stops the events from being passed to the subscriber.
+// It can be called from within the handler.
+func (sub *Subscription) Cancel() {
+	if sub.cancelled {
+		return
+	}
+	sub.cancelled = true
+
+	subs := sub.space.subs
+	for i, other := range subs {
+		if other == sub {
+			sub.space.subs = append(subs[:i:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (space *Space) emit(event Event) {
+	if len(space.subs) == 0 {
+		return
+	}
+	subs := append([]*Subscription(nil), space.subs...)
+	for _, sub := range subs {
+		if !sub.cancelled {
+			sub.handle(event)
+		}
+	}
+}
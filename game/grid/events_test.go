@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestSpaceEmitsEventsForChanges(t *testing.T) {
+	taker := grid.DummyTaker()
+	pt := grid.P(2, 3)
+
+	kases := map[string]struct {
+		Prepare func(pos grid.Position)
+		Change  func(pos grid.Position)
+		Want    []grid.Event
+	}{
+		"Create": {
+			Prepare: func(grid.Position) {},
+			Change:  func(pos grid.Position) { pos.Create() },
+			Want:    []grid.Event{{Kind: grid.PositionCreated, At: pt}},
+		},
+		"Destroy": {
+			Prepare: func(pos grid.Position) { pos.Create() },
+			Change:  func(pos grid.Position) { pos.Destroy() },
+			Want:    []grid.Event{{Kind: grid.PositionDestroyed, At: pt}},
+		},
+		"Take": {
+			Prepare: func(pos grid.Position) { pos.Create() },
+			Change:  func(pos grid.Position) { pos.Take(taker) },
+			Want:    []grid.Event{{Kind: grid.PositionTaken, At: pt, Taker: taker}},
+		},
+		"Free": {
+			Prepare: func(pos grid.Position) { pos.Create(); pos.Take(taker) },
+			Change:  func(pos grid.Position) { pos.Free() },
+			Want:    []grid.Event{{Kind: grid.PositionFreed, At: pt, Taker: taker}},
+		},
+		"FailedCreate": {
+			Prepare: func(pos grid.Position) { pos.Create() },
+			Change:  func(pos grid.Position) { pos.Create() },
+		},
+		"FailedDestroy": {
+			Prepare: func(grid.Position) {},
+			Change:  func(pos grid.Position) { pos.Destroy() },
+		},
+		"FailedTake": {
+			Prepare: func(grid.Position) {},
+			Change:  func(pos grid.Position) { pos.Take(taker) },
+		},
+		"FailedFree": {
+			Prepare: func(pos grid.Position) { pos.Create() },
+			Change:  func(pos grid.Position) { pos.Free() },
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := grid.NewSpace()
+			pos := space.At(pt)
+			kase.Prepare(pos)
+
+			var events []grid.Event
+			space.Subscribe(func(ev grid.Event) { events = append(events, ev) })
+
+			// when
+			kase.Change(pos)
+
+			// then
+			assert.That(eventsEqual(events, kase.Want), t.Errorf, "got events %v, want %v", events, kase.Want)
+		})
+	}
+}
+
+func TestCancelledSubscriptionGetsNoEvents(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+
+	var events []grid.Event
+	sub := space.Subscribe(func(ev grid.Event) { events = append(events, ev) })
+
+	// when
+	sub.Cancel()
+	space.At(grid.P(0, 0)).Create()
+
+	// then
+	assert.That(len(events) == 0, t.Errorf, "got events %v, want none", events)
+}
+
+func TestSubscriptionCanBeCancelledByItsHandler(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+
+	var first, second []grid.Event
+	var sub *grid.Subscription
+	sub = space.Subscribe(func(ev grid.Event) {
+		first = append(first, ev)
+		sub.Cancel()
+	})
+	space.Subscribe(func(ev grid.Event) { second = append(second, ev) })
+
+	// when
+	space.At(grid.P(0, 0)).Create()
+	space.At(grid.P(0, 1)).Create()
+
+	// then
+	assert.That(len(first) == 1, t.Errorf, "got %d events before cancelling, want %d", len(first), 1)
+	assert.That(len(second) == 2, t.Errorf, "got %d events for the other subscriber, want %d", len(second), 2)
+}
+
+func TestCommittedTransactionEmitsEventsInOrder(t *testing.T) {
+	// given
+	space := lineSpace(1)
+	taker := grid.DummyTaker()
+
+	var events []grid.Event
+	space.Subscribe(func(ev grid.Event) { events = append(events, ev) })
+
+	tx := grid.NewTransaction(space)
+	tx.Create(grid.P(0, 1))
+	tx.Take(grid.P(0, 1), taker)
+	tx.Destroy(grid.P(0, 0))
+
+	// when
+	_ = tx.Commit()
+
+	// then
+	want := []grid.Event{
+		{Kind: grid.PositionCreated, At: grid.P(0, 1)},
+		{Kind: grid.PositionTaken, At: grid.P(0, 1), Taker: taker},
+		{Kind: grid.PositionDestroyed, At: grid.P(0, 0)},
+	}
+	assert.That(eventsEqual(events, want), t.Errorf, "got events %v, want %v", events, want)
+}
+
+func TestFailedTransactionEmitsNoEvents(t *testing.T) {
+	// given
+	space := lineSpace(1)
+
+	var events []grid.Event
+	space.Subscribe(func(ev grid.Event) { events = append(events, ev) })
+
+	tx := grid.NewTransaction(space)
+	tx.Create(grid.P(0, 1))
+	tx.Destroy(grid.P(0, 0))
+	space.At(grid.P(0, 0)).Take(grid.DummyTaker())
+	events = nil
+
+	// when
+	_ = tx.Commit()
+
+	// then
+	assert.That(len(events) == 0, t.Errorf, "got events %v, want none", events)
+}
+
+func eventsEqual(a, b []grid.Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// A FlowField leads from every position in a space to the nearest of a set of goals.
+//
+// It is meant for many things heading to the same place, which would otherwise all have to search for paths on their own.
+// Only what positions exist matters to it - things taking up positions are for those following the field to get around.
+//
+// It follows the space's events and gets computed again after positions are created or destroyed.
+type FlowField struct {
+	space *Space
+	goals []Point
+	dist  map[Point]int
+	stale bool
+	sub   *Subscription
+}
+
+// NewFlowField creates a flow field leading to the goals.
+// Goals from other spaces and ones that do not exist are ignored.
+//
+// Close has to be called once the field is not needed anymore, so that it stops following the space.
+func NewFlowField(space *Space, goals ...Position) *FlowField {
+	ff := &FlowField{space: space, stale: true}
+	for _, goal := range goals {
+		if goal.space == space {
+			ff.goals = append(ff.goals, goal.at)
+		}
+	}
+	ff.sub = space.Subscribe(ff.update)
+	return ff
+}
+
+// Close stops the field from following the changes to the space.
+// It keeps leading the way it did before.
+func (ff *FlowField) Close() { ff.sub.Cancel() }
+
+// IsGoal says whether the position is one of the goals.
+func (ff *FlowField) IsGoal(pos Position) bool {
+	d, ok := ff.Distance(pos)
+	return ok && d == 0
+}
+
+// Reaches says whether there is a way from the position to a goal.
+func (ff *FlowField) Reaches(pos Position) bool {
+	_, ok := ff.Distance(pos)
+	return ok
+}
+
+// Distance is the number of steps from the position to the nearest goal.
+// It reports false when no goal can be reached from the position.
+func (ff *FlowField) Distance(pos Position) (int, bool) {
+	if pos.space != ff.space {
+		return 0, false
+	}
+	ff.refresh()
+	d, ok := ff.dist[pos.at]
+	return d, ok
+}
+
+// Next is the position to step to from pos, to get closer to the nearest goal.
+// It reports false when pos is a goal or when no goal can be reached from it.
+func (ff *FlowField) Next(pos Position) (Position, bool) {
+	steps := ff.downhill(pos)
+	if len(steps) == 0 {
+		return pos, false
+	}
+	return steps[0], true
+}
+
+// downhill are all the positions next to pos that are a step closer to a goal, in the order Next prefers them.
+func (ff *FlowField) downhill(pos Position) []Position {
+	d, ok := ff.Distance(pos)
+	if !ok || d == 0 {
+		return nil
+	}
+	var found []Position
+	for _, pt := range neighbours(pos.at) {
+		if next, ok := ff.dist[pt]; ok && next == d-1 {
+			found = append(found, ff.space.At(pt))
+		}
+	}
+	return found
+}
+
+func (ff *FlowField) update(ev Event) {
+	if ev.Kind == PositionCreated || ev.Kind == PositionDestroyed {
+		ff.stale = true
+	}
+}
+
+func (ff *FlowField) refresh() {
+	if !ff.stale {
+		return
+	}
+	ff.stale = false
+	ff.dist = map[Point]int{}
+
+	var queue []Point
+	for _, goal := range ff.goals {
+		if _, seen := ff.dist[goal]; seen || !ff.space.At(goal).Exists() {
+			continue
+		}
+		ff.dist[goal] = 0
+		queue = append(queue, goal)
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range neighbours(current) {
+			if _, seen := ff.dist[next]; seen || !ff.space.At(next).Exists() {
+				continue
+			}
+			ff.dist[next] = ff.dist[current] + 1
+			queue = append(queue, next)
+		}
+	}
+}
+
+// FollowFlow creates an action that moves the placement along the flow field until it gets to a goal.
+// Each step takes stepDt.
+//
+// When the step the field leads to is taken, the placement takes another one that gets it as close to a goal, if there is one.
+// Otherwise it waits for the way to clear.
+// The action is interrupted when the placement is not placed or when no goal can be reached from where it is.
+func (hp *HeadedPlacement) FollowFlow(field *FlowField, stepDt time.Duration) actions.Action {
+	return &_FollowFlowAction{placement: hp, field: field, stepDt: stepDt, stepper: _Stepper{placement: hp}}
+}
+
+type _FollowFlowAction struct {
+	placement *HeadedPlacement
+	field     *FlowField
+	stepDt    time.Duration
+	stepper   _Stepper
+}
+
+func (action *_FollowFlowAction) Run(atMost time.Duration) actions.Status {
+	hp := action.placement
+	for {
+		ready, timeLeft, blocked := action.stepper.advance(atMost)
+		if !ready || blocked {
+			// A blocked step gets replaced with another one the next time.
+			return actions.Paused()
+		}
+		atMost = timeLeft
+
+		if !hp.Placed() {
+			return actions.Interrupted(atMost)
+		}
+		at := hp.pos.pos
+		if action.field.IsGoal(at) {
+			return actions.Done(atMost)
+		}
+		if !action.field.Reaches(at) {
+			return actions.Interrupted(atMost)
+		}
+
+		next, ok := action.freeStep(at)
+		if !ok {
+			return actions.Paused()
+		}
+		action.stepper.moveTo(next, action.stepDt)
+	}
+}
+
+func (action *_FollowFlowAction) freeStep(at Position) (Position, bool) {
+	for _, next := range action.field.downhill(at) {
+		if !next.Taken() {
+			return next, true
+		}
+	}
+	return at, false
+}
@@ -0,0 +1,203 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestFlowFieldDistanceIsToTheNearestGoal(t *testing.T) {
+	// given
+	space := lineSpace(10)
+	field := grid.NewFlowField(space, space.At(grid.P(0, 0)), space.At(grid.P(0, 9)))
+	defer field.Close()
+
+	kases := map[grid.Point]int{
+		grid.P(0, 0): 0,
+		grid.P(0, 3): 3,
+		grid.P(0, 6): 3,
+		grid.P(0, 9): 0,
+	}
+
+	for pt, want := range kases {
+		// when
+		d, ok := field.Distance(space.At(pt))
+
+		// then
+		assert.That(ok, t.Errorf, "no goal is reached from %v", pt)
+		assert.That(d == want, t.Errorf, "got distance %d from %v, want %d", d, pt, want)
+	}
+}
+
+func TestFlowFieldLeadsTowardsTheNearestGoal(t *testing.T) {
+	// given
+	space := lineSpace(10)
+	field := grid.NewFlowField(space, space.At(grid.P(0, 0)), space.At(grid.P(0, 9)))
+	defer field.Close()
+
+	// when
+	left, leftOK := field.Next(space.At(grid.P(0, 3)))
+	right, rightOK := field.Next(space.At(grid.P(0, 6)))
+
+	// then
+	assert.That(leftOK && left.AtPoint() == grid.P(0, 2), t.Errorf, "got next %v (%v), want %v", left.AtPoint(), leftOK, grid.P(0, 2))
+	assert.That(rightOK && right.AtPoint() == grid.P(0, 7), t.Errorf, "got next %v (%v), want %v", right.AtPoint(), rightOK, grid.P(0, 7))
+}
+
+func TestFlowFieldHasNoNextStepAtGoalOrWhereGoalIsUnreachable(t *testing.T) {
+	// given
+	space := lineSpace(10)
+	space.At(grid.P(0, 5)).Destroy()
+	field := grid.NewFlowField(space, space.At(grid.P(0, 0)))
+	defer field.Close()
+
+	for _, pt := range []grid.Point{grid.P(0, 0), grid.P(0, 7), grid.P(3, 3)} {
+		// when
+		_, ok := field.Next(space.At(pt))
+
+		// then
+		assert.That(!ok, t.Errorf, "got a next step from %v", pt)
+	}
+}
+
+func TestFlowFieldFollowsChangesToTheSpace(t *testing.T) {
+	// given
+	space := rectSpace(3, 5)
+	field := grid.NewFlowField(space, space.At(grid.P(1, 4)))
+	defer field.Close()
+	before, _ := field.Distance(space.At(grid.P(1, 0)))
+
+	// when
+	space.At(grid.P(1, 2)).Destroy()
+	space.At(grid.P(2, 2)).Destroy()
+	after, _ := field.Distance(space.At(grid.P(1, 0)))
+
+	// then
+	assert.That(before == 4, t.Errorf, "got distance %d before the change, want %d", before, 4)
+	assert.That(after == 6, t.Errorf, "got distance %d after the change, want %d", after, 6)
+}
+
+func TestFlowFieldIgnoresTakenPositions(t *testing.T) {
+	// given
+	space := lineSpace(5)
+	space.At(grid.P(0, 2)).Take(grid.DummyTaker())
+	field := grid.NewFlowField(space, space.At(grid.P(0, 4)))
+	defer field.Close()
+
+	// when
+	d, ok := field.Distance(space.At(grid.P(0, 0)))
+
+	// then
+	assert.That(ok && d == 4, t.Errorf, "got distance %d (%v), want %d", d, ok, 4)
+}
+
+func TestPlacementFollowingFlowGetsToTheGoal(t *testing.T) {
+	// given
+	space := rectSpace(3, 5)
+	field := grid.NewFlowField(space, space.At(grid.P(2, 4)))
+	defer field.Close()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.FollowFlow(field, time.Second)
+
+	// when
+	status := action.Run(6*time.Second + time.Second/2)
+
+	// then
+	assert.That(status == actions.Done(time.Second/2), t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second/2))
+	assertPlaced(t, &placement, grid.P(2, 4))
+}
+
+func TestPlacementFollowingFlowGoesAroundTakenPositions(t *testing.T) {
+	// given
+	space := rectSpace(2, 3)
+	space.At(grid.P(0, 1)).Take(grid.DummyTaker())
+	field := grid.NewFlowField(space, space.At(grid.P(1, 2)))
+	defer field.Close()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.FollowFlow(field, time.Second)
+
+	// when
+	status := action.Run(3 * time.Second)
+
+	// then
+	assert.That(status.Done(), t.Errorf, "got status %#v, want it done", status)
+	assertPlaced(t, &placement, grid.P(1, 2))
+}
+
+func TestPlacementFollowingFlowWaitsForTheWayToClear(t *testing.T) {
+	// given
+	space := lineSpace(3)
+	blocker := space.At(grid.P(0, 1))
+	blocker.Take(grid.DummyTaker())
+	field := grid.NewFlowField(space, space.At(grid.P(0, 2)))
+	defer field.Close()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.FollowFlow(field, time.Second)
+	waiting := action.Run(5 * time.Second)
+	assert.That(waiting == actions.Paused(), t.Errorf, "got status %#v while blocked, want %#v", waiting, actions.Paused())
+	assertPlaced(t, &placement, grid.P(0, 0))
+
+	// when
+	blocker.Free()
+	status := action.Run(2 * time.Second)
+
+	// then
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 2))
+}
+
+func TestPlacementFollowingFlowIsInterruptedWhereTheGoalIsUnreachable(t *testing.T) {
+	// given
+	space := lineSpace(5)
+	space.At(grid.P(0, 2)).Destroy()
+	field := grid.NewFlowField(space, space.At(grid.P(0, 4)))
+	defer field.Close()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.FollowFlow(field, time.Second)
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status == actions.Interrupted(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Interrupted(time.Second))
+}
+
+func TestPlacementFollowingFlowFinishesItsStepFirst(t *testing.T) {
+	// given
+	space := lineSpace(4)
+	field := grid.NewFlowField(space, space.At(grid.P(0, 0)))
+	defer field.Close()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 2)))
+	placement.MoveTo(space.At(grid.P(0, 3)), time.Second).Run(time.Second / 2)
+
+	action := placement.FollowFlow(field, time.Second)
+
+	// when
+	status := action.Run(time.Second/2 + 3*time.Second)
+
+	// then
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 0))
+}
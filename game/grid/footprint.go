@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"fmt"
+)
+
+// A Footprint is the shape of the area something takes up in a space.
+// It lists the points it covers relative to an anchor point.
+type Footprint []Point
+
+// SingleCell is the footprint of something that takes up just the anchor point.
+func SingleCell() Footprint { return Footprint{{}} }
+
+// RectFootprint is the footprint of something that takes up a rectangle of rows by columns.
+// The anchor is at the rectangle's corner with the lowest row and column.
+func RectFootprint(rows, columns int) Footprint {
+	if rows <= 0 || columns <= 0 {
+		panic(fmt.Sprintf("invalid footprint size %dx%d", rows, columns))
+	}
+	footprint := make(Footprint, 0, rows*columns)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			footprint = append(footprint, P(row, column))
+		}
+	}
+	return footprint
+}
+
+// At are the points covered when the footprint is anchored at anchor.
+func (f Footprint) At(anchor Point) []Point {
+	points := make([]Point, len(f))
+	for i, offset := range f {
+		points[i] = P(anchor.Row+offset.Row, anchor.Column+offset.Column)
+	}
+	return points
+}
+
+// Exists says whether all the positions covered by the footprint anchored at anchor exist.
+func (f Footprint) Exists(space *Space, anchor Point) bool {
+	for _, pt := range f.At(anchor) {
+		if !space.At(pt).Exists() {
+			return false
+		}
+	}
+	return true
+}
+
+// Fits says whether the footprint anchored at anchor covers only positions that exist and are either free or taken by self.
+func (f Footprint) Fits(space *Space, anchor Point, self SpaceTaker) bool {
+	for _, pt := range f.At(anchor) {
+		pos := space.At(pt)
+		if !pos.Exists() || (pos.Taken() && pos.Taker() != self) {
+			return false
+		}
+	}
+	return true
+}
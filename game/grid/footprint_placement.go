@@ -0,0 +1,238 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// A FootprintPlacement is like a HeadedPlacement for things that take up more than one position, like large units and buildings.
+//
+// It takes all the positions covered by its footprint at once, or none of them.
+// While it moves, it takes the positions covered at both the anchor it is at and the one it is heading to.
+// When any of its positions is freed by force, it leaves all the others too.
+//
+// The placement is its own space taker.
+type FootprintPlacement struct {
+	footprint Footprint
+	owner     any
+
+	space   *Space
+	at      Point
+	heading Point
+	headed  bool
+	taken   map[Point]bool
+	leaving bool
+
+	countdown actions.Countdown
+}
+
+var _ Owned = new(FootprintPlacement)
+
+// NewFootprintPlacement creates a placement with the given footprint, that is not placed anywhere.
+func NewFootprintPlacement(footprint Footprint) *FootprintPlacement {
+	if len(footprint) == 0 {
+		panic("empty footprint")
+	}
+	return &FootprintPlacement{
+		footprint: append(Footprint(nil), footprint...),
+		taken:     map[Point]bool{},
+	}
+}
+
+// Footprint of the placement.
+func (fp *FootprintPlacement) Footprint() Footprint { return fp.footprint }
+
+// LetOnto is part of the SpaceTaker interface.
+func (fp *FootprintPlacement) LetOnto(pos Position) { fp.taken[pos.at] = true }
+
+// ForceOff is part of the SpaceTaker interface.
+func (fp *FootprintPlacement) ForceOff(pos Position) {
+	delete(fp.taken, pos.at)
+	if !fp.leaving {
+		fp.Leave()
+	}
+}
+
+// Owner is part of the Owned interface.
+func (fp *FootprintPlacement) Owner() any { return fp.owner }
+
+// SetOwner says what the placement is a part of.
+func (fp *FootprintPlacement) SetOwner(owner any) { fp.owner = owner }
+
+// Placed says whether the placement takes up some positions.
+func (fp *FootprintPlacement) Placed() bool { return fp.space != nil }
+
+// AtPoint is the anchor point the placement is at.
+//
+// The zero value of the type is returned when the placement is not placed.
+func (fp *FootprintPlacement) AtPoint() Point { return fp.at }
+
+// Headed says whether the placement is moving to another anchor point.
+func (fp *FootprintPlacement) Headed() bool { return fp.headed }
+
+// Heading is the anchor point the placement is moving to.
+//
+// The zero value of the type is returned when the placement is not headed anywhere.
+func (fp *FootprintPlacement) Heading() Point { return fp.heading }
+
+// Progress says how far along the move to the heading the placement is.
+func (fp *FootprintPlacement) Progress() float64 {
+	if !fp.Placed() {
+		return 0
+	}
+	return fp.countdown.Progress()
+}
+
+// Cells are the points of all the positions the placement takes.
+func (fp *FootprintPlacement) Cells() []Point {
+	cells := make([]Point, 0, len(fp.taken))
+	for _, pt := range fp.footprint.At(fp.at) {
+		if fp.taken[pt] {
+			cells = append(cells, pt)
+		}
+	}
+	if fp.headed {
+		for _, pt := range fp.footprint.At(fp.heading) {
+			if fp.taken[pt] && !containsPoint(cells, pt) {
+				cells = append(cells, pt)
+			}
+		}
+	}
+	return cells
+}
+
+// Place puts the placement with its anchor at pos, leaving wherever it was before.
+// It reports false and stays where it was when the footprint does not fit there.
+func (fp *FootprintPlacement) Place(pos Position) bool {
+	if !fp.footprint.Fits(pos.space, pos.at, fp) {
+		return false
+	}
+	fp.Leave()
+	fp.space, fp.at = pos.space, pos.at
+	fp.take(pos.at)
+	fp.countdown = actions.Countdown{}
+	return true
+}
+
+// Leave makes the placement leave all the positions it takes.
+func (fp *FootprintPlacement) Leave() {
+	if fp.leaving {
+		return
+	}
+	fp.leaving = true
+	defer func() { fp.leaving = false }()
+
+	for pt := range fp.taken {
+		fp.space.At(pt).Free()
+	}
+	fp.taken = map[Point]bool{}
+	fp.space = nil
+	fp.at, fp.heading = Point{}, Point{}
+	fp.headed = false
+}
+
+// MoveTo moves the placement's anchor to dst over the time dt.
+//
+// The action takes the positions covered at dst as it starts, and fails when they cannot all be taken.
+// When it completes, the placement leaves the positions that are no longer covered.
+func (fp *FootprintPlacement) MoveTo(dst Position, dt time.Duration) actions.Action {
+	if !fp.Placed() {
+		return actions.NoAction()
+	}
+	return actions.Sequence(
+		&_FootprintHeadAction{fp, dst},
+		fp.countdown.Action(dt),
+		&_FootprintArriveAction{fp})
+}
+
+// FollowPath creates an action that moves the placement's anchor along the path.
+// The path has to start where the placement is.
+func (fp *FootprintPlacement) FollowPath(path Path, stepDt time.Duration) actions.Action {
+	steps := make([]actions.Action, 0, len(path))
+	if len(path) > 0 {
+		steps = append(steps, &_FootprintCheckAtAction{fp, path[0]})
+		for _, dst := range path[1:] {
+			steps = append(steps, fp.MoveTo(dst, stepDt))
+		}
+	}
+	return actions.Sequence(steps...)
+}
+
+// take makes the placement take all the positions covered with the footprint anchored at anchor, or none of them.
+func (fp *FootprintPlacement) take(anchor Point) bool {
+	tx := NewTransaction(fp.space)
+	for _, pt := range fp.footprint.At(anchor) {
+		if tx.Taker(pt) != fp && !tx.Take(pt, fp) {
+			tx.Rollback()
+			return false
+		}
+	}
+	return tx.Commit() == nil
+}
+
+type _FootprintHeadAction struct {
+	placement *FootprintPlacement
+	dst       Position
+}
+
+func (action *_FootprintHeadAction) Run(atMost time.Duration) actions.Status {
+	fp := action.placement
+	if !fp.Placed() || fp.headed || action.dst.space != fp.space {
+		return actions.Interrupted(atMost)
+	}
+	if !fp.take(action.dst.at) {
+		return actions.Interrupted(atMost)
+	}
+	fp.heading, fp.headed = action.dst.at, true
+	return actions.Done(atMost)
+}
+
+type _FootprintArriveAction struct {
+	placement *FootprintPlacement
+}
+
+func (action *_FootprintArriveAction) Run(atMost time.Duration) actions.Status {
+	fp := action.placement
+	if !fp.Placed() || !fp.headed {
+		return actions.Interrupted(atMost)
+	}
+
+	stays := fp.footprint.At(fp.heading)
+	fp.leaving = true
+	for _, pt := range fp.footprint.At(fp.at) {
+		if !containsPoint(stays, pt) {
+			fp.space.At(pt).Free()
+		}
+	}
+	fp.leaving = false
+
+	fp.at, fp.heading, fp.headed = fp.heading, Point{}, false
+	return actions.Done(atMost)
+}
+
+type _FootprintCheckAtAction struct {
+	placement *FootprintPlacement
+	pos       Position
+}
+
+func (action *_FootprintCheckAtAction) Run(atMost time.Duration) actions.Status {
+	fp := action.placement
+	if !fp.Placed() || fp.headed || fp.space != action.pos.space || fp.at != action.pos.at {
+		return actions.Interrupted(atMost)
+	}
+	return actions.Done(atMost)
+}
+
+func containsPoint(points []Point, pt Point) bool {
+	for _, other := range points {
+		if other == pt {
+			return true
+		}
+	}
+	return false
+}
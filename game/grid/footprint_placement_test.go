@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestFootprintPlacementTakesAllItsCells(t *testing.T) {
+	// given
+	space := rectSpace(3, 3)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+
+	// when
+	ok := placement.Place(space.At(grid.P(1, 1)))
+
+	// then
+	assert.That(ok, t.Fatalf, "placing failed")
+	assert.That(placement.AtPoint() == grid.P(1, 1), t.Errorf, "placement is at %v, want %v", placement.AtPoint(), grid.P(1, 1))
+	for _, pt := range grid.RectFootprint(2, 2).At(grid.P(1, 1)) {
+		taker := space.At(pt).Taker()
+		assert.That(taker == placement, t.Errorf, "position %v is taken by %v, want the placement", pt, taker)
+	}
+	assert.That(len(placement.Cells()) == 4, t.Errorf, "got %d cells, want %d", len(placement.Cells()), 4)
+}
+
+func TestFootprintPlacementIsPlacedAtomically(t *testing.T) {
+	kases := map[string]func(space *grid.Space){
+		"CellMissing": func(space *grid.Space) { space.At(grid.P(2, 2)).Destroy() },
+		"CellTaken":   func(space *grid.Space) { space.At(grid.P(2, 2)).Take(grid.DummyTaker()) },
+	}
+
+	for name, prepare := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := rectSpace(3, 3)
+			prepare(space)
+			placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+
+			// when
+			ok := placement.Place(space.At(grid.P(1, 1)))
+
+			// then
+			assert.That(!ok, t.Errorf, "placing should fail")
+			assert.That(!placement.Placed(), t.Errorf, "placement is placed")
+			for _, pt := range []grid.Point{grid.P(1, 1), grid.P(1, 2), grid.P(2, 1)} {
+				assert.That(!space.At(pt).Taken(), t.Errorf, "position %v is taken", pt)
+			}
+		})
+	}
+}
+
+func TestFootprintPlacementThatDoesNotFitStaysWhereItWas(t *testing.T) {
+	// given
+	space := rectSpace(3, 3)
+	space.At(grid.P(2, 2)).Take(grid.DummyTaker())
+
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	// when
+	ok := placement.Place(space.At(grid.P(1, 1)))
+
+	// then
+	assert.That(!ok, t.Errorf, "placing should fail")
+	assert.That(placement.AtPoint() == grid.P(0, 0), t.Errorf, "placement is at %v, want %v", placement.AtPoint(), grid.P(0, 0))
+	assert.That(space.At(grid.P(0, 0)).Taker() == placement, t.Errorf, "the placement does not take %v", grid.P(0, 0))
+}
+
+func TestFootprintPlacementLeaveFreesAllCells(t *testing.T) {
+	// given
+	space := rectSpace(2, 2)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	// when
+	placement.Leave()
+
+	// then
+	assert.That(!placement.Placed(), t.Errorf, "placement is placed")
+	assertAllFree(t, space, grid.RectFootprint(2, 2).At(grid.P(0, 0)))
+}
+
+func TestFootprintPlacementLeavesAllCellsWhenOneIsFreed(t *testing.T) {
+	// given
+	space := rectSpace(2, 2)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	// when
+	space.At(grid.P(1, 1)).Free()
+
+	// then
+	assert.That(!placement.Placed(), t.Errorf, "placement is placed")
+	assertAllFree(t, space, grid.RectFootprint(2, 2).At(grid.P(0, 0)))
+}
+
+func TestFootprintPlacementMoveTakesCellsAtBothEnds(t *testing.T) {
+	// given
+	space := rectSpace(2, 3)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.MoveTo(space.At(grid.P(0, 1)), time.Second)
+
+	// when
+	action.Run(time.Second / 2)
+
+	// then
+	assert.That(placement.Headed(), t.Errorf, "placement is not headed")
+	assert.That(placement.Heading() == grid.P(0, 1), t.Errorf, "placement is heading to %v, want %v", placement.Heading(), grid.P(0, 1))
+	assert.That(len(placement.Cells()) == 6, t.Errorf, "got %d cells, want %d", len(placement.Cells()), 6)
+	for _, pt := range grid.RectFootprint(2, 3).At(grid.P(0, 0)) {
+		assert.That(space.At(pt).Taker() == placement, t.Errorf, "the placement does not take %v", pt)
+	}
+}
+
+func TestFootprintPlacementMoveFreesCellsLeftBehind(t *testing.T) {
+	// given
+	space := rectSpace(2, 3)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.MoveTo(space.At(grid.P(0, 1)), time.Second)
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status.Done(), t.Errorf, "move is not done: %#v", status)
+	assert.That(!placement.Headed(), t.Errorf, "placement is headed")
+	assert.That(placement.AtPoint() == grid.P(0, 1), t.Errorf, "placement is at %v, want %v", placement.AtPoint(), grid.P(0, 1))
+	assertAllFree(t, space, []grid.Point{grid.P(0, 0), grid.P(1, 0)})
+	for _, pt := range grid.RectFootprint(2, 2).At(grid.P(0, 1)) {
+		assert.That(space.At(pt).Taker() == placement, t.Errorf, "the placement does not take %v", pt)
+	}
+}
+
+func TestFootprintPlacementMoveIsInterruptedWhenTheFootprintDoesNotFit(t *testing.T) {
+	// given
+	space := rectSpace(2, 3)
+	space.At(grid.P(1, 2)).Take(grid.DummyTaker())
+
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.MoveTo(space.At(grid.P(0, 1)), time.Second)
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status.Interrupted(), t.Errorf, "move was not interrupted: %#v", status)
+	assert.That(!placement.Headed(), t.Errorf, "placement is headed")
+	assert.That(placement.AtPoint() == grid.P(0, 0), t.Errorf, "placement is at %v, want %v", placement.AtPoint(), grid.P(0, 0))
+	assert.That(!space.At(grid.P(0, 2)).Taken(), t.Errorf, "position %v is taken", grid.P(0, 2))
+}
+
+func TestFootprintPlacementFollowsPath(t *testing.T) {
+	// given
+	space := rectSpace(2, 5)
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	finder := grid.NewFootprintPathFinder(space, placement.Footprint(), placement)
+	path, _ := finder.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 3)))
+
+	action := placement.FollowPath(path, time.Second)
+
+	// when
+	status := action.Run(3 * time.Second)
+
+	// then
+	assert.That(status.Done(), t.Errorf, "following the path is not done: %#v", status)
+	assert.That(placement.AtPoint() == grid.P(0, 3), t.Errorf, "placement is at %v, want %v", placement.AtPoint(), grid.P(0, 3))
+	assertAllFree(t, space, grid.RectFootprint(2, 3).At(grid.P(0, 0)))
+}
+
+func assertAllFree(t *testing.T, space *grid.Space, points []grid.Point) {
+	t.Helper()
+	for _, pt := range points {
+		assert.That(!space.At(pt).Taken(), t.Errorf, "position %v is taken", pt)
+	}
+}
@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestRectFootprintAt(t *testing.T) {
+	// given
+	footprint := grid.RectFootprint(2, 3)
+
+	// when
+	points := footprint.At(grid.P(1, 1))
+
+	// then
+	want := []grid.Point{
+		grid.P(1, 1), grid.P(1, 2), grid.P(1, 3),
+		grid.P(2, 1), grid.P(2, 2), grid.P(2, 3),
+	}
+	assert.That(pointsEqual(points, want), t.Errorf, "got points %v, want %v", points, want)
+}
+
+func TestFootprintFits(t *testing.T) {
+	kases := map[string]struct {
+		Prepare func(space *grid.Space, self grid.SpaceTaker)
+		Fits    bool
+	}{
+		"AllFree": {
+			Prepare: func(*grid.Space, grid.SpaceTaker) {},
+			Fits:    true,
+		},
+		"OneMissing": {
+			Prepare: func(space *grid.Space, _ grid.SpaceTaker) { space.At(grid.P(1, 1)).Destroy() },
+		},
+		"OneTakenByOther": {
+			Prepare: func(space *grid.Space, _ grid.SpaceTaker) { space.At(grid.P(1, 1)).Take(grid.DummyTaker()) },
+		},
+		"OneTakenBySelf": {
+			Prepare: func(space *grid.Space, self grid.SpaceTaker) { space.At(grid.P(1, 1)).Take(self) },
+			Fits:    true,
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := rectSpace(3, 3)
+			self := grid.NewFootprintPlacement(grid.SingleCell())
+			kase.Prepare(space, self)
+
+			footprint := grid.RectFootprint(2, 2)
+
+			// when
+			fits := footprint.Fits(space, grid.P(0, 0), self)
+
+			// then
+			assert.That(fits == kase.Fits, t.Errorf, "got fits %v, want %v", fits, kase.Fits)
+		})
+	}
+}
+
+func TestFootprintPathFinderAvoidsGapsTooNarrowForTheFootprint(t *testing.T) {
+	// given
+	space := rectSpace(5, 6)
+	// a wall in column 2 with a gap one cell wide at row 0 and two cells wide at rows 3-4
+	for _, row := range []int{1, 2} {
+		space.At(grid.P(row, 2)).Destroy()
+	}
+
+	finder := grid.NewFootprintPathFinder(space, grid.RectFootprint(2, 2), nil)
+
+	// when
+	path, ok := finder.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 4)))
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(0, 4))
+	assert.That(finder.IsViable(path), t.Errorf, "path %v is not viable", path)
+	for _, pos := range path {
+		assert.That(pos.AtPoint().Row >= 3 || pos.AtPoint().Column != 1, t.Errorf, "path %v squeezes through the narrow gap", path)
+	}
+}
+
+func TestFootprintPathFinderIsNotBlockedBySelf(t *testing.T) {
+	// given
+	space := rectSpace(2, 4)
+
+	placement := grid.NewFootprintPlacement(grid.RectFootprint(2, 2))
+	placement.Place(space.At(grid.P(0, 0)))
+
+	finder := grid.NewFootprintPathFinder(space, placement.Footprint(), placement)
+
+	// when
+	path, ok := finder.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 2)))
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(0, 2))
+	assert.That(len(path) == 3, t.Errorf, "got path of length %d, want %d", len(path), 3)
+}
+
+func rectSpace(rows, columns int) *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	return space
+}
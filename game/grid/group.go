@@ -0,0 +1,114 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+// Destinations picks up to n distinct free positions, as close to target as possible.
+//
+// Closeness is measured in steps between neighbouring positions that exist.
+// Taken positions can be stepped through, but they are never picked.
+// The closest positions come first, so target comes first whenever it is free.
+func Destinations(target Position, n int) []Position {
+	return destinations(target, n, func(pos Position) bool { return !pos.Taken() })
+}
+
+func destinations(target Position, n int, free func(Position) bool) []Position {
+	if n <= 0 || !target.Exists() {
+		return nil
+	}
+
+	dsts := make([]Position, 0, n)
+	seen := map[Point]bool{target.at: true}
+	queue := []Position{target}
+
+	for len(queue) > 0 && len(dsts) < n {
+		pos := queue[0]
+		queue = queue[1:]
+
+		if free(pos) {
+			dsts = append(dsts, pos)
+		}
+
+		for _, pt := range neighbours(pos.at) {
+			next := pos.space.At(pt)
+			if seen[pt] || !next.Exists() {
+				continue
+			}
+			seen[pt] = true
+			queue = append(queue, next)
+		}
+	}
+
+	return dsts
+}
+
+// AssignDestinations gives each placement a different destination close to target.
+//
+// Placements are matched with destinations greedily, the closest pair of the two first.
+// A placement that is on its way somewhere is treated as if it already arrived.
+// Positions taken by the placements themselves count as free, so one that already stands close to target can stay where it is.
+// Placements that are not placed, or that do not fit around target, are left out of the result.
+func AssignDestinations(placements []*HeadedPlacement, target Position) map[*HeadedPlacement]Position {
+	unassigned := make([]*HeadedPlacement, 0, len(placements))
+	own := map[SpaceTaker]bool{}
+	for _, p := range placements {
+		if p.Placed() {
+			unassigned = append(unassigned, p)
+			own[&p.pos], own[&p.heading] = true, true
+		}
+	}
+
+	free := func(pos Position) bool {
+		taker := pos.Taker()
+		return taker == nil || (comparableTaker(taker) && own[taker])
+	}
+	dsts := destinations(target, len(unassigned), free)
+	assigned := make(map[*HeadedPlacement]Position, len(dsts))
+
+	for len(dsts) > 0 {
+		closestP, closestDst := 0, 0
+		for i, p := range unassigned {
+			for j, dst := range dsts {
+				if steps(startOf(p), dst.at) < steps(startOf(unassigned[closestP]), dsts[closestDst].at) {
+					closestP, closestDst = i, j
+				}
+			}
+		}
+
+		assigned[unassigned[closestP]] = dsts[closestDst]
+		unassigned = append(unassigned[:closestP], unassigned[closestP+1:]...)
+		dsts = append(dsts[:closestDst], dsts[closestDst+1:]...)
+	}
+
+	return assigned
+}
+
+// startOf is where a placement would start moving from.
+func startOf(p *HeadedPlacement) Point {
+	if p.Headed() {
+		return p.Heading()
+	}
+	return p.AtPoint()
+}
+
+func neighbours(pt Point) [4]Point {
+	return [4]Point{
+		P(pt.Row, pt.Column+1),
+		P(pt.Row+1, pt.Column),
+		P(pt.Row, pt.Column-1),
+		P(pt.Row-1, pt.Column),
+	}
+}
+
+// steps is the number of steps between a and b, when nothing is in the way.
+func steps(a, b Point) int {
+	return abs(b.Row-a.Row) + abs(b.Column-a.Column)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestDestinations(t *testing.T) {
+	tests := map[string]struct {
+		Cells  []grid.Point
+		Taken  []grid.Point
+		Target grid.Point
+		N      int
+
+		Want []grid.Point
+	}{
+		"None": {
+			Cells:  []grid.Point{grid.P(0, 0)},
+			Target: grid.P(0, 0),
+		},
+		"TargetDoesNotExist": {
+			Cells:  []grid.Point{grid.P(0, 0)},
+			Target: grid.P(1, 1),
+			N:      1,
+		},
+		"JustTheTarget": {
+			Cells:  []grid.Point{grid.P(0, 0), grid.P(0, 1)},
+			Target: grid.P(0, 0),
+			N:      1,
+
+			Want: []grid.Point{grid.P(0, 0)},
+		},
+		"ClosestFirst": {
+			Cells:  []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(0, 2), grid.P(0, 3)},
+			Target: grid.P(0, 1),
+			N:      4,
+
+			Want: []grid.Point{grid.P(0, 1), grid.P(0, 2), grid.P(0, 0), grid.P(0, 3)},
+		},
+		"TakenTargetIsSkipped": {
+			Cells:  []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(0, 2)},
+			Taken:  []grid.Point{grid.P(0, 0)},
+			Target: grid.P(0, 0),
+			N:      1,
+
+			Want: []grid.Point{grid.P(0, 1)},
+		},
+		"ThroughATakenPosition": {
+			Cells:  []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(0, 2)},
+			Taken:  []grid.Point{grid.P(0, 1)},
+			Target: grid.P(0, 0),
+			N:      2,
+
+			Want: []grid.Point{grid.P(0, 0), grid.P(0, 2)},
+		},
+		"NotEnoughRoom": {
+			Cells:  []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(5, 5)},
+			Target: grid.P(0, 0),
+			N:      3,
+
+			Want: []grid.Point{grid.P(0, 0), grid.P(0, 1)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := grid.NewSpace()
+			for _, pt := range tt.Cells {
+				space.At(pt).Create()
+			}
+			for _, pt := range tt.Taken {
+				space.At(pt).Take(grid.DummyTaker())
+			}
+
+			// when
+			dsts := grid.Destinations(space.At(tt.Target), tt.N)
+
+			// then
+			got := make([]grid.Point, len(dsts))
+			for i, pos := range dsts {
+				got[i] = pos.AtPoint()
+			}
+			assert.That(
+				pointsEqual(got, tt.Want),
+				t.Errorf, "got destinations %v, want %v", got, tt.Want)
+		})
+	}
+}
+
+func TestAssignDestinationsGivesEachPlacementADifferentOne(t *testing.T) {
+	// given
+	space := lineSpace(6)
+
+	placements := []*grid.HeadedPlacement{{}, {}, {}}
+	placements[0].Place(space.At(grid.P(0, 0)))
+	placements[1].Place(space.At(grid.P(0, 1)))
+	placements[2].Place(space.At(grid.P(0, 2)))
+
+	// when
+	assigned := grid.AssignDestinations(placements, space.At(grid.P(0, 4)))
+
+	// then
+	want := map[*grid.HeadedPlacement]grid.Point{
+		placements[0]: grid.P(0, 5),
+		placements[1]: grid.P(0, 4),
+		placements[2]: grid.P(0, 3),
+	}
+	assert.That(len(assigned) == len(want), t.Errorf, "got %d destinations, want %d", len(assigned), len(want))
+	for p, pt := range want {
+		got := assigned[p].AtPoint()
+		assert.That(got == pt, t.Errorf, "placement at %v got destination %v, want %v", p.AtPoint(), got, pt)
+	}
+}
+
+func TestAssignDestinationsLeavesOutPlacementsThatDoNotFit(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	space.At(grid.P(5, 5)).Create()
+
+	var outsider grid.HeadedPlacement
+	outsider.Place(space.At(grid.P(0, 0)))
+
+	placements := []*grid.HeadedPlacement{{}, {}, {}}
+	placements[0].Place(space.At(grid.P(0, 1)))
+	placements[1].Place(space.At(grid.P(5, 5)))
+
+	// when
+	assigned := grid.AssignDestinations(placements, space.At(grid.P(0, 1)))
+
+	// then
+	_, unplacedAssigned := assigned[placements[2]]
+	assert.That(len(assigned) == 1, t.Errorf, "got %d destinations, want %d", len(assigned), 1)
+	assert.That(!unplacedAssigned, t.Errorf, "a placement that is not placed got a destination")
+	assert.That(
+		assigned[placements[0]].AtPoint() == grid.P(0, 1),
+		t.Errorf, "the nearest placement got destination %v, want %v", assigned[placements[0]].AtPoint(), grid.P(0, 1))
+}
+
+func TestAssignDestinationsLetsPlacementsKeepTheirPositions(t *testing.T) {
+	// given
+	space := lineSpace(4)
+
+	var outsider grid.HeadedPlacement
+	outsider.Place(space.At(grid.P(0, 1)))
+
+	placements := []*grid.HeadedPlacement{{}, {}}
+	placements[0].Place(space.At(grid.P(0, 2)))
+	placements[1].Place(space.At(grid.P(0, 0)))
+
+	// when
+	assigned := grid.AssignDestinations(placements, space.At(grid.P(0, 2)))
+
+	// then
+	want := map[*grid.HeadedPlacement]grid.Point{
+		placements[0]: grid.P(0, 2),
+		placements[1]: grid.P(0, 3),
+	}
+	assert.That(len(assigned) == len(want), t.Errorf, "got %d destinations, want %d", len(assigned), len(want))
+	for p, pt := range want {
+		got := assigned[p].AtPoint()
+		assert.That(got == pt, t.Errorf, "placement at %v got destination %v, want %v", p.AtPoint(), got, pt)
+	}
+}
+
+func lineSpace(length int) *grid.Space {
+	space := grid.NewSpace()
+	for column := 0; column < length; column++ {
+		space.At(grid.P(0, column)).Create()
+	}
+	return space
+}
+
+func pointsEqual(a, b []grid.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
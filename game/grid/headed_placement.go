@@ -15,11 +15,29 @@ import (
 //   - nowhere
 //   - at a point
 //   - moving from one point to another
+//
+// The actions that work out where the placement steps next as they go - FollowFlow, Navigate and Pursue - first have it finish a step it is in the middle of.
 type HeadedPlacement struct {
 	pos, heading OnePosTaker
 	countdown    actions.Countdown
 }
 
+// Owner is what the placement is a part of.
+func (hp *HeadedPlacement) Owner() any { return hp.pos.Owner() }
+
+// SetOwner says what the placement is a part of.
+// The owner can then be found through the positions the placement takes.
+func (hp *HeadedPlacement) SetOwner(owner any) {
+	hp.pos.SetOwner(owner)
+	hp.heading.SetOwner(owner)
+}
+
+// Leave makes the placement leave the position it is at, as well as the one it is heading to.
+func (hp *HeadedPlacement) Leave() {
+	hp.pos.Leave()
+	hp.heading.Leave()
+}
+
 // Placed says whether the placement takes up some position.
 // Nothing else is be able to occupy the same position at the same time.
 func (hp *HeadedPlacement) Placed() bool { return hp.pos.Placed() }
@@ -73,6 +91,19 @@ func (hp *HeadedPlacement) Progress() float64 {
 	return hp.countdown.Progress()
 }
 
+// FinishStep creates an action that completes the move the placement is in the middle of.
+// It lets whatever action was moving the placement be replaced without leaving it stuck between positions.
+//
+// When the placement is not headed anywhere the action completes immediately.
+func (hp *HeadedPlacement) FinishStep() actions.Action {
+	if !hp.Headed() {
+		return actions.Sequence()
+	}
+	return actions.Sequence(
+		hp.countdown.Resume(),
+		hp.arriveAction(hp.heading.pos))
+}
+
 func (hp *HeadedPlacement) arriveAction(dst Position) actions.Action {
 	return &_PlacemnetArriveAction{hp, dst}
 }
@@ -444,6 +444,130 @@ func TestFollowingATwoStepPathForTheRequiredTimeLeavesThePlacementAtTheDestinati
 	assert.That(placement.AtPoint() == want, t.Errorf, "placement at %#v, want %#v", placement.AtPoint(), want)
 }
 
+func TestFinishingAStepWhenNotHeadedCompletesImmediately(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(pos)
+
+	action := placement.FinishStep()
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status == actions.Done(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second))
+	assertPlaced(t, &placement, pos.AtPoint())
+}
+
+func TestFinishingAStepTakesTheTimeLeftOfTheMove(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+	dst := space.At(grid.P(2, 4))
+	dst.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(pos)
+	placement.MoveTo(dst, 4*time.Second).Run(3 * time.Second)
+
+	action := placement.FinishStep()
+
+	// when
+	status := action.Run(2 * time.Second)
+
+	// then
+	assert.That(status == actions.Done(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second))
+	assertPlaced(t, &placement, dst.AtPoint())
+	assertNotHeaded(t, &placement)
+}
+
+func TestFinishingAStepInPartLeavesThePlacementHeaded(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+	dst := space.At(grid.P(2, 4))
+	dst.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(pos)
+	placement.MoveTo(dst, 4*time.Second).Run(time.Second)
+
+	action := placement.FinishStep()
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status == actions.Paused(), t.Errorf, "got status %#v, want %#v", status, actions.Paused())
+	assert.That(placement.Progress() == 0.5, t.Errorf, "got progress %f - want %f", placement.Progress(), 0.5)
+	assertHeaded(t, &placement, dst.AtPoint())
+}
+
+func TestHeadedPlacementCanBeFoundThroughItsPosition(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.SetOwner("unit")
+
+	// when
+	placement.Place(pos)
+
+	// then
+	owner := grid.OwnerAt(pos)
+	assert.That(owner == "unit", t.Errorf, "got owner %#v, want %#v", owner, "unit")
+}
+
+func TestHeadedPlacementCanBeFoundThroughThePositionItIsHeadedTo(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+	dst := space.At(grid.P(2, 4))
+	dst.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.SetOwner("unit")
+	placement.Place(pos)
+
+	// when
+	placement.MoveTo(dst, time.Second).Run(0)
+
+	// then
+	owner := grid.OwnerAt(dst)
+	assert.That(owner == "unit", t.Errorf, "got owner %#v, want %#v", owner, "unit")
+}
+
+func TestHeadedPlacementThatLeftIsNowhere(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+	dst := space.At(grid.P(2, 4))
+	dst.Create()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(pos)
+	placement.MoveTo(dst, time.Second).Run(0)
+
+	// when
+	placement.Leave()
+
+	// then
+	assertNotPlaced(t, &placement)
+	assertNotHeaded(t, &placement)
+	assert.That(!pos.Taken(), t.Errorf, "the position left is still taken")
+	assert.That(!dst.Taken(), t.Errorf, "the position headed to is still taken")
+}
+
 // TODO: Better name?
 func assumption(t *testing.T) assert.ErrorFunc {
 	return func(msg string, args ...interface{}) {
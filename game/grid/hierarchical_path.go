@@ -0,0 +1,467 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// DefaultClusterSize is the cluster size that works well for spaces of a few hundred positions across.
+const DefaultClusterSize = 16
+
+// _MaxCachedPaths bounds how many paths a HierarchicalPathFinder remembers.
+const _MaxCachedPaths = 4096
+
+// A HierarchicalPathFinder finds paths like a PathFinder does, but it is meant for big spaces and many searches.
+//
+// It splits the space into square clusters.
+// For each of them it knows the entrances from the neighbouring clusters and how far apart the entrances are.
+// A path is first found between entrances and then filled in with steps between neighbouring positions.
+// The paths found are remembered, so that searching again is cheap.
+//
+// It follows the space's events, so that only the clusters and paths affected by a change are recomputed.
+// The paths it finds are viable, but they can be a bit longer than the shortest ones.
+type HierarchicalPathFinder struct {
+	space *Space
+	size  int
+	sub   *Subscription
+
+	clusters map[Point]*_Cluster
+	dirty    map[Point]bool
+
+	cache       map[_PathKey]_CachedPath
+	byCluster   map[Point]map[_PathKey]bool
+	unreachable map[_PathKey]bool
+}
+
+// A _Cluster knows the entrances in it and where one can get from each of them.
+type _Cluster struct {
+	links map[Point][]_Link
+}
+
+type _Link struct {
+	to   Point
+	cost int
+}
+
+type _PathKey struct{ src, dst Point }
+
+type _CachedPath struct {
+	path     Path
+	clusters []Point
+}
+
+// NewHierarchicalPathFinder creates a path finder for the space, with clusters of clusterSize by clusterSize positions.
+//
+// Close has to be called once the path finder is not needed anymore, so that it stops following the space.
+func NewHierarchicalPathFinder(space *Space, clusterSize int) *HierarchicalPathFinder {
+	if clusterSize < 2 {
+		panic(fmt.Sprintf("cluster size %d is too small", clusterSize))
+	}
+	pf := &HierarchicalPathFinder{
+		space:       space,
+		size:        clusterSize,
+		clusters:    map[Point]*_Cluster{},
+		dirty:       map[Point]bool{},
+		cache:       map[_PathKey]_CachedPath{},
+		byCluster:   map[Point]map[_PathKey]bool{},
+		unreachable: map[_PathKey]bool{},
+	}
+
+	if space.Size() > 0 {
+		min, max := pf.clusterOf(space.Min()), pf.clusterOf(space.Max())
+		var key Point
+		for key.Row = min.Row; key.Row <= max.Row; key.Row++ {
+			for key.Column = min.Column; key.Column <= max.Column; key.Column++ {
+				pf.dirty[key] = true
+			}
+		}
+	}
+
+	pf.sub = space.Subscribe(pf.update)
+	return pf
+}
+
+// Close stops the path finder from following the changes to the space.
+// It should not be used afterwards.
+func (pf *HierarchicalPathFinder) Close() { pf.sub.Cancel() }
+
+// FindPath searches for a path from src to dst.
+//
+// Like PathFinder.FindPath, when a path cannot be found it reports so and returns a path containing exactly src.
+// Otherwise it returns a viable path.
+func (pf *HierarchicalPathFinder) FindPath(src, dst Position) (path Path, exists bool) {
+	if src.space != pf.space || dst.space != pf.space {
+		return Path{src}, false
+	}
+	if src == dst {
+		return Path{src}, true
+	}
+	if !pf.passable(dst.at) {
+		return Path{src}, false
+	}
+
+	key := _PathKey{src.at, dst.at}
+	if cached, ok := pf.cache[key]; ok {
+		return append(Path(nil), cached.path...), true
+	}
+	if pf.unreachable[key] {
+		return Path{src}, false
+	}
+
+	pf.refresh()
+	points, ok := pf.search(src.at, dst.at)
+	if !ok {
+		pf.remember(key, nil)
+		return Path{src}, false
+	}
+
+	path = make(Path, len(points))
+	for i, pt := range points {
+		path[i] = pf.space.At(pt)
+	}
+	pf.remember(key, path)
+	return append(Path(nil), path...), true
+}
+
+// Through creates a path search that also goes through the taken positions passable allows, like PathFinder.Through does.
+//
+// What passable allows can differ from search to search, so it cannot be remembered like the rest.
+// A path around the taken positions is looked for first, and only when there is none the search goes through them, the slower way a PathFinder does.
+func (pf *HierarchicalPathFinder) Through(passable func(Position) bool) func(src, dst Position) (Path, bool) {
+	through := NewPathFinder(pf.space).Through(passable)
+	return func(src, dst Position) (Path, bool) {
+		if path, ok := pf.FindPath(src, dst); ok {
+			return path, true
+		}
+		if src.space != pf.space || dst.space != pf.space {
+			return Path{src}, false
+		}
+		return through.FindPath(src, dst)
+	}
+}
+
+func (pf *HierarchicalPathFinder) update(ev Event) {
+	key := pf.clusterOf(ev.At)
+	pf.dirty[key] = true
+
+	for pathKey := range pf.byCluster[key] {
+		pf.forget(pathKey)
+	}
+	if ev.Kind == PositionCreated || ev.Kind == PositionFreed {
+		pf.unreachable = map[_PathKey]bool{}
+	}
+}
+
+func (pf *HierarchicalPathFinder) remember(key _PathKey, path Path) {
+	if len(pf.cache)+len(pf.unreachable) >= _MaxCachedPaths {
+		pf.cache = map[_PathKey]_CachedPath{}
+		pf.byCluster = map[Point]map[_PathKey]bool{}
+		pf.unreachable = map[_PathKey]bool{}
+	}
+
+	if path == nil {
+		pf.unreachable[key] = true
+		return
+	}
+
+	cached := _CachedPath{path: path}
+	for _, pos := range path {
+		cluster := pf.clusterOf(pos.at)
+		if pf.byCluster[cluster] == nil {
+			pf.byCluster[cluster] = map[_PathKey]bool{}
+		}
+		if !pf.byCluster[cluster][key] {
+			pf.byCluster[cluster][key] = true
+			cached.clusters = append(cached.clusters, cluster)
+		}
+	}
+	pf.cache[key] = cached
+}
+
+func (pf *HierarchicalPathFinder) forget(key _PathKey) {
+	for _, cluster := range pf.cache[key].clusters {
+		delete(pf.byCluster[cluster], key)
+		if len(pf.byCluster[cluster]) == 0 {
+			delete(pf.byCluster, cluster)
+		}
+	}
+	delete(pf.cache, key)
+}
+
+// refresh recomputes the clusters that changed, together with their neighbours, which share entrances with them.
+func (pf *HierarchicalPathFinder) refresh() {
+	if len(pf.dirty) == 0 {
+		return
+	}
+	affected := map[Point]bool{}
+	for key := range pf.dirty {
+		affected[key] = true
+		for _, next := range neighbours(key) {
+			affected[next] = true
+		}
+	}
+	pf.dirty = map[Point]bool{}
+
+	for key := range affected {
+		pf.rebuild(key)
+	}
+}
+
+// rebuild finds the entrances of the cluster and links them with each other and with the neighbouring clusters.
+func (pf *HierarchicalPathFinder) rebuild(key Point) {
+	entrances := map[Point][]Point{}
+	for _, next := range neighbours(key) {
+		for _, pair := range pf.entrances(key, next) {
+			entrances[pair[0]] = append(entrances[pair[0]], pair[1])
+		}
+	}
+	if len(entrances) == 0 {
+		delete(pf.clusters, key)
+		return
+	}
+
+	cluster := &_Cluster{links: map[Point][]_Link{}}
+	for from, across := range entrances {
+		dist, _ := pf.spread(key, from)
+		for to := range entrances {
+			if d := dist[pf.local(key, to)]; to != from && d >= 0 {
+				cluster.links[from] = append(cluster.links[from], _Link{to, d})
+			}
+		}
+		for _, to := range across {
+			cluster.links[from] = append(cluster.links[from], _Link{to, 1})
+		}
+	}
+	pf.clusters[key] = cluster
+}
+
+// entrances are the pairs of positions one can step between to get from the cluster to its neighbour.
+//
+// Each stretch of the border where both sides are passable gets one entrance, in its middle.
+// It is the same no matter which of the two clusters it is found for, except for the order within the pairs.
+func (pf *HierarchicalPathFinder) entrances(key, next Point) [][2]Point {
+	origin := P(key.Row*pf.size, key.Column*pf.size)
+
+	var inside, step Point
+	switch {
+	case next.Row > key.Row:
+		inside, step = P(origin.Row+pf.size-1, origin.Column), P(0, 1)
+	case next.Row < key.Row:
+		inside, step = origin, P(0, 1)
+	case next.Column > key.Column:
+		inside, step = P(origin.Row, origin.Column+pf.size-1), P(1, 0)
+	default:
+		inside, step = origin, P(1, 0)
+	}
+	across := P(next.Row-key.Row, next.Column-key.Column)
+
+	var pairs [][2]Point
+	run := 0
+	closeRun := func(end int) {
+		if run == 0 {
+			return
+		}
+		mid := end - run + run/2
+		at := P(inside.Row+step.Row*mid, inside.Column+step.Column*mid)
+		pairs = append(pairs, [2]Point{at, P(at.Row+across.Row, at.Column+across.Column)})
+		run = 0
+	}
+	for i := 0; i < pf.size; i++ {
+		at := P(inside.Row+step.Row*i, inside.Column+step.Column*i)
+		if pf.passable(at) && pf.passable(P(at.Row+across.Row, at.Column+across.Column)) {
+			run++
+		} else {
+			closeRun(i)
+		}
+	}
+	closeRun(pf.size)
+	return pairs
+}
+
+// search finds a path between the points, through the entrances of the clusters on the way.
+func (pf *HierarchicalPathFinder) search(src, dst Point) ([]Point, bool) {
+	srcKey, dstKey := pf.clusterOf(src), pf.clusterOf(dst)
+	srcDist, _ := pf.spread(srcKey, src)
+	dstDist, _ := pf.spread(dstKey, dst)
+
+	// The links from src to the entrances of its cluster and to dst, when it is in the same cluster.
+	var srcLinks []_Link
+	if cluster := pf.clusters[srcKey]; cluster != nil {
+		for entrance := range cluster.links {
+			if d := srcDist[pf.local(srcKey, entrance)]; d >= 0 && entrance != src {
+				srcLinks = append(srcLinks, _Link{entrance, d})
+			}
+		}
+	}
+	if srcKey == dstKey {
+		if d := srcDist[pf.local(srcKey, dst)]; d >= 0 {
+			srcLinks = append(srcLinks, _Link{dst, d})
+		}
+	}
+
+	linksOf := func(pt Point, links []_Link) []_Link {
+		links = links[:0]
+		if pt == src {
+			links = append(links, srcLinks...)
+		}
+		if cluster := pf.clusters[pf.clusterOf(pt)]; cluster != nil {
+			links = append(links, cluster.links[pt]...)
+		}
+		if pf.clusterOf(pt) == dstKey && pt != dst {
+			if d := dstDist[pf.local(dstKey, pt)]; d >= 0 {
+				links = append(links, _Link{dst, d})
+			}
+		}
+		return links
+	}
+
+	waypoints, ok := shortestPath(src, dst, linksOf)
+	if !ok {
+		return nil, false
+	}
+	return pf.refine(waypoints), true
+}
+
+// refine fills in the steps between consecutive waypoints.
+func (pf *HierarchicalPathFinder) refine(waypoints []Point) []Point {
+	path := []Point{waypoints[0]}
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1], waypoints[i]
+		key := pf.clusterOf(from)
+		if pf.clusterOf(to) != key {
+			path = append(path, to)
+			continue
+		}
+
+		_, parents := pf.spread(key, from)
+		start := len(path)
+		for at := to; at != from; at = pf.global(key, parents[pf.local(key, at)]) {
+			path = append(path, at)
+		}
+		reverse(path[start:])
+	}
+	return path
+}
+
+// spread finds how far it is from the point to each position in the cluster, without leaving it.
+// Distances are -1 for the positions that cannot be reached.
+// Parents are the local indexes of the previous steps on the way.
+func (pf *HierarchicalPathFinder) spread(key, from Point) (dist, parents []int) {
+	dist = make([]int, pf.size*pf.size)
+	parents = make([]int, pf.size*pf.size)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	start := pf.local(key, from)
+	dist[start] = 0
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		at := pf.global(key, current)
+		for _, next := range neighbours(at) {
+			if pf.clusterOf(next) != key || !pf.passable(next) {
+				continue
+			}
+			i := pf.local(key, next)
+			if dist[i] >= 0 {
+				continue
+			}
+			dist[i], parents[i] = dist[current]+1, current
+			queue = append(queue, i)
+		}
+	}
+	return dist, parents
+}
+
+func (pf *HierarchicalPathFinder) passable(pt Point) bool {
+	taker, exists := pf.space.lookup(pt)
+	return exists && taker == nil
+}
+
+func (pf *HierarchicalPathFinder) clusterOf(pt Point) Point {
+	return P(floorDiv(pt.Row, pf.size), floorDiv(pt.Column, pf.size))
+}
+
+func (pf *HierarchicalPathFinder) local(key, pt Point) int {
+	return (pt.Row-key.Row*pf.size)*pf.size + pt.Column - key.Column*pf.size
+}
+
+func (pf *HierarchicalPathFinder) global(key Point, i int) Point {
+	return P(key.Row*pf.size+i/pf.size, key.Column*pf.size+i%pf.size)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func reverse(pts []Point) {
+	for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+		pts[i], pts[j] = pts[j], pts[i]
+	}
+}
+
+// shortestPath is A* over the graph described by the links, with the taxicab distance as the heuristic.
+// The links function is given a buffer it can append to.
+func shortestPath(src, dst Point, links func(Point, []_Link) []_Link) ([]Point, bool) {
+	costs := map[Point]int{src: 0}
+	parents := map[Point]Point{}
+	closed := map[Point]bool{}
+
+	open := &_OpenPoints{{at: src, estimate: steps(src, dst)}}
+	var buf []_Link
+	for open.Len() > 0 {
+		current := heap.Pop(open).(_OpenPoint).at
+		if current == dst {
+			path := []Point{dst}
+			for at := dst; at != src; {
+				at = parents[at]
+				path = append(path, at)
+			}
+			reverse(path)
+			return path, true
+		}
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+
+		buf = links(current, buf)
+		for _, link := range buf {
+			cost := costs[current] + link.cost
+			if known, ok := costs[link.to]; ok && known <= cost {
+				continue
+			}
+			costs[link.to], parents[link.to] = cost, current
+			heap.Push(open, _OpenPoint{at: link.to, estimate: cost + steps(link.to, dst)})
+		}
+	}
+	return nil, false
+}
+
+type _OpenPoint struct {
+	at       Point
+	estimate int
+}
+
+type _OpenPoints []_OpenPoint
+
+func (ps _OpenPoints) Len() int           { return len(ps) }
+func (ps _OpenPoints) Less(i, j int) bool { return ps[i].estimate < ps[j].estimate }
+func (ps _OpenPoints) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
+func (ps *_OpenPoints) Push(x any)        { *ps = append(*ps, x.(_OpenPoint)) }
+func (ps *_OpenPoints) Pop() any {
+	old := *ps
+	last := old[len(old)-1]
+	*ps = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,227 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestHierarchicalPathFinderAgreesWithPathFinder(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			// given
+			rng := rand.New(rand.NewSource(seed))
+			space := randomSpace(rng, 30, 0.3)
+			exact := grid.NewPathFinder(space)
+			hierarchical := grid.NewHierarchicalPathFinder(space, 5)
+			defer hierarchical.Close()
+
+			for i := 0; i < 10; i++ {
+				src := space.At(grid.P(rng.Intn(30), rng.Intn(30)))
+				dst := space.At(grid.P(rng.Intn(30), rng.Intn(30)))
+				if !src.Exists() {
+					continue
+				}
+
+				// when
+				want, wantOK := exact.FindPath(src, dst)
+				got, gotOK := hierarchical.FindPath(src, dst)
+
+				// then
+				assert.That(gotOK == wantOK, t.Fatalf, "path from %v to %v found: %v, want %v", src.AtPoint(), dst.AtPoint(), gotOK, wantOK)
+				if !gotOK {
+					assert.That(len(got) == 1 && got[0] == src, t.Errorf, "got path %v, want one with just the source", got)
+					continue
+				}
+				assertPathFromTo(t.Errorf, got, src.AtPoint(), dst.AtPoint())
+				assert.That(exact.IsViable(got), t.Errorf, "path %v is not viable", got)
+				assert.That(len(got) >= len(want), t.Errorf, "got path of length %d, shorter than the shortest %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestHierarchicalPathFinderFindsPathThroughSeveralClusters(t *testing.T) {
+	// given
+	space := rectSpace(4, 20)
+	// walls with single gaps, alternating sides
+	for row := 0; row < 3; row++ {
+		space.At(grid.P(row, 5)).Destroy()
+		space.At(grid.P(row+1, 12)).Destroy()
+	}
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	// when
+	path, ok := finder.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 19)))
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(0, 19))
+	assert.That(grid.NewPathFinder(space).IsViable(path), t.Errorf, "path %v is not viable", path)
+}
+
+func TestHierarchicalPathFinderAvoidsPositionsDestroyedAfterItWasCreated(t *testing.T) {
+	// given
+	space := rectSpace(3, 12)
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	src, dst := space.At(grid.P(1, 0)), space.At(grid.P(1, 11))
+	finder.FindPath(src, dst)
+
+	// when
+	space.At(grid.P(1, 5)).Destroy()
+	space.At(grid.P(0, 5)).Destroy()
+	path, ok := finder.FindPath(src, dst)
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assert.That(grid.NewPathFinder(space).IsViable(path), t.Errorf, "path %v is not viable", path)
+}
+
+func TestHierarchicalPathFinderAvoidsPositionsTakenAfterItWasCreated(t *testing.T) {
+	// given
+	space := rectSpace(1, 12)
+	space.At(grid.P(1, 11)).Create()
+	space.At(grid.P(2, 11)).Create()
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	src, dst := space.At(grid.P(0, 0)), space.At(grid.P(2, 11))
+	finder.FindPath(src, dst)
+
+	// when
+	space.At(grid.P(0, 6)).Take(grid.DummyTaker())
+	_, ok := finder.FindPath(src, dst)
+
+	// then
+	assert.That(!ok, t.Errorf, "a path was found through a taken position")
+}
+
+func TestHierarchicalPathFinderFindsPathOnceItIsConnected(t *testing.T) {
+	// given
+	space := rectSpace(1, 12)
+	space.At(grid.P(0, 6)).Destroy()
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	src, dst := space.At(grid.P(0, 0)), space.At(grid.P(0, 11))
+	_, before := finder.FindPath(src, dst)
+
+	// when
+	space.At(grid.P(0, 6)).Create()
+	path, ok := finder.FindPath(src, dst)
+
+	// then
+	assert.That(!before, t.Errorf, "a path was found before the space was connected")
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(0, 11))
+}
+
+func TestHierarchicalPathFinderDoesNotFindPathToTakenPosition(t *testing.T) {
+	// given
+	space := rectSpace(2, 8)
+	space.At(grid.P(1, 7)).Take(grid.DummyTaker())
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	src := space.At(grid.P(0, 0))
+
+	// when
+	path, ok := finder.FindPath(src, space.At(grid.P(1, 7)))
+
+	// then
+	assert.That(!ok, t.Errorf, "a path was found")
+	assert.That(len(path) == 1 && path[0] == src, t.Errorf, "got path %v, want one with just the source", path)
+}
+
+func TestHierarchicalPathFinderStartsFromTakenPosition(t *testing.T) {
+	// given
+	space := rectSpace(2, 8)
+	src := space.At(grid.P(0, 0))
+	src.Take(grid.DummyTaker())
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	// when
+	path, ok := finder.FindPath(src, space.At(grid.P(1, 7)))
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(1, 7))
+	assert.That(len(path) == 9, t.Errorf, "got path of length %d, want %d", len(path), 9)
+}
+
+func TestHierarchicalPathFinderGoesThroughPassablePositions(t *testing.T) {
+	tests := map[string]struct {
+		Passable bool
+		Found    bool
+	}{
+		"Passable":    {Passable: true, Found: true},
+		"NotPassable": {Passable: false, Found: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := rectSpace(1, 12)
+			blocker := &RecordingSpaceTaker{}
+			space.At(grid.P(0, 6)).Take(blocker)
+			finder := grid.NewHierarchicalPathFinder(space, 4)
+			defer finder.Close()
+
+			findPath := finder.Through(func(pos grid.Position) bool { return tt.Passable && pos.Taker() == blocker })
+
+			// when
+			path, ok := findPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 11)))
+
+			// then
+			assert.That(ok == tt.Found, t.Fatalf, "path found: %v, want %v", ok, tt.Found)
+			if tt.Found {
+				assertPathFromTo(t.Errorf, path, grid.P(0, 0), grid.P(0, 11))
+			}
+		})
+	}
+}
+
+func TestHierarchicalPathFinderWorksWithNegativeCoordinates(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	for row := -7; row <= 2; row++ {
+		for column := -9; column <= 3; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	finder := grid.NewHierarchicalPathFinder(space, 4)
+	defer finder.Close()
+
+	// when
+	path, ok := finder.FindPath(space.At(grid.P(-7, -9)), space.At(grid.P(2, 3)))
+
+	// then
+	assert.That(ok, t.Fatalf, "no path found")
+	assertPathFromTo(t.Errorf, path, grid.P(-7, -9), grid.P(2, 3))
+	assert.That(len(path) == 22, t.Errorf, "got path of length %d, want %d", len(path), 22)
+}
+
+// randomSpace creates a side by side space, with a fraction of the positions left out at random.
+func randomSpace(rng *rand.Rand, side int, holes float64) *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < side; row++ {
+		for column := 0; column < side; column++ {
+			if rng.Float64() >= holes {
+				space.At(grid.P(row, column)).Create()
+			}
+		}
+	}
+	return space
+}
@@ -0,0 +1,255 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// Navigation says how a placement gets to where it is going when other things are in the way.
+type Navigation struct {
+	// FindPath is used to plan the way, and to look for another one when the planned one is blocked.
+	// With a crowd, it should go through the positions the crowd says are Navigating, so that the placements can trade places.
+	FindPath func(src, dst Position) (Path, bool)
+	// StepTime is how long it takes to move to the next position.
+	StepTime time.Duration
+	// Patience is how long to wait for a blocked step to clear before looking for another way.
+	Patience time.Duration
+	// MaxRepaths is how many times looking for another way can fail before giving up.
+	// With zero the placement never gives up.
+	MaxRepaths int
+	// Crowd lets placements navigating with it trade places when they block each other.
+	// Without one, they only wait and look for other ways.
+	Crowd *Crowd
+}
+
+// A Crowd knows which placements navigate with it and which positions they wait for, so that two waiting for each other can trade places.
+type Crowd struct {
+	members map[SpaceTaker]*_NavigateAction
+	waiting map[Point]*_NavigateAction
+}
+
+// NewCrowd creates a crowd with no placements in it.
+func NewCrowd() *Crowd {
+	return &Crowd{
+		members: map[SpaceTaker]*_NavigateAction{},
+		waiting: map[Point]*_NavigateAction{},
+	}
+}
+
+// Navigating says whether the position is taken by a placement navigating with the crowd.
+// It is meant for PathFinder.Through, so that placements plan their way through the ones that can trade places with them or get out of the way.
+func (crowd *Crowd) Navigating(pos Position) bool {
+	taker := pos.Taker()
+	if !comparableTaker(taker) {
+		return false
+	}
+	_, ok := crowd.members[taker]
+	return ok
+}
+
+func (crowd *Crowd) join(action *_NavigateAction) {
+	hp := action.placement
+	crowd.members[&hp.pos] = action
+	crowd.members[&hp.heading] = action
+}
+
+func (crowd *Crowd) leave(action *_NavigateAction) {
+	hp := action.placement
+	if crowd.members[&hp.pos] == action {
+		delete(crowd.members, &hp.pos)
+		delete(crowd.members, &hp.heading)
+	}
+}
+
+// Navigate creates an action that moves the placement to dst one step at a time.
+//
+// When the next step is taken, the placement waits for it to clear.
+// If it does not clear in time, the placement looks for another way, keeping the old one when there is none.
+// A placement that waits for the position of another one that waits for its position trades places with it, when both use the same crowd.
+//
+// The action is done once the placement gets to dst.
+// It is interrupted when the placement is not placed, or when looking for another way fails more than nav.MaxRepaths times.
+// The placement is a part of the crowd from when the action is created until it finishes or gets cancelled.
+// That way, placements sent off together plan their ways through each other from the start.
+func (hp *HeadedPlacement) Navigate(dst Position, nav Navigation) actions.Action {
+	action := &_NavigateAction{placement: hp, dst: dst, nav: nav, stepper: _Stepper{placement: hp}}
+	if nav.Crowd != nil {
+		nav.Crowd.join(action)
+	}
+	return action
+}
+
+type _NavigateAction struct {
+	placement *HeadedPlacement
+	dst       Position
+	nav       Navigation
+
+	stepper    _Stepper
+	path       Path
+	blockedFor time.Duration
+	repaths    int
+	waitingAt  *Point
+	wants      Point
+	cancelled  bool
+}
+
+var _ actions.Cancellable = new(_NavigateAction)
+
+func (action *_NavigateAction) Run(atMost time.Duration) actions.Status {
+	if action.cancelled {
+		return actions.Interrupted(atMost)
+	}
+
+	hp := action.placement
+	repathed := false
+	for {
+		// A step that got blocked leaves the placement where it was - the loop will find the position taken and wait.
+		ready, timeLeft, _ := action.stepper.advance(atMost)
+		if !ready {
+			return actions.Paused()
+		}
+		atMost = timeLeft
+
+		if !hp.Placed() {
+			return action.finish(actions.Interrupted(atMost))
+		}
+		at := hp.pos.pos
+		if at == action.dst {
+			return action.finish(actions.Done(atMost))
+		}
+
+		next, ok := action.nextStep(at)
+		switch {
+		case ok && !next.Taken():
+			action.stopWaiting()
+			action.blockedFor = 0
+			action.stepper.moveTo(next, action.nav.StepTime)
+			continue
+
+		case ok && action.trySwap(at, next):
+			action.blockedFor = 0
+			action.stepper.wait(action.nav.StepTime)
+			continue
+
+		case ok:
+			action.waitFor(at, next)
+		}
+
+		action.blockedFor += atMost
+		if action.blockedFor < action.nav.Patience || repathed {
+			return actions.Paused()
+		}
+		action.blockedFor, atMost = 0, 0
+		repathed = true
+		if action.repath(at) {
+			continue
+		}
+		action.repaths++
+		if action.nav.MaxRepaths > 0 && action.repaths > action.nav.MaxRepaths {
+			return action.finish(actions.Interrupted(0))
+		}
+		return actions.Paused()
+	}
+}
+
+// Cancel takes the placement out of the crowd, so that others do not count on it anymore.
+// A step in progress is not finished.
+func (action *_NavigateAction) Cancel() {
+	action.cancelled = true
+	action.finish(actions.Interrupted(0))
+}
+
+func (action *_NavigateAction) finish(status actions.Status) actions.Status {
+	action.stopWaiting()
+	if crowd := action.nav.Crowd; crowd != nil {
+		crowd.leave(action)
+	}
+	return status
+}
+
+// nextStep is the position after at on the planned path.
+// It plans the path when there is none yet, or when the placement is not on it anymore.
+func (action *_NavigateAction) nextStep(at Position) (Position, bool) {
+	for i, pos := range action.path {
+		if pos == at && i+1 < len(action.path) {
+			action.path = action.path[i:]
+			return action.path[1], true
+		}
+	}
+	if !action.repath(at) {
+		action.path = nil
+		return at, false
+	}
+	return action.path[1], true
+}
+
+// repath looks for a way from at to the destination.
+// The planned path stays as it was when there is none.
+func (action *_NavigateAction) repath(at Position) bool {
+	path, ok := action.nav.FindPath(at, action.dst)
+	if !ok || len(path) < 2 {
+		return false
+	}
+	action.path = path
+	return true
+}
+
+// trySwap makes the placement trade places with another one that waits to step onto at from next.
+//
+// The other one has to still be navigating with the crowd - one whose action got cancelled, or that got moved by something else since, is left alone.
+func (action *_NavigateAction) trySwap(at, next Position) bool {
+	crowd := action.nav.Crowd
+	if crowd == nil {
+		return false
+	}
+	other, ok := crowd.waiting[next.at]
+	if !ok || other == action || other.wants != at.at {
+		return false
+	}
+	them := other.placement
+	if crowd.members[&them.pos] != other || them.Headed() || them.pos.pos != next {
+		delete(crowd.waiting, next.at)
+		return false
+	}
+
+	me := action.placement
+	tx := NewTransaction(at.space)
+	tx.Free(at.at)
+	tx.Free(next.at)
+	tx.Take(at.at, &them.pos)
+	tx.Take(next.at, &me.pos)
+	if tx.Commit() != nil {
+		return false
+	}
+
+	action.stopWaiting()
+	other.stopWaiting()
+	return true
+}
+
+func (action *_NavigateAction) waitFor(at, next Position) {
+	crowd := action.nav.Crowd
+	if crowd == nil {
+		return
+	}
+	action.stopWaiting()
+	crowd.waiting[at.at] = action
+	waitingAt := at.at
+	action.waitingAt, action.wants = &waitingAt, next.at
+}
+
+func (action *_NavigateAction) stopWaiting() {
+	crowd := action.nav.Crowd
+	if crowd == nil || action.waitingAt == nil {
+		return
+	}
+	if crowd.waiting[*action.waitingAt] == action {
+		delete(crowd.waiting, *action.waitingAt)
+	}
+	action.waitingAt = nil
+}
@@ -0,0 +1,342 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestNavigatingPlacementGetsToItsDestination(t *testing.T) {
+	// given
+	space := rectSpace(3, 5)
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.Navigate(space.At(grid.P(2, 4)), navigation(space, nil))
+
+	// when
+	status := action.Run(6*time.Second + time.Second/2)
+
+	// then
+	assert.That(status == actions.Done(time.Second/2), t.Errorf, "got status %#v, want %#v", status, actions.Done(time.Second/2))
+	assertPlaced(t, &placement, grid.P(2, 4))
+}
+
+func TestNavigatingPlacementWaitsForBlockerToMoveAway(t *testing.T) {
+	// given
+	space := lineSpace(3)
+	blocker := grid.HeadedPlacement{}
+	blocker.Place(space.At(grid.P(0, 1)))
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.Navigate(space.At(grid.P(0, 2)), navigation(space, nil))
+	waiting := action.Run(time.Second)
+
+	// when
+	blocker.Leave()
+	status := action.Run(2 * time.Second)
+
+	// then
+	assert.That(waiting == actions.Paused(), t.Errorf, "got status %#v while blocked, want %#v", waiting, actions.Paused())
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 2))
+}
+
+func TestNavigatingPlacementGoesAroundBlockerThatDoesNotMove(t *testing.T) {
+	// given
+	space := rectSpace(2, 5)
+	blocker := grid.HeadedPlacement{}
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.Navigate(space.At(grid.P(0, 4)), navigation(space, nil))
+	action.Run(0)
+	// the blocker steps in once the path is planned
+	blocker.Place(space.At(grid.P(0, 2)))
+
+	// when
+	statuses := runFor(action, 10*time.Second, time.Second)
+
+	// then
+	assert.That(statuses[len(statuses)-1].Done(), t.Errorf, "got statuses %v, want the last one done", statuses)
+	assertPlaced(t, &placement, grid.P(0, 4))
+	assertPlaced(t, &blocker, grid.P(0, 2))
+}
+
+func TestNavigatingPlacementsSwapInCorridor(t *testing.T) {
+	// given
+	space := lineSpace(6)
+	crowd := grid.NewCrowd()
+
+	left, right := grid.HeadedPlacement{}, grid.HeadedPlacement{}
+	left.Place(space.At(grid.P(0, 0)))
+	right.Place(space.At(grid.P(0, 5)))
+
+	toRight := left.Navigate(space.At(grid.P(0, 3)), navigation(space, crowd))
+	toLeft := right.Navigate(space.At(grid.P(0, 2)), navigation(space, crowd))
+
+	// when
+	var leftDone, rightDone bool
+	for tick := 0; tick < 40 && !(leftDone && rightDone); tick++ {
+		leftDone = leftDone || toRight.Run(time.Second/2).Done()
+		rightDone = rightDone || toLeft.Run(time.Second/2).Done()
+	}
+
+	// then
+	assert.That(leftDone && rightDone, t.Errorf, "both should be done, left: %v, right: %v", leftDone, rightDone)
+	assertPlaced(t, &left, grid.P(0, 3))
+	assertPlaced(t, &right, grid.P(0, 2))
+}
+
+func TestNavigatingPlacementsPassEachOtherInCorridor(t *testing.T) {
+	// given
+	space := lineSpace(8)
+	crowd := grid.NewCrowd()
+
+	left, right := grid.HeadedPlacement{}, grid.HeadedPlacement{}
+	left.Place(space.At(grid.P(0, 1)))
+	right.Place(space.At(grid.P(0, 6)))
+
+	toRight := left.Navigate(space.At(grid.P(0, 7)), navigation(space, crowd))
+	toLeft := right.Navigate(space.At(grid.P(0, 0)), navigation(space, crowd))
+
+	// when
+	var leftStatus, rightStatus actions.Status
+	for tick := 0; tick < 60 && !(leftStatus.Done() && rightStatus.Done()); tick++ {
+		if !leftStatus.Done() {
+			leftStatus = toRight.Run(time.Second / 2)
+		}
+		if !rightStatus.Done() {
+			rightStatus = toLeft.Run(time.Second / 2)
+		}
+	}
+
+	// then
+	assert.That(leftStatus.Done(), t.Errorf, "got left status %#v, want it done", leftStatus)
+	assert.That(rightStatus.Done(), t.Errorf, "got right status %#v, want it done", rightStatus)
+	assertPlaced(t, &left, grid.P(0, 7))
+	assertPlaced(t, &right, grid.P(0, 0))
+}
+
+func TestNavigatingPlacementDoesNotSwapWithOneThatStoppedNavigating(t *testing.T) {
+	// given
+	space := lineSpace(4)
+	crowd := grid.NewCrowd()
+	nav := navigation(space, crowd)
+	// plans go through anything, so that the mover counts on trading places
+	nav.FindPath = grid.NewPathFinder(space).Through(func(grid.Position) bool { return true }).FindPath
+
+	mover, stopped := grid.HeadedPlacement{}, grid.HeadedPlacement{}
+	mover.Place(space.At(grid.P(0, 1)))
+	stopped.Place(space.At(grid.P(0, 2)))
+
+	var queue actions.Queue
+	queue.Push(stopped.Navigate(space.At(grid.P(0, 0)), nav))
+	queue.Run(time.Second / 2)
+	// the other one gets told to do something else while it waits to pass
+	queue.Replace(actions.Wait(time.Hour))
+
+	action := mover.Navigate(space.At(grid.P(0, 3)), nav)
+
+	// when
+	statuses := runFor(action, 10*time.Second, time.Second/2)
+
+	// then
+	last := statuses[len(statuses)-1]
+	assert.That(!last.Done(), t.Errorf, "got status %#v, want it not done", last)
+	assertPlaced(t, &mover, grid.P(0, 1))
+	assertPlaced(t, &stopped, grid.P(0, 2))
+}
+
+func TestGroupMovesOntoItsOwnPositions(t *testing.T) {
+	// given
+	space := lineSpace(3)
+	crowd := grid.NewCrowd()
+	nav := navigation(space, crowd)
+
+	placements := []*grid.HeadedPlacement{{}, {}}
+	queues := make([]actions.Queue, len(placements))
+	for i, p := range placements {
+		p.Place(space.At(grid.P(0, i)))
+	}
+	// the second one is on its way to the end, so the first one gets sent to where it stands
+	queues[1].Push(placements[1].MoveTo(space.At(grid.P(0, 2)), nav.StepTime))
+	queues[1].Run(time.Second / 4)
+
+	assigned := grid.AssignDestinations(placements, space.At(grid.P(0, 1)))
+	for i, p := range placements {
+		queues[i].Replace(p.Navigate(assigned[p], nav))
+	}
+
+	// when
+	var statuses [2]actions.Status
+	for tick := 0; tick < 4; tick++ {
+		for i := range statuses {
+			if !statuses[i].Done() {
+				statuses[i] = queues[i].Run(time.Second / 2)
+			}
+		}
+	}
+
+	// then
+	assert.That(assigned[placements[0]].AtPoint() == grid.P(0, 1), t.Fatalf, "the first placement got destination %v, want %v", assigned[placements[0]].AtPoint(), grid.P(0, 1))
+	for i, p := range placements {
+		assert.That(statuses[i].Done(), t.Errorf, "placement %d got status %#v, want it done", i, statuses[i])
+		assertPlaced(t, p, assigned[p].AtPoint())
+	}
+}
+
+func TestPlacementsSentOntoEachOthersPositionsSwapRightAway(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	crowd := grid.NewCrowd()
+
+	left, right := grid.HeadedPlacement{}, grid.HeadedPlacement{}
+	left.Place(space.At(grid.P(0, 0)))
+	right.Place(space.At(grid.P(0, 1)))
+
+	toRight := left.Navigate(space.At(grid.P(0, 1)), navigation(space, crowd))
+	toLeft := right.Navigate(space.At(grid.P(0, 0)), navigation(space, crowd))
+
+	// when
+	var leftStatus, rightStatus actions.Status
+	for tick := 0; tick < 2; tick++ {
+		leftStatus = toRight.Run(time.Second / 2)
+		rightStatus = toLeft.Run(time.Second / 2)
+	}
+
+	// then
+	assert.That(leftStatus.Done(), t.Errorf, "got left status %#v, want it done", leftStatus)
+	assert.That(rightStatus.Done(), t.Errorf, "got right status %#v, want it done", rightStatus)
+	assertPlaced(t, &left, grid.P(0, 1))
+	assertPlaced(t, &right, grid.P(0, 0))
+}
+
+func TestCrowdDoesNotCountUncomparableTakersAsNavigating(t *testing.T) {
+	// given
+	space := lineSpace(1)
+	pos := space.At(grid.P(0, 0))
+	pos.Take(uncomparableTaker{names: []string{"rock"}})
+	crowd := grid.NewCrowd()
+
+	// when
+	navigating := crowd.Navigating(pos)
+
+	// then
+	assert.That(!navigating, t.Errorf, "a position taken by an uncomparable taker counts as navigating")
+}
+
+func TestNavigatingPlacementsOutsideOfCrowdGiveUpInCorridor(t *testing.T) {
+	// given
+	space := lineSpace(6)
+
+	left, right := grid.HeadedPlacement{}, grid.HeadedPlacement{}
+	left.Place(space.At(grid.P(0, 0)))
+	right.Place(space.At(grid.P(0, 5)))
+
+	toRight := left.Navigate(space.At(grid.P(0, 3)), navigation(space, nil))
+	toLeft := right.Navigate(space.At(grid.P(0, 2)), navigation(space, nil))
+
+	// when
+	var leftStatus, rightStatus actions.Status
+	for tick := 0; tick < 40; tick++ {
+		leftStatus = toRight.Run(time.Second / 2)
+		rightStatus = toLeft.Run(time.Second / 2)
+		if leftStatus.Interrupted() || rightStatus.Interrupted() {
+			break
+		}
+	}
+
+	// then
+	assert.That(leftStatus.Interrupted(), t.Errorf, "got status %#v, want it interrupted", leftStatus)
+	assert.That(rightStatus.Interrupted(), t.Errorf, "got status %#v, want it interrupted", rightStatus)
+}
+
+func TestNavigatingPlacementGivesUpOnUnreachableDestination(t *testing.T) {
+	// given
+	space := lineSpace(4)
+	space.At(grid.P(0, 2)).Destroy()
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+
+	action := placement.Navigate(space.At(grid.P(0, 3)), navigation(space, nil))
+
+	// when
+	statuses := runFor(action, 10*time.Second, time.Second)
+
+	// then
+	last := statuses[len(statuses)-1]
+	assert.That(last.Interrupted(), t.Errorf, "got status %#v, want it interrupted", last)
+	assertPlaced(t, &placement, grid.P(0, 0))
+}
+
+func TestNavigatingPlacementThatIsNotPlacedIsInterrupted(t *testing.T) {
+	// given
+	space := lineSpace(4)
+	placement := grid.HeadedPlacement{}
+
+	action := placement.Navigate(space.At(grid.P(0, 3)), navigation(space, nil))
+
+	// when
+	status := action.Run(time.Second)
+
+	// then
+	assert.That(status == actions.Interrupted(time.Second), t.Errorf, "got status %#v, want %#v", status, actions.Interrupted(time.Second))
+}
+
+func TestNavigatingPlacementFinishesItsStepFirst(t *testing.T) {
+	// given
+	space := lineSpace(4)
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 2)))
+	placement.MoveTo(space.At(grid.P(0, 3)), time.Second).Run(time.Second / 2)
+
+	action := placement.Navigate(space.At(grid.P(0, 0)), navigation(space, nil))
+
+	// when
+	status := action.Run(time.Second/2 + 3*time.Second)
+
+	// then
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 0))
+}
+
+// navigation plans through the placements navigating with the crowd, when there is one.
+func navigation(space *grid.Space, crowd *grid.Crowd) grid.Navigation {
+	pf := grid.NewPathFinder(space)
+	if crowd != nil {
+		pf = pf.Through(crowd.Navigating)
+	}
+	return grid.Navigation{
+		FindPath:   pf.FindPath,
+		StepTime:   time.Second,
+		Patience:   2 * time.Second,
+		MaxRepaths: 2,
+		Crowd:      crowd,
+	}
+}
+
+// runFor runs the action in ticks of dt, until it is done, interrupted or the total time passes.
+func runFor(action actions.Action, total, dt time.Duration) []actions.Status {
+	var statuses []actions.Status
+	for passed := time.Duration(0); passed < total; passed += dt {
+		status := action.Run(dt)
+		statuses = append(statuses, status)
+		if status.Done() || status.Interrupted() {
+			break
+		}
+	}
+	return statuses
+}
@@ -6,9 +6,12 @@ package grid
 
 // A OnePosTaker is a space taker that occupies at most one grid position at a time.
 type OnePosTaker struct {
-	pos Position
+	pos   Position
+	owner any
 }
 
+var _ Owned = new(OnePosTaker)
+
 // LetOnto is part of the SpaceTaker interface.
 func (taker *OnePosTaker) LetOnto(pos Position) {
 	if taker.Placed() {
@@ -19,8 +22,17 @@ func (taker *OnePosTaker) LetOnto(pos Position) {
 
 // ForceOff is part of the SpaceTaker interface.
 func (taker *OnePosTaker) ForceOff(pos Position) {
+	if taker.pos == pos {
+		taker.pos = Position{}
+	}
 }
 
+// Owner is part of the Owned interface.
+func (taker *OnePosTaker) Owner() any { return taker.owner }
+
+// SetOwner says what the taker is a part of.
+func (taker *OnePosTaker) SetOwner(owner any) { taker.owner = owner }
+
 // Placed says whether the space taker is taking a position.
 func (taker *OnePosTaker) Placed() bool {
 	return taker.pos != Position{}
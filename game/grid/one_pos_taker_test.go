@@ -99,3 +99,19 @@ func TestOnePosTakerCanLeaveTakenPosition(t *testing.T) {
 		taker.AtPoint() == grid.Point{},
 		t.Fatalf, "reported at point %#v - want %#v", taker.AtPoint(), grid.Point{})
 }
+
+func TestOnePosTakerIsNotPlacedOnceItsPositionIsFreed(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(2, 3))
+	pos.Create()
+
+	taker := grid.OnePosTaker{}
+	pos.Take(&taker)
+
+	// when
+	pos.Free()
+
+	// then
+	assert.That(!taker.Placed(), t.Errorf, "the taker is placed - it should not be")
+}
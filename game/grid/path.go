@@ -15,7 +15,10 @@ type Path []Position
 
 // A PathFinder finds paths from one point to another.
 type PathFinder struct {
-	space *Space
+	space     *Space
+	footprint Footprint
+	self      SpaceTaker
+	through   func(Position) bool
 }
 
 // NewPathFinder creates a path finder that searches for path through the specified space.
@@ -23,20 +26,39 @@ type PathFinder struct {
 // The path finder will be sensitive to what positions do and do not exist in the space.
 // The space can be modified after the path finder is created - it will be aware of the updates.
 func NewPathFinder(space *Space) PathFinder {
-	return PathFinder{space}
+	return PathFinder{space: space, footprint: SingleCell()}
+}
+
+// NewFootprintPathFinder creates a path finder for something with the given footprint, like a FootprintPlacement.
+//
+// The paths it finds are made of the positions the footprint is anchored at.
+// At every step the whole footprint has to fit, with positions taken by self not getting in the way.
+func NewFootprintPathFinder(space *Space, footprint Footprint, self SpaceTaker) PathFinder {
+	if len(footprint) == 0 {
+		panic("empty footprint")
+	}
+	return PathFinder{space: space, footprint: footprint, self: self}
+}
+
+// Through creates a copy of the path finder that also goes through the taken positions passable allows, like ones taken by allies.
+// Whatever takes them still has to get out of the way when the path is followed.
+func (pf PathFinder) Through(passable func(Position) bool) PathFinder {
+	pf.through = passable
+	return pf
 }
 
 // IsViable validates a path.
 //
 // It will be false if the path contains positions from a different space.
 // It will be false if the path contains positions that cannot be occupied.
+// For a footprint path finder, all the positions covered at every step have to exist.
 // It will be false if the path contains two consecutive positions that are not neighbours.
 func (pf PathFinder) IsViable(path Path) bool {
 	viable := true
 	viable = viable && len(path) > 0
 	for _, pos := range path {
 		inSpace := pf.space.At(pos.AtPoint()) == pos
-		viable = viable && inSpace && pos.Exists()
+		viable = viable && inSpace && pf.footprint.Exists(pf.space, pos.at)
 	}
 	for i := 1; i < len(path); i++ {
 		prev, next := path[i-1], path[i]
@@ -62,7 +84,7 @@ func (pf PathFinder) FindPath(src, dst Position) (path Path, exists bool) {
 }
 
 func (pf PathFinder) graph() astar.Graph {
-	return &_PathFinderGraph{space: pf.space}
+	return &_PathFinderGraph{pf: pf}
 }
 
 func (pf PathFinder) distance(a, b astar.Node) float64 {
@@ -80,7 +102,7 @@ func (PathFinder) heuristic(a, b astar.Node) float64 {
 
 type _PathFinderGraph struct {
 	neighbourBuf [4]astar.Node
-	space        *Space
+	pf           PathFinder
 }
 
 var _ astar.Graph = &_PathFinderGraph{}
@@ -97,9 +119,21 @@ func (g *_PathFinderGraph) Neighbours(node astar.Node) []astar.Node {
 }
 
 func (g *_PathFinderGraph) appendViable(ns []astar.Node, pt Point) []astar.Node {
-	pos := g.space.At(pt)
-	if !pos.Exists() || pos.Taken() {
+	if !g.pf.fits(pt) {
 		return ns
 	}
-	return append(ns, pos)
+	return append(ns, g.pf.space.At(pt))
+}
+
+func (pf PathFinder) fits(anchor Point) bool {
+	if pf.through == nil {
+		return pf.footprint.Fits(pf.space, anchor, pf.self)
+	}
+	for _, pt := range pf.footprint.At(anchor) {
+		pos := pf.space.At(pt)
+		if !pos.Exists() || (pos.Taken() && pos.Taker() != pf.self && !pf.through(pos)) {
+			return false
+		}
+	}
+	return true
 }
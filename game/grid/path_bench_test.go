@@ -0,0 +1,106 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+var pathBenchmarkSpaces = map[string]func() *grid.Space{
+	"Open50":  func() *grid.Space { return squareSpace(50) },
+	"Open200": func() *grid.Space { return squareSpace(200) },
+	"Maze200": func() *grid.Space { return mazeSpace(200) },
+}
+
+func BenchmarkFindPath(b *testing.B) {
+	for name, newSpace := range pathBenchmarkSpaces {
+		space := newSpace()
+		side := space.Max().Row + 1
+
+		b.Run(name+"/PathFinder", func(b *testing.B) {
+			finder := grid.NewPathFinder(space)
+			for i := 0; i < b.N; i++ {
+				src, dst := benchmarkEnds(space, side, i)
+				finder.FindPath(src, dst)
+			}
+		})
+
+		b.Run(name+"/HierarchicalCold", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				finder := grid.NewHierarchicalPathFinder(space, grid.DefaultClusterSize)
+				src, dst := benchmarkEnds(space, side, i)
+				finder.FindPath(src, dst)
+				finder.Close()
+			}
+		})
+
+		b.Run(name+"/Hierarchical", func(b *testing.B) {
+			finder := grid.NewHierarchicalPathFinder(space, grid.DefaultClusterSize)
+			defer finder.Close()
+			// There are more pairs than paths the finder remembers, so most searches are not cached.
+			pairs := randomEnds(space, side, 1<<14)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pair := pairs[i%len(pairs)]
+				finder.FindPath(pair[0], pair[1])
+			}
+		})
+
+		b.Run(name+"/HierarchicalCached", func(b *testing.B) {
+			finder := grid.NewHierarchicalPathFinder(space, grid.DefaultClusterSize)
+			defer finder.Close()
+			src, dst := benchmarkEnds(space, side, 0)
+			for i := 0; i < b.N; i++ {
+				finder.FindPath(src, dst)
+			}
+		})
+
+		b.Run(name+"/HierarchicalWithMovingUnit", func(b *testing.B) {
+			finder := grid.NewHierarchicalPathFinder(space, grid.DefaultClusterSize)
+			defer finder.Close()
+			unit := &grid.OnePosTaker{}
+			src, dst := benchmarkEnds(space, side, 0)
+			for i := 0; i < b.N; i++ {
+				space.At(grid.P(side/2, i%side)).Take(unit)
+				finder.FindPath(src, dst)
+			}
+			unit.Leave()
+		})
+	}
+}
+
+// benchmarkEnds picks the ends of a path crossing the whole space, different for each i.
+func benchmarkEnds(space *grid.Space, side, i int) (src, dst grid.Position) {
+	src = space.At(grid.P(0, i%side))
+	dst = space.At(grid.P(side-1, (i*7+side/2)%side))
+	return src, dst
+}
+
+// randomEnds picks n pairs of ends of paths, from the bottom to the top of the space.
+func randomEnds(space *grid.Space, side, n int) [][2]grid.Position {
+	rng := rand.New(rand.NewSource(1))
+	pairs := make([][2]grid.Position, n)
+	for i := range pairs {
+		pairs[i][0] = space.At(grid.P(rng.Intn(side/4), rng.Intn(side)))
+		pairs[i][1] = space.At(grid.P(side-1-rng.Intn(side/4), rng.Intn(side)))
+	}
+	return pairs
+}
+
+// mazeSpace creates a side by side space with walls across every tenth row, each with a few gaps.
+func mazeSpace(side int) *grid.Space {
+	space := squareSpace(side)
+	for row := 5; row < side-1; row += 10 {
+		for column := 0; column < side; column++ {
+			if (column+row)%37 != 0 {
+				space.At(grid.P(row, column)).Destroy()
+			}
+		}
+	}
+	return space
+}
@@ -179,6 +179,35 @@ func TestFoundPathAvoidsTakenPositions(t *testing.T) {
 	}
 }
 
+func TestFoundPathGoesThroughPassablePositions(t *testing.T) {
+	kases := map[string]struct {
+		Passable bool
+		Want     bool
+	}{
+		"passable":     {Passable: true, Want: true},
+		"not passable": {Passable: false, Want: false},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(3)
+			taken := space.At(grid.P(0, 1))
+			taken.Take(&grid.OnePosTaker{})
+
+			finder := grid.NewPathFinder(space).Through(func(pos grid.Position) bool {
+				return kase.Passable && pos == taken
+			})
+
+			// when
+			path, ok := finder.FindPath(space.At(grid.P(0, 0)), space.At(grid.P(0, 2)))
+
+			// then
+			assert.That(ok == kase.Want, t.Errorf, "got path %v (%v)", path, ok)
+		})
+	}
+}
+
 func assertPathFromTo(onErr assert.ErrorFunc, path grid.Path, from, to grid.Point) {
 	if len(path) == 0 {
 		onErr("path is empty")
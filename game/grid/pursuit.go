@@ -0,0 +1,194 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// Pursuit says how a placement chases another one.
+type Pursuit struct {
+	// Through says which taken positions the way towards the target can go through, like for PathFinder.Through.
+	// Without it, all the taken positions are avoided.
+	Through func(Position) bool
+	// StepTime is how long it takes to move to the next position.
+	StepTime time.Duration
+	// Range is how many steps away from the target is close enough.
+	// Anything below 1 means being next to it.
+	Range int
+	// ReplanEvery is how often the way is planned again, to follow the target as it moves.
+	// With zero, it is planned again before every step.
+	ReplanEvery time.Duration
+	// GiveUpAfter is how long the chase can take.
+	// With zero, it can take forever.
+	GiveUpAfter time.Duration
+	// MaxFailures is how many times in a row planning the way can fail before giving up.
+	// With zero, the placement never gives up because of it.
+	MaxFailures int
+}
+
+// Pursue creates an action that moves the placement until it is within range of the target.
+//
+// The target is chased where it is headed when it is in the middle of a step, and where it is otherwise.
+// The way there gets planned again as the target moves.
+// While there is no way to get closer, the placement waits.
+//
+// The action is done once the placement is within range.
+// It is interrupted when either placement is not placed, when they are in different spaces, or when one of the give up conditions is met.
+func (hp *HeadedPlacement) Pursue(target *HeadedPlacement, p Pursuit) actions.Action {
+	return &_PursueAction{placement: hp, target: target, pursuit: p, stepper: _Stepper{placement: hp}}
+}
+
+type _PursueAction struct {
+	placement *HeadedPlacement
+	target    *HeadedPlacement
+	pursuit   Pursuit
+
+	stepper   _Stepper
+	path      Path
+	sincePlan time.Duration
+	elapsed   time.Duration
+	failures  int
+}
+
+func (action *_PursueAction) Run(atMost time.Duration) actions.Status {
+	hp := action.placement
+	for {
+		ready, timeLeft, blocked := action.stepper.advance(atMost)
+		if !ready {
+			action.pass(atMost)
+			return actions.Paused()
+		}
+		if blocked {
+			// The way gets planned again, around whoever took the step.
+			action.path = nil
+		}
+		action.pass(atMost - timeLeft)
+		atMost = timeLeft
+
+		goal, ok := action.goal()
+		if !hp.Placed() || !ok {
+			return actions.Interrupted(atMost)
+		}
+
+		at := hp.pos.pos
+		if steps(at.at, goal.at) <= action.reach() {
+			return actions.Done(atMost)
+		}
+		if action.pursuit.GiveUpAfter > 0 && action.elapsed >= action.pursuit.GiveUpAfter {
+			return actions.Interrupted(atMost)
+		}
+
+		next, ok := action.nextStep(at, goal)
+		if !ok {
+			action.failures++
+			if action.pursuit.MaxFailures > 0 && action.failures > action.pursuit.MaxFailures {
+				return actions.Interrupted(atMost)
+			}
+		}
+		if !ok || next.Taken() {
+			action.path = nil
+			action.pass(atMost)
+			return actions.Paused()
+		}
+		action.stepper.moveTo(next, action.pursuit.StepTime)
+	}
+}
+
+func (action *_PursueAction) pass(dt time.Duration) {
+	action.sincePlan += dt
+	action.elapsed += dt
+}
+
+// goal is the position the target is at, or the one it is headed to.
+// It reports false when the target cannot be chased.
+func (action *_PursueAction) goal() (Position, bool) {
+	target := action.target
+	if !target.Placed() || target.pos.pos.space != action.placement.pos.pos.space {
+		return Position{}, false
+	}
+	if target.Headed() {
+		return target.heading.pos, true
+	}
+	return target.pos.pos, true
+}
+
+func (action *_PursueAction) reach() int {
+	if action.pursuit.Range < 1 {
+		return 1
+	}
+	return action.pursuit.Range
+}
+
+// nextStep is the position after at on the planned path.
+// The path gets planned again when it is due, or when the placement is not on it anymore.
+func (action *_PursueAction) nextStep(at, goal Position) (Position, bool) {
+	due := action.sincePlan >= action.pursuit.ReplanEvery
+	for i, pos := range action.path {
+		if !due && pos == at && i+1 < len(action.path) {
+			action.path = action.path[i:]
+			return action.path[1], true
+		}
+	}
+
+	action.sincePlan = 0
+	path, ok := action.plan(at, goal)
+	if !ok {
+		action.path = nil
+		return at, false
+	}
+	action.failures = 0
+	action.path = path
+	return path[1], true
+}
+
+// plan looks for the shortest way from at to a position within range of the goal.
+// Positions closer to the goal are preferred, even when the way to them is longer.
+//
+// It is a single search spreading out from at, which stops early once it finds a way to right next to the goal.
+func (action *_PursueAction) plan(at, goal Position) (Path, bool) {
+	best, bestRing := at.at, action.reach()+1
+	parents := map[Point]Point{at.at: at.at}
+	queue := []Point{at.at}
+	for len(queue) > 0 && bestRing > 1 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, pt := range neighbours(current) {
+			if _, seen := parents[pt]; seen || !action.passable(at.space.At(pt)) {
+				continue
+			}
+			parents[pt] = current
+			queue = append(queue, pt)
+			// The search goes out one step at a time, so the first position found in a ring is the closest one.
+			if r := steps(pt, goal.at); r >= 1 && r < bestRing {
+				best, bestRing = pt, r
+			}
+		}
+	}
+	if best == at.at {
+		return nil, false
+	}
+
+	pts := []Point{best}
+	for pt := best; pt != at.at; pt = parents[pt] {
+		pts = append(pts, parents[pt])
+	}
+	reverse(pts)
+
+	path := make(Path, len(pts))
+	for i, pt := range pts {
+		path[i] = at.space.At(pt)
+	}
+	return path, true
+}
+
+func (action *_PursueAction) passable(pos Position) bool {
+	if !pos.Exists() {
+		return false
+	}
+	return !pos.Taken() || (action.pursuit.Through != nil && action.pursuit.Through(pos))
+}
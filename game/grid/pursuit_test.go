@@ -0,0 +1,225 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestPursuingPlacementStopsWithinRange(t *testing.T) {
+	kases := map[string]struct {
+		Range int
+		Want  grid.Point
+	}{
+		"zero means next to": {Range: 0, Want: grid.P(0, 5)},
+		"next to":            {Range: 1, Want: grid.P(0, 5)},
+		"further":            {Range: 3, Want: grid.P(0, 3)},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(8)
+			target := grid.HeadedPlacement{}
+			target.Place(space.At(grid.P(0, 6)))
+
+			placement := grid.HeadedPlacement{}
+			placement.Place(space.At(grid.P(0, 0)))
+
+			p := pursuit()
+			p.Range = kase.Range
+			action := placement.Pursue(&target, p)
+
+			// when
+			statuses := runFor(action, 10*time.Second, time.Second)
+
+			// then
+			last := statuses[len(statuses)-1]
+			assert.That(last.Done(), t.Errorf, "got status %#v, want it done", last)
+			assertPlaced(t, &placement, kase.Want)
+		})
+	}
+}
+
+func TestPursuingPlacementCatchesUpWithMovingTarget(t *testing.T) {
+	// given
+	space := rectSpace(3, 12)
+	target := grid.HeadedPlacement{}
+	target.Place(space.At(grid.P(1, 3)))
+	fleeing := target.FollowPath(pathOf(space, grid.P(1, 3), grid.P(1, 4), grid.P(1, 5), grid.P(1, 6), grid.P(1, 7)), 2*time.Second)
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(1, 0)))
+	action := placement.Pursue(&target, pursuit())
+
+	// when
+	var status actions.Status
+	for tick := 0; tick < 40 && !status.Done() && !status.Interrupted(); tick++ {
+		fleeing.Run(time.Second / 2)
+		status = action.Run(time.Second / 2)
+	}
+
+	// then
+	assert.That(status.Done(), t.Errorf, "got status %#v, want it done", status)
+	at, goal := placement.AtPoint(), target.AtPoint()
+	if target.Headed() {
+		goal = target.Heading()
+	}
+	assert.That(stepsBetween(at, goal) == 1, t.Errorf, "placement at %v is not next to the target going to %v", at, goal)
+}
+
+func TestPursuingPlacementGivesUp(t *testing.T) {
+	kases := map[string]func(*grid.Pursuit){
+		"after too long":          func(p *grid.Pursuit) { p.GiveUpAfter = 5 * time.Second },
+		"after too many failures": func(p *grid.Pursuit) { p.MaxFailures = 3 },
+	}
+
+	for name, configure := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(6)
+			space.At(grid.P(0, 3)).Destroy()
+			target := grid.HeadedPlacement{}
+			target.Place(space.At(grid.P(0, 5)))
+
+			placement := grid.HeadedPlacement{}
+			placement.Place(space.At(grid.P(0, 0)))
+
+			p := pursuit()
+			configure(&p)
+			action := placement.Pursue(&target, p)
+
+			// when
+			statuses := runFor(action, 20*time.Second, time.Second)
+
+			// then
+			last := statuses[len(statuses)-1]
+			assert.That(last.Interrupted(), t.Errorf, "got status %#v, want it interrupted", last)
+			assert.That(len(statuses) < 20, t.Errorf, "gave up after %d runs", len(statuses))
+			assertPlaced(t, &placement, grid.P(0, 0))
+		})
+	}
+}
+
+func TestPursuingPlacementWaitsWhileThereIsNoWay(t *testing.T) {
+	// given
+	space := lineSpace(6)
+	blocker := grid.HeadedPlacement{}
+	blocker.Place(space.At(grid.P(0, 2)))
+	target := grid.HeadedPlacement{}
+	target.Place(space.At(grid.P(0, 5)))
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+	action := placement.Pursue(&target, pursuit())
+	waiting := action.Run(3 * time.Second)
+
+	// when
+	blocker.Leave()
+	status := action.Run(4 * time.Second)
+
+	// then
+	assert.That(waiting == actions.Paused(), t.Errorf, "got status %#v while blocked, want %#v", waiting, actions.Paused())
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 4))
+}
+
+func TestPursuingPlacementGetsCloserThroughPassablePositions(t *testing.T) {
+	kases := map[string]struct {
+		Passable bool
+		Want     grid.Point
+	}{
+		"passable":     {Passable: true, Want: grid.P(0, 1)},
+		"not passable": {Passable: false, Want: grid.P(0, 0)},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(6)
+			blocker := grid.HeadedPlacement{}
+			blocker.Place(space.At(grid.P(0, 2)))
+			target := grid.HeadedPlacement{}
+			target.Place(space.At(grid.P(0, 5)))
+
+			placement := grid.HeadedPlacement{}
+			placement.Place(space.At(grid.P(0, 0)))
+			p := pursuit()
+			p.Through = func(grid.Position) bool { return kase.Passable }
+			action := placement.Pursue(&target, p)
+
+			// when
+			status := action.Run(3 * time.Second)
+
+			// then
+			assert.That(status == actions.Paused(), t.Errorf, "got status %#v, want %#v", status, actions.Paused())
+			assertPlaced(t, &placement, kase.Want)
+		})
+	}
+}
+
+func TestPursuingPlacementIsInterruptedWhenTargetIsGone(t *testing.T) {
+	// given
+	space := lineSpace(8)
+	target := grid.HeadedPlacement{}
+	target.Place(space.At(grid.P(0, 6)))
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+	action := placement.Pursue(&target, pursuit())
+	action.Run(time.Second)
+
+	// when
+	target.Leave()
+	status := action.Run(3 * time.Second)
+
+	// then
+	assert.That(status.Interrupted(), t.Errorf, "got status %#v, want it interrupted", status)
+	// the step started before the target left is finished
+	assertPlaced(t, &placement, grid.P(0, 2))
+}
+
+func TestPursuingPlacementFinishesItsStepFirst(t *testing.T) {
+	// given
+	space := lineSpace(6)
+	target := grid.HeadedPlacement{}
+	target.Place(space.At(grid.P(0, 0)))
+
+	placement := grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 2)))
+	placement.MoveTo(space.At(grid.P(0, 3)), time.Second).Run(time.Second / 2)
+
+	action := placement.Pursue(&target, pursuit())
+
+	// when
+	status := action.Run(time.Second/2 + 2*time.Second)
+
+	// then
+	assert.That(status == actions.Done(0), t.Errorf, "got status %#v, want %#v", status, actions.Done(0))
+	assertPlaced(t, &placement, grid.P(0, 1))
+}
+
+func pursuit() grid.Pursuit {
+	return grid.Pursuit{
+		StepTime: time.Second,
+	}
+}
+
+func stepsBetween(a, b grid.Point) int {
+	rows, columns := a.Row-b.Row, a.Column-b.Column
+	if rows < 0 {
+		rows = -rows
+	}
+	if columns < 0 {
+		columns = -columns
+	}
+	return rows + columns
+}
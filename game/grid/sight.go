@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+// Line are the points on a straight line from one point to another, both ends included.
+//
+// The line is the same both ways - only the order of the points differs.
+func Line(from, to Point) []Point {
+	if to.Row < from.Row || (to.Row == from.Row && to.Column < from.Column) {
+		pts := Line(to, from)
+		reverse(pts)
+		return pts
+	}
+
+	dr, dc := -abs(to.Row-from.Row), abs(to.Column-from.Column)
+	stepC := 1
+	if to.Column < from.Column {
+		stepC = -1
+	}
+
+	pts := make([]Point, 0, maxInt(-dr, dc)+1)
+	at, err := from, dc+dr
+	for {
+		pts = append(pts, at)
+		if at == to {
+			return pts
+		}
+		twice := 2 * err
+		if twice >= dr {
+			err += dr
+			at.Column += stepC
+		}
+		if twice <= dc {
+			err += dc
+			at.Row++
+		}
+	}
+}
+
+// InSight says whether nothing stands between two points.
+// All the positions on the line between them have to exist and be free.
+// What is at the ends does not matter, so a point is always in sight of itself and of its neighbours.
+func (space *Space) InSight(from, to Point) bool {
+	return space.InSightThrough(from, to, nil)
+}
+
+// InSightThrough is like InSight, except that the taken positions passable allows do not stand in the way.
+func (space *Space) InSightThrough(from, to Point, passable func(Position) bool) bool {
+	line := Line(from, to)
+	if len(line) < 3 {
+		return true
+	}
+	for _, pt := range line[1 : len(line)-1] {
+		pos := space.At(pt)
+		if !pos.Exists() {
+			return false
+		}
+		if pos.Taken() && (passable == nil || !passable(pos)) {
+			return false
+		}
+	}
+	return true
+}
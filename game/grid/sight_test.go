@@ -0,0 +1,148 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestLine(t *testing.T) {
+	kases := map[string]struct {
+		From, To grid.Point
+		Want     []grid.Point
+	}{
+		"single point": {
+			From: grid.P(1, 1), To: grid.P(1, 1),
+			Want: []grid.Point{grid.P(1, 1)},
+		},
+		"along a row": {
+			From: grid.P(0, 3), To: grid.P(0, 0),
+			Want: []grid.Point{grid.P(0, 3), grid.P(0, 2), grid.P(0, 1), grid.P(0, 0)},
+		},
+		"along a column": {
+			From: grid.P(0, 0), To: grid.P(2, 0),
+			Want: []grid.Point{grid.P(0, 0), grid.P(1, 0), grid.P(2, 0)},
+		},
+		"diagonal": {
+			From: grid.P(0, 0), To: grid.P(-2, 2),
+			Want: []grid.Point{grid.P(0, 0), grid.P(-1, 1), grid.P(-2, 2)},
+		},
+		"shallow": {
+			From: grid.P(0, 0), To: grid.P(1, 4),
+			Want: []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(1, 2), grid.P(1, 3), grid.P(1, 4)},
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			line := grid.Line(kase.From, kase.To)
+
+			// then
+			assert.That(pointsEqual(line, kase.Want), t.Errorf, "got line %v, want %v", line, kase.Want)
+		})
+	}
+}
+
+func TestLineIsTheSameBothWays(t *testing.T) {
+	// given
+	from, to := grid.P(-3, 2), grid.P(4, -5)
+
+	// when
+	there := grid.Line(from, to)
+	back := grid.Line(to, from)
+
+	// then
+	for i := range there {
+		j := len(back) - 1 - i
+		assert.That(j >= 0 && there[i] == back[j], t.Errorf, "the lines differ at %d: %v there, %v back", i, there, back)
+	}
+}
+
+func TestInSight(t *testing.T) {
+	kases := map[string]struct {
+		Setup func(*grid.Space)
+		Want  bool
+	}{
+		"nothing in between": {
+			Setup: func(*grid.Space) {},
+			Want:  true,
+		},
+		"ends taken": {
+			Setup: func(space *grid.Space) {
+				space.At(grid.P(0, 0)).Take(grid.DummyTaker())
+				space.At(grid.P(0, 4)).Take(grid.DummyTaker())
+			},
+			Want: true,
+		},
+		"taken in between": {
+			Setup: func(space *grid.Space) { space.At(grid.P(0, 2)).Take(grid.DummyTaker()) },
+			Want:  false,
+		},
+		"missing in between": {
+			Setup: func(space *grid.Space) { space.At(grid.P(0, 2)).Destroy() },
+			Want:  false,
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(5)
+			kase.Setup(space)
+
+			// when
+			inSight := space.InSight(grid.P(0, 0), grid.P(0, 4))
+
+			// then
+			assert.That(inSight == kase.Want, t.Errorf, "got %v, want %v", inSight, kase.Want)
+		})
+	}
+}
+
+func TestInSightWithNothingInBetween(t *testing.T) {
+	kases := map[string]struct {
+		From, To grid.Point
+	}{
+		"same point": {From: grid.P(0, 1), To: grid.P(0, 1)},
+		"neighbours": {From: grid.P(0, 1), To: grid.P(0, 2)},
+		"diagonal":   {From: grid.P(0, 1), To: grid.P(1, 2)},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := rectSpace(2, 3)
+			space.At(kase.From).Take(grid.DummyTaker())
+
+			// when
+			inSight := space.InSight(kase.From, kase.To)
+
+			// then
+			assert.That(inSight, t.Errorf, "%v is not in sight of %v", kase.To, kase.From)
+		})
+	}
+}
+
+func TestInSightThroughPassablePositions(t *testing.T) {
+	// given
+	space := lineSpace(5)
+	seeThrough, solid := &RecordingSpaceTaker{}, &RecordingSpaceTaker{}
+	space.At(grid.P(0, 1)).Take(seeThrough)
+	space.At(grid.P(0, 3)).Take(solid)
+	passable := func(pos grid.Position) bool { return pos.Taker() == seeThrough }
+
+	// when
+	past := space.InSightThrough(grid.P(0, 0), grid.P(0, 2), passable)
+	blocked := space.InSightThrough(grid.P(0, 0), grid.P(0, 4), passable)
+
+	// then
+	assert.That(past, t.Errorf, "the sight is blocked by a passable position")
+	assert.That(!blocked, t.Errorf, "the sight goes through a position that is not passable")
+}
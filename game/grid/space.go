@@ -5,6 +5,7 @@
 package grid
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/szabba/tob-cob/game/actions"
@@ -15,15 +16,17 @@ import (
 // It is a subspace of a 2D grid.
 // Which positions on the grid exist can change dynamically.
 type Space struct {
-	poses    map[Point]SpaceTaker
-	min, max Point
-	empty    bool
+	chunks map[Point]*_Chunk
+	count  int
+	bounds _Bounds
+	subs   []*Subscription
 }
 
 // NewSpace creates a new, empty space.
 func NewSpace() *Space {
 	return &Space{
-		poses: map[Point]SpaceTaker{},
+		chunks: map[Point]*_Chunk{},
+		bounds: newBounds(),
 	}
 }
 
@@ -35,32 +38,85 @@ func (space *Space) At(at Point) Position {
 }
 
 // Min is the point with the column of the leftmost position and row of the bottom one.
-func (space *Space) Min() Point { return space.min }
+func (space *Space) Min() Point { return space.bounds.min }
 
 // Max is the point with the column of the rightmost position and row of the top one.
-func (space *Space) Max() Point { return space.max }
+func (space *Space) Max() Point { return space.bounds.max }
 
-func (space *Space) fixMinMax() {
-	space.empty = true
-	space.min, space.max = Point{}, Point{}
-	for at := range space.poses {
-		space.pickMin(at.Row, &space.min.Row)
-		space.pickMin(at.Column, &space.min.Column)
-		space.pickMax(at.Row, &space.max.Row)
-		space.pickMax(at.Column, &space.max.Column)
-		space.empty = false
+// Size is the number of positions that exist in the space.
+func (space *Space) Size() int { return space.count }
+
+func (space *Space) lookup(at Point) (taker SpaceTaker, exists bool) {
+	key, index := chunkOf(at)
+	chunk := space.chunks[key]
+	if chunk == nil {
+		return nil, false
 	}
+	return chunk.takers[index], chunk.exists[index]
 }
 
-func (space *Space) pickMin(candidate int, dst *int) {
-	if space.empty || candidate < *dst {
-		*dst = candidate
+// create makes the position at the point exist, when it does not already.
+func (space *Space) create(at Point) {
+	key, index := chunkOf(at)
+	chunk := space.chunks[key]
+	if chunk == nil {
+		chunk = new(_Chunk)
+		space.chunks[key] = chunk
+	}
+	if chunk.exists[index] {
+		return
 	}
+	chunk.exists[index] = true
+	chunk.count++
+	space.count++
+	space.bounds.add(at)
 }
 
-func (space *Space) pickMax(candidate int, dst *int) {
-	if space.empty || *dst < candidate {
-		*dst = candidate
+// destroy makes the position at the point not exist, forgetting its taker.
+func (space *Space) destroy(at Point) {
+	key, index := chunkOf(at)
+	chunk := space.chunks[key]
+	if chunk == nil || !chunk.exists[index] {
+		return
+	}
+	chunk.exists[index] = false
+	chunk.takers[index] = nil
+	chunk.count--
+	space.count--
+	space.bounds.remove(at)
+	if chunk.count == 0 {
+		delete(space.chunks, key)
+	}
+}
+
+// setTaker changes the taker of a position that exists.
+func (space *Space) setTaker(at Point, taker SpaceTaker) {
+	key, index := chunkOf(at)
+	space.chunks[key].takers[index] = taker
+}
+
+// eachIn calls f with the positions that exist in the chunks overlapping the rectangle with the corners min and max.
+// Some of those positions can be outside of the rectangle.
+// The order is not specified.
+func (space *Space) eachIn(min, max Point, f func(at Point, taker SpaceTaker)) {
+	minKey, _ := chunkOf(min)
+	maxKey, _ := chunkOf(max)
+	if !atMostCells(minKey, maxKey, len(space.chunks)-1) {
+		for key, chunk := range space.chunks {
+			if minKey.Row <= key.Row && key.Row <= maxKey.Row && minKey.Column <= key.Column && key.Column <= maxKey.Column {
+				chunk.each(key, f)
+			}
+		}
+		return
+	}
+
+	var key Point
+	for key.Row = minKey.Row; key.Row <= maxKey.Row; key.Row++ {
+		for key.Column = minKey.Column; key.Column <= maxKey.Column; key.Column++ {
+			if chunk := space.chunks[key]; chunk != nil {
+				chunk.each(key, f)
+			}
+		}
 	}
 }
 
@@ -75,7 +131,7 @@ func (pos Position) AtPoint() Point { return pos.at }
 
 // Exists says whether the position within the space exists.
 func (pos Position) Exists() bool {
-	_, exists := pos.space.poses[pos.at]
+	_, exists := pos.space.lookup(pos.at)
 	return exists
 }
 
@@ -85,8 +141,8 @@ func (pos Position) Create() bool {
 	if pos.Exists() {
 		return false
 	}
-	pos.space.poses[pos.at] = nil
-	pos.space.fixMinMax()
+	pos.space.create(pos.at)
+	pos.space.emit(Event{Kind: PositionCreated, At: pos.at})
 	return true
 }
 
@@ -96,15 +152,24 @@ func (pos Position) Destroy() bool {
 	if pos.Taken() {
 		return false
 	}
-	ok := pos.Exists()
-	delete(pos.space.poses, pos.at)
-	pos.space.fixMinMax()
-	return ok
+	if !pos.Exists() {
+		return false
+	}
+	pos.space.destroy(pos.at)
+	pos.space.emit(Event{Kind: PositionDestroyed, At: pos.at})
+	return true
 }
 
 // Taken says whether the position is currently taken.
 func (pos Position) Taken() bool {
-	return pos.space.poses[pos.at] != nil
+	return pos.Taker() != nil
+}
+
+// Taker is what takes the position at the moment.
+// It is nil when the position is not taken.
+func (pos Position) Taker() SpaceTaker {
+	taker, _ := pos.space.lookup(pos.at)
+	return taker
 }
 
 // Take tries to mark the position as taken.
@@ -114,7 +179,8 @@ func (pos Position) Take(taker SpaceTaker) bool {
 		return false
 	}
 	taker.LetOnto(pos)
-	pos.space.poses[pos.at] = taker
+	pos.space.setTaker(pos.at, taker)
+	pos.space.emit(Event{Kind: PositionTaken, At: pos.at, Taker: taker})
 	return true
 }
 
@@ -124,14 +190,17 @@ func (pos Position) Free() bool {
 	if !pos.Taken() {
 		return false
 	}
-	pos.space.poses[pos.at].ForceOff(pos)
-	pos.space.poses[pos.at] = nil
+	taker := pos.Taker()
+	taker.ForceOff(pos)
+	pos.space.setTaker(pos.at, nil)
+	pos.space.emit(Event{Kind: PositionFreed, At: pos.at, Taker: taker})
 	return true
 }
 
 // A SpaceTaker is the thing that takes up a taken position.
 //
 // A space taker can take up multiple positions at once.
+// One that does is best a pointer, so that it compares equal to itself wherever it is found.
 type SpaceTaker interface {
 	// LetOnto tells the space taker that it is now taking up pos.
 	LetOnto(pos Position)
@@ -139,6 +208,28 @@ type SpaceTaker interface {
 	ForceOff(pos Position)
 }
 
+// comparableTaker says whether the taker can be compared with ==, which looking it up in a map does.
+func comparableTaker(taker SpaceTaker) bool {
+	return taker != nil && reflect.TypeOf(taker).Comparable()
+}
+
+// An Owned space taker is a part of something bigger, like a unit.
+// It lets that bigger thing be found by looking at the positions it takes.
+type Owned interface {
+	SpaceTaker
+	Owner() any
+}
+
+// OwnerAt is the owner of the taker that takes the position.
+// It is nil when the position is not taken or when the taker is not Owned.
+func OwnerAt(pos Position) any {
+	owned, ok := pos.Taker().(Owned)
+	if !ok {
+		return nil
+	}
+	return owned.Owner()
+}
+
 // DummyTaker returns a space taker that only takes up the position it takes.
 // It has no additional behaviour.
 func DummyTaker() SpaceTaker {
@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+var benchmarkSides = []int{100, 1000, 2000}
+
+func BenchmarkSpaceCreate(b *testing.B) {
+	for _, side := range benchmarkSides {
+		b.Run(fmt.Sprintf("%dx%d", side, side), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				squareSpace(side)
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*side*side), "ns/cell")
+		})
+	}
+}
+
+func BenchmarkSpaceDestroy(b *testing.B) {
+	for _, side := range benchmarkSides {
+		b.Run(fmt.Sprintf("%dx%d", side, side), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				space := squareSpace(side)
+				b.StartTimer()
+
+				// Destroying from the edges inwards keeps moving Min and Max.
+				for row := 0; row < side; row++ {
+					for column := 0; column < side; column++ {
+						space.At(grid.P(row, column)).Destroy()
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSpaceLookup(b *testing.B) {
+	for _, side := range benchmarkSides {
+		b.Run(fmt.Sprintf("%dx%d", side, side), func(b *testing.B) {
+			space := squareSpace(side)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				pt := grid.P(i*7919%side, i*104729%side)
+				if !space.At(pt).Exists() {
+					b.Fatalf("position %v does not exist", pt)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSpaceNeighbours(b *testing.B) {
+	for _, side := range benchmarkSides {
+		b.Run(fmt.Sprintf("%dx%d", side, side), func(b *testing.B) {
+			space := squareSpace(side)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				center := grid.P(i*7919%side, i*104729%side)
+				_ = space.InRadius(center, 1.5)
+			}
+		})
+	}
+}
+
+func squareSpace(side int) *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < side; row++ {
+		for column := 0; column < side; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	return space
+}
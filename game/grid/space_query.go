@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"math"
+	"sort"
+)
+
+// Positions are all the positions that exist in the space.
+//
+// Like the results of all the other space queries, they are ordered by row and then by column.
+func (space *Space) Positions() []Position {
+	return space.collect(func(Point, SpaceTaker) bool { return true })
+}
+
+// TakenPositions are all the positions in the space that are taken.
+func (space *Space) TakenPositions() []Position {
+	return space.collect(func(_ Point, taker SpaceTaker) bool { return taker != nil })
+}
+
+// InRect are the positions that exist within the rectangle with the corners min and max.
+// The rectangle includes its edges.
+func (space *Space) InRect(min, max Point) []Position {
+	inRect := func(at Point, _ SpaceTaker) bool {
+		return min.Row <= at.Row && at.Row <= max.Row &&
+			min.Column <= at.Column && at.Column <= max.Column
+	}
+
+	if min.Row > max.Row || min.Column > max.Column {
+		return nil
+	}
+	if !atMostCells(min, max, _ChunkArea) {
+		return space.collectIn(min, max, inRect)
+	}
+
+	var found []Position
+	for row := min.Row; row <= max.Row; row++ {
+		for column := min.Column; column <= max.Column; column++ {
+			pos := space.At(P(row, column))
+			if pos.Exists() {
+				found = append(found, pos)
+			}
+		}
+	}
+	return found
+}
+
+// InRadius are the positions that exist no further than radius from center.
+// The distance is measured in a straight line between the points.
+func (space *Space) InRadius(center Point, radius float64) []Position {
+	if radius < 0 {
+		return nil
+	}
+
+	reach := int(math.Floor(radius))
+	min := P(center.Row-reach, center.Column-reach)
+	max := P(center.Row+reach, center.Column+reach)
+
+	var found []Position
+	for _, pos := range space.InRect(min, max) {
+		dr := float64(pos.at.Row - center.Row)
+		dc := float64(pos.at.Column - center.Column)
+		if math.Hypot(dr, dc) <= radius {
+			found = append(found, pos)
+		}
+	}
+	return found
+}
+
+// Takers of the positions, each listed once, in the order they are first found in.
+// Positions that are not taken are skipped.
+//
+// Takers are told apart with ==.
+// Ones of types that cannot be compared get listed once for every position they take.
+func Takers(positions []Position) []SpaceTaker {
+	var takers []SpaceTaker
+	seen := map[SpaceTaker]bool{}
+	for _, pos := range positions {
+		taker := pos.Taker()
+		switch {
+		case taker == nil:
+			continue
+		case !comparableTaker(taker):
+		case seen[taker]:
+			continue
+		default:
+			seen[taker] = true
+		}
+		takers = append(takers, taker)
+	}
+	return takers
+}
+
+func (space *Space) collect(keep func(at Point, taker SpaceTaker) bool) []Position {
+	return space.collectIn(space.Min(), space.Max(), keep)
+}
+
+// collectIn is like collect, but it only looks at the chunks that overlap the rectangle with the corners min and max.
+func (space *Space) collectIn(min, max Point, keep func(at Point, taker SpaceTaker) bool) []Position {
+	var found []Position
+	space.eachIn(min, max, func(at Point, taker SpaceTaker) {
+		if keep(at, taker) {
+			found = append(found, space.At(at))
+		}
+	})
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i].at, found[j].at
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Column < b.Column
+	})
+	return found
+}
@@ -0,0 +1,182 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestSpaceQueries(t *testing.T) {
+	tests := map[string]struct {
+		Query func(space *grid.Space) []grid.Position
+		Want  []grid.Point
+	}{
+		"Positions": {
+			Query: (*grid.Space).Positions,
+			Want: []grid.Point{
+				grid.P(0, 0), grid.P(0, 1), grid.P(0, 2),
+				grid.P(1, 0), grid.P(1, 1), grid.P(1, 2),
+				grid.P(2, 0), grid.P(2, 1), grid.P(2, 2),
+				grid.P(7, 7),
+			},
+		},
+		"TakenPositions": {
+			Query: (*grid.Space).TakenPositions,
+			Want:  []grid.Point{grid.P(0, 2), grid.P(1, 1), grid.P(7, 7)},
+		},
+		"InRect": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRect(grid.P(1, 1), grid.P(3, 3))
+			},
+			Want: []grid.Point{grid.P(1, 1), grid.P(1, 2), grid.P(2, 1), grid.P(2, 2)},
+		},
+		"InRect/BiggerThanTheSpace": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRect(grid.P(-1, 1), grid.P(100, 100))
+			},
+			Want: []grid.Point{
+				grid.P(0, 1), grid.P(0, 2),
+				grid.P(1, 1), grid.P(1, 2),
+				grid.P(2, 1), grid.P(2, 2),
+				grid.P(7, 7),
+			},
+		},
+		"InRect/AreaOverflowingInt": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRect(grid.P(0, 0), grid.P(1<<36-1, 1<<36-1))
+			},
+			Want: []grid.Point{
+				grid.P(0, 0), grid.P(0, 1), grid.P(0, 2),
+				grid.P(1, 0), grid.P(1, 1), grid.P(1, 2),
+				grid.P(2, 0), grid.P(2, 1), grid.P(2, 2),
+				grid.P(7, 7),
+			},
+		},
+		"InRect/Inverted": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRect(grid.P(2, 2), grid.P(0, 0))
+			},
+		},
+		"InRadius/Zero": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRadius(grid.P(1, 1), 0)
+			},
+			Want: []grid.Point{grid.P(1, 1)},
+		},
+		"InRadius/One": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRadius(grid.P(1, 1), 1)
+			},
+			Want: []grid.Point{grid.P(0, 1), grid.P(1, 0), grid.P(1, 1), grid.P(1, 2), grid.P(2, 1)},
+		},
+		"InRadius/ReachingTheDiagonal": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRadius(grid.P(0, 0), 1.5)
+			},
+			Want: []grid.Point{grid.P(0, 0), grid.P(0, 1), grid.P(1, 0), grid.P(1, 1)},
+		},
+		"InRadius/Negative": {
+			Query: func(space *grid.Space) []grid.Position {
+				return space.InRadius(grid.P(1, 1), -1)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := querySpace()
+
+			// when
+			found := tt.Query(space)
+
+			// then
+			got := make([]grid.Point, len(found))
+			for i, pos := range found {
+				got[i] = pos.AtPoint()
+			}
+			assert.That(pointsEqual(got, tt.Want), t.Errorf, "got %v, want %v", got, tt.Want)
+		})
+	}
+}
+
+func TestTakersAreListedOnce(t *testing.T) {
+	// given
+	space := querySpace()
+	shared := &RecordingSpaceTaker{}
+	space.At(grid.P(0, 0)).Take(shared)
+	space.At(grid.P(0, 1)).Take(shared)
+
+	positions := space.InRect(grid.P(0, 0), grid.P(1, 1))
+
+	// when
+	takers := grid.Takers(positions)
+
+	// then
+	assert.That(len(takers) == 2, t.Fatalf, "got %d takers, want %d", len(takers), 2)
+	assert.That(takers[0] == shared, t.Errorf, "got first taker %#v, want %#v", takers[0], shared)
+	assert.That(
+		takers[1] == space.At(grid.P(1, 1)).Taker(),
+		t.Errorf, "got second taker %#v, want %#v", takers[1], space.At(grid.P(1, 1)).Taker())
+}
+
+func TestTakersOfTwoPositions(t *testing.T) {
+	kases := map[string]struct {
+		Taker grid.SpaceTaker
+		Want  int
+	}{
+		"pointer":                    {Taker: &RecordingSpaceTaker{}, Want: 1},
+		"value":                      {Taker: valueTaker{name: "rock"}, Want: 1},
+		"value of uncomparable type": {Taker: uncomparableTaker{names: []string{"rock"}}, Want: 2},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(2)
+			space.At(grid.P(0, 0)).Take(kase.Taker)
+			space.At(grid.P(0, 1)).Take(kase.Taker)
+
+			// when
+			takers := grid.Takers(space.TakenPositions())
+
+			// then
+			assert.That(len(takers) == kase.Want, t.Errorf, "got %d takers, want %d", len(takers), kase.Want)
+		})
+	}
+}
+
+// valueTaker is a space taker that gets copied around instead of pointed to.
+type valueTaker struct{ name string }
+
+func (valueTaker) LetOnto(grid.Position)  {}
+func (valueTaker) ForceOff(grid.Position) {}
+
+// uncomparableTaker is a space taker that cannot be compared with ==.
+type uncomparableTaker struct{ names []string }
+
+func (uncomparableTaker) LetOnto(grid.Position)  {}
+func (uncomparableTaker) ForceOff(grid.Position) {}
+
+// querySpace has a 3x3 square of positions at the origin and a lone one at (7, 7).
+// The positions at (0, 2), (1, 1) and (7, 7) are taken.
+func querySpace() *grid.Space {
+	space := grid.NewSpace()
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 3; column++ {
+			space.At(grid.P(row, column)).Create()
+		}
+	}
+	space.At(grid.P(7, 7)).Create()
+
+	for _, pt := range []grid.Point{grid.P(0, 2), grid.P(1, 1), grid.P(7, 7)} {
+		space.At(pt).Take(&RecordingSpaceTaker{})
+	}
+	return space
+}
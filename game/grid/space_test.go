@@ -367,6 +367,69 @@ func TestAFreedPositionIsNotTaken(t *testing.T) {
 	assert.That(!pos.Taken(), t.Errorf, "position should not be taken")
 }
 
+func TestAPositionThatIsNotTakenHasNoTaker(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(13, 25))
+	pos.Create()
+
+	// when
+	taker := pos.Taker()
+
+	// then
+	assert.That(taker == nil, t.Errorf, "got taker %#v, want none", taker)
+}
+
+func TestAPositionThatIsTakenKnowsItsTaker(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(13, 25))
+	pos.Create()
+
+	want := &RecordingSpaceTaker{}
+	pos.Take(want)
+
+	// when
+	taker := pos.Taker()
+
+	// then
+	assert.That(taker == want, t.Errorf, "got taker %#v, want %#v", taker, want)
+}
+
+func TestOwnerAtFindsTheOwnerOfTheTaker(t *testing.T) {
+	tests := map[string]struct {
+		Taker grid.SpaceTaker
+		Owner any
+	}{
+		"NotTaken": {},
+		"NotOwned": {
+			Taker: &RecordingSpaceTaker{},
+		},
+		"Owned": {
+			Taker: ownedTaker("unit"),
+			Owner: "unit",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := grid.NewSpace()
+			pos := space.At(grid.P(13, 25))
+			pos.Create()
+			if tt.Taker != nil {
+				pos.Take(tt.Taker)
+			}
+
+			// when
+			owner := grid.OwnerAt(pos)
+
+			// then
+			assert.That(owner == tt.Owner, t.Errorf, "got owner %#v, want %#v", owner, tt.Owner)
+		})
+	}
+}
+
 func TestSpaceTakerIsNotifiedWhenItIsLetOntoAPosition(t *testing.T) {
 	// given
 	space := grid.NewSpace()
@@ -786,6 +849,99 @@ func TestSpaceWithThreePointsInAColumnHasMiddleOneAsMaxAfterTheTopOneIsRemoved(t
 	assert.That(space.Max() == mid, t.Errorf, "got %#v - want %#v", space.Max(), mid)
 }
 
+func TestSpaceBoundsShrinkToTheRemainingPositions(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	corners := []grid.Point{grid.P(-40, 3), grid.P(25, -17), grid.P(5, 60)}
+	for _, pt := range corners {
+		space.At(pt).Create()
+	}
+	space.At(grid.P(0, 0)).Create()
+
+	// when
+	for _, pt := range corners {
+		space.At(pt).Destroy()
+	}
+
+	// then
+	assert.That(space.Min() == grid.P(0, 0), t.Errorf, "got min %#v - want %#v", space.Min(), grid.P(0, 0))
+	assert.That(space.Max() == grid.P(0, 0), t.Errorf, "got max %#v - want %#v", space.Max(), grid.P(0, 0))
+}
+
+func TestSpaceBoundsShrinkQuicklyBetweenFarApartPositions(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	far := grid.P(-1<<40, 1<<34)
+	space.At(grid.P(0, 0)).Create()
+	space.At(far).Create()
+
+	// when
+	space.At(grid.P(0, 0)).Destroy()
+
+	// then
+	assert.That(space.Min() == far, t.Errorf, "got min %#v - want %#v", space.Min(), far)
+	assert.That(space.Max() == far, t.Errorf, "got max %#v - want %#v", space.Max(), far)
+}
+
+func TestSpaceWithAllPositionsDestroyedHasZeroBounds(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pts := []grid.Point{grid.P(-3, 7), grid.P(12, -9)}
+	for _, pt := range pts {
+		space.At(pt).Create()
+	}
+
+	// when
+	for _, pt := range pts {
+		space.At(pt).Destroy()
+	}
+
+	// then
+	assert.That(space.Min() == grid.Point{}, t.Errorf, "got min %#v - want %#v", space.Min(), grid.Point{})
+	assert.That(space.Max() == grid.Point{}, t.Errorf, "got max %#v - want %#v", space.Max(), grid.Point{})
+	assert.That(space.Size() == 0, t.Errorf, "got size %d - want %d", space.Size(), 0)
+}
+
+func TestPositionsAroundChunkBordersAreDistinct(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	var pts []grid.Point
+	for row := -33; row <= 33; row += 11 {
+		for column := -17; column <= 17; column++ {
+			pts = append(pts, grid.P(row, column))
+		}
+	}
+
+	// when
+	for _, pt := range pts {
+		space.At(pt).Take(grid.DummyTaker())
+		space.At(pt).Create()
+	}
+
+	// then
+	assert.That(space.Size() == len(pts), t.Errorf, "got size %d - want %d", space.Size(), len(pts))
+	assert.That(len(space.Positions()) == len(pts), t.Errorf, "got %d positions - want %d", len(space.Positions()), len(pts))
+	assert.That(len(space.TakenPositions()) == 0, t.Errorf, "got %d taken positions - want none", len(space.TakenPositions()))
+	assert.That(!space.At(grid.P(-34, 0)).Exists(), t.Errorf, "position %#v exists", grid.P(-34, 0))
+	assert.That(!space.At(grid.P(0, -18)).Exists(), t.Errorf, "position %#v exists", grid.P(0, -18))
+}
+
+func TestRecreatedPositionIsNotTaken(t *testing.T) {
+	// given
+	space := grid.NewSpace()
+	pos := space.At(grid.P(-1, -1))
+	pos.Create()
+	pos.Take(grid.DummyTaker())
+	pos.Free()
+	pos.Destroy()
+
+	// when
+	pos.Create()
+
+	// then
+	assert.That(!pos.Taken(), t.Errorf, "the position should not be taken")
+}
+
 func setUpActionTakingPosition() (actions.Action, grid.Position, *RecordingSpaceTaker) {
 	space := grid.NewSpace()
 	pos := space.At(grid.P(13, 25))
@@ -797,6 +953,12 @@ func setUpActionTakingPosition() (actions.Action, grid.Position, *RecordingSpace
 	return action, pos, &taker
 }
 
+func ownedTaker(owner any) *grid.OnePosTaker {
+	taker := &grid.OnePosTaker{}
+	taker.SetOwner(owner)
+	return taker
+}
+
 type RecordingSpaceTaker struct {
 	Calls []RecordedSpaceTakerCall
 }
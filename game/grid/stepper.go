@@ -0,0 +1,50 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/actions"
+)
+
+// A _Stepper drives a placement one step at a time, for the actions that work out where to step next as they go.
+type _Stepper struct {
+	placement *HeadedPlacement
+	step      actions.Action
+}
+
+// advance runs the step in progress and has the placement finish a step it is in the middle of.
+//
+// It reports whether the placement is ready for the next step and how much time is left once it is.
+// Blocked says whether the last step got interrupted, which means someone else got to the position first.
+func (s *_Stepper) advance(atMost time.Duration) (ready bool, timeLeft time.Duration, blocked bool) {
+	for {
+		if s.step != nil {
+			status := s.step.Run(atMost)
+			if !status.Done() && !status.Interrupted() {
+				return false, 0, false
+			}
+			s.step = nil
+			atMost = status.TimeLeft()
+			blocked = blocked || status.Interrupted()
+		}
+
+		if !s.placement.Headed() {
+			return true, atMost, blocked
+		}
+		s.step = s.placement.FinishStep()
+	}
+}
+
+// moveTo makes the next step one onto dst, taking dt.
+func (s *_Stepper) moveTo(dst Position, dt time.Duration) {
+	s.step = s.placement.MoveTo(dst, dt)
+}
+
+// wait makes the next step one that keeps the placement where it is for dt.
+func (s *_Stepper) wait(dt time.Duration) {
+	s.step = actions.Wait(dt)
+}
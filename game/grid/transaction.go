@@ -0,0 +1,232 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionConflict is what a commit fails with when the space changed so that a staged change cannot be made anymore.
+func ErrTransactionConflict() error { return errTransactionConflict }
+
+// ErrTransactionFinished is what a commit fails with when the transaction was already committed or rolled back.
+func ErrTransactionFinished() error { return errTransactionFinished }
+
+var (
+	errTransactionConflict = errors.New("transaction conflict")
+	errTransactionFinished = errors.New("transaction finished")
+)
+
+// A Transaction stages changes to a space and then makes all of them at once, or none at all.
+//
+// Each change is checked as it is staged, against the space as it would be with the changes staged before.
+// A change that cannot be made is not staged.
+// The space itself stays as it is until the transaction is committed.
+type Transaction struct {
+	space    *Space
+	staged   []_SpaceChange
+	view     _SpaceView
+	finished bool
+}
+
+// NewTransaction starts a transaction that changes the space.
+func NewTransaction(space *Space) *Transaction {
+	return &Transaction{
+		space: space,
+		view:  newSpaceView(space),
+	}
+}
+
+// Exists says whether the position at pt would exist if the transaction was committed now.
+func (tx *Transaction) Exists(pt Point) bool { return tx.view.state(pt).exists }
+
+// Taker is what would take the position at pt if the transaction was committed now.
+func (tx *Transaction) Taker(pt Point) SpaceTaker { return tx.view.state(pt).taker }
+
+// Create stages creating the position at pt.
+// Like Position.Create, it fails when the position exists.
+func (tx *Transaction) Create(pt Point) bool {
+	return tx.stage(_SpaceChange{kind: _Create, at: pt})
+}
+
+// Destroy stages destroying the position at pt.
+// Like Position.Destroy, it fails when the position does not exist or is taken.
+func (tx *Transaction) Destroy(pt Point) bool {
+	return tx.stage(_SpaceChange{kind: _Destroy, at: pt})
+}
+
+// Take stages taking the position at pt by the taker.
+// Like Position.Take, it fails when the position does not exist or is taken.
+func (tx *Transaction) Take(pt Point, taker SpaceTaker) bool {
+	return tx.stage(_SpaceChange{kind: _Take, at: pt, taker: taker})
+}
+
+// Free stages freeing the position at pt.
+// Like Position.Free, it fails when the position is not taken.
+func (tx *Transaction) Free(pt Point) bool {
+	return tx.stage(_SpaceChange{kind: _Free, at: pt})
+}
+
+func (tx *Transaction) stage(change _SpaceChange) bool {
+	if tx.finished || !tx.view.apply(&change) {
+		return false
+	}
+	tx.staged = append(tx.staged, change)
+	return true
+}
+
+// Commit makes all the staged changes.
+//
+// It checks the staged changes again first, since the space might have changed after they were staged.
+// If any of them cannot be made anymore, it fails with ErrTransactionConflict and leaves the space as it is.
+//
+// Space takers are notified the same way Position's methods do, in the order the changes were staged.
+// That only happens after all the changes are made, so they see the space as the transaction left it.
+// Each notification is followed by the event for the change, like the ones Space.Subscribe passes on.
+//
+// Once Commit is called the transaction is finished, whether it succeeds or not.
+func (tx *Transaction) Commit() error {
+	if tx.finished {
+		return ErrTransactionFinished()
+	}
+	tx.finished = true
+
+	view := newSpaceView(tx.space)
+	changes := make([]_SpaceChange, len(tx.staged))
+	for i, change := range tx.staged {
+		if !view.apply(&change) {
+			return fmt.Errorf("cannot %s: %w", change, ErrTransactionConflict())
+		}
+		changes[i] = change
+	}
+
+	view.write()
+	for _, change := range changes {
+		change.notify(tx.space.At(change.at))
+		tx.space.emit(change.event())
+	}
+	return nil
+}
+
+// Rollback drops all the staged changes and finishes the transaction.
+// The space is left as it is.
+func (tx *Transaction) Rollback() {
+	tx.finished = true
+	tx.staged = nil
+	tx.view = newSpaceView(tx.space)
+}
+
+type _SpaceChangeKind int
+
+const (
+	_Create _SpaceChangeKind = iota
+	_Destroy
+	_Take
+	_Free
+)
+
+type _SpaceChange struct {
+	kind  _SpaceChangeKind
+	at    Point
+	taker SpaceTaker
+}
+
+func (change _SpaceChange) notify(pos Position) {
+	switch change.kind {
+	case _Take:
+		change.taker.LetOnto(pos)
+	case _Free:
+		change.taker.ForceOff(pos)
+	}
+}
+
+func (change _SpaceChange) event() Event {
+	kinds := [...]EventKind{
+		_Create:  PositionCreated,
+		_Destroy: PositionDestroyed,
+		_Take:    PositionTaken,
+		_Free:    PositionFreed,
+	}
+	return Event{Kind: kinds[change.kind], At: change.at, Taker: change.taker}
+}
+
+func (change _SpaceChange) String() string {
+	switch change.kind {
+	case _Create:
+		return fmt.Sprintf("create %v", change.at)
+	case _Destroy:
+		return fmt.Sprintf("destroy %v", change.at)
+	case _Take:
+		return fmt.Sprintf("take %v", change.at)
+	default:
+		return fmt.Sprintf("free %v", change.at)
+	}
+}
+
+// A _SpaceView is a space with some changes made on top of it, without changing the space itself.
+type _SpaceView struct {
+	space   *Space
+	changed map[Point]_PositionState
+}
+
+type _PositionState struct {
+	exists bool
+	taker  SpaceTaker
+}
+
+func newSpaceView(space *Space) _SpaceView {
+	return _SpaceView{space: space, changed: map[Point]_PositionState{}}
+}
+
+func (view *_SpaceView) state(pt Point) _PositionState {
+	if state, ok := view.changed[pt]; ok {
+		return state
+	}
+	taker, exists := view.space.lookup(pt)
+	return _PositionState{exists, taker}
+}
+
+// apply makes the change in the view, if it can be made.
+// For a change that frees a position, it fills in the taker that gets forced off.
+func (view *_SpaceView) apply(change *_SpaceChange) bool {
+	state := view.state(change.at)
+	switch change.kind {
+	case _Create:
+		if state.exists {
+			return false
+		}
+		state.exists = true
+	case _Destroy:
+		if !state.exists || state.taker != nil {
+			return false
+		}
+		state.exists = false
+	case _Take:
+		if !state.exists || state.taker != nil || change.taker == nil {
+			return false
+		}
+		state.taker = change.taker
+	case _Free:
+		if state.taker == nil {
+			return false
+		}
+		change.taker, state.taker = state.taker, nil
+	}
+	view.changed[change.at] = state
+	return true
+}
+
+// write makes the changes in the underlying space.
+func (view *_SpaceView) write() {
+	for pt, state := range view.changed {
+		if state.exists {
+			view.space.create(pt)
+			view.space.setTaker(pt, state.taker)
+		} else {
+			view.space.destroy(pt)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package grid_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/grid"
+)
+
+func TestTransactionCannotStageImpossibleChanges(t *testing.T) {
+	kases := map[string]func(tx *grid.Transaction) bool{
+		"CreateExisting":   func(tx *grid.Transaction) bool { return tx.Create(grid.P(0, 0)) },
+		"DestroyMissing":   func(tx *grid.Transaction) bool { return tx.Destroy(grid.P(5, 5)) },
+		"DestroyTaken":     func(tx *grid.Transaction) bool { return tx.Destroy(grid.P(0, 1)) },
+		"TakeMissing":      func(tx *grid.Transaction) bool { return tx.Take(grid.P(5, 5), grid.DummyTaker()) },
+		"TakeTaken":        func(tx *grid.Transaction) bool { return tx.Take(grid.P(0, 1), grid.DummyTaker()) },
+		"TakeWithNilTaker": func(tx *grid.Transaction) bool { return tx.Take(grid.P(0, 0), nil) },
+		"FreeFree":         func(tx *grid.Transaction) bool { return tx.Free(grid.P(0, 0)) },
+		"FreeMissing":      func(tx *grid.Transaction) bool { return tx.Free(grid.P(5, 5)) },
+		"TakeStagedTaken": func(tx *grid.Transaction) bool {
+			tx.Take(grid.P(0, 0), grid.DummyTaker())
+			return tx.Take(grid.P(0, 0), grid.DummyTaker())
+		},
+		"TakeStagedDestroy": func(tx *grid.Transaction) bool {
+			tx.Destroy(grid.P(0, 0))
+			return tx.Take(grid.P(0, 0), grid.DummyTaker())
+		},
+		"CreateStagedCreate": func(tx *grid.Transaction) bool { tx.Create(grid.P(5, 5)); return tx.Create(grid.P(5, 5)) },
+		"FreeStagedFree":     func(tx *grid.Transaction) bool { tx.Free(grid.P(0, 1)); return tx.Free(grid.P(0, 1)) },
+		"AfterRollback":      func(tx *grid.Transaction) bool { tx.Rollback(); return tx.Create(grid.P(5, 5)) },
+		"AfterCommit":        func(tx *grid.Transaction) bool { _ = tx.Commit(); return tx.Create(grid.P(5, 5)) },
+	}
+
+	for name, stage := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(2)
+			space.At(grid.P(0, 1)).Take(grid.DummyTaker())
+			tx := grid.NewTransaction(space)
+
+			// when
+			ok := stage(tx)
+
+			// then
+			assert.That(!ok, t.Errorf, "staging should fail")
+		})
+	}
+}
+
+func TestTransactionDoesNotChangeTheSpaceBeforeItIsCommitted(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	tx := grid.NewTransaction(space)
+
+	// when
+	tx.Take(grid.P(0, 0), grid.DummyTaker())
+	tx.Destroy(grid.P(0, 1))
+	tx.Create(grid.P(0, 2))
+
+	// then
+	assert.That(!space.At(grid.P(0, 0)).Taken(), t.Errorf, "position %v is taken", grid.P(0, 0))
+	assert.That(space.At(grid.P(0, 1)).Exists(), t.Errorf, "position %v does not exist", grid.P(0, 1))
+	assert.That(!space.At(grid.P(0, 2)).Exists(), t.Errorf, "position %v exists", grid.P(0, 2))
+}
+
+func TestTransactionSeesTheChangesItStaged(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	taker := &RecordingSpaceTaker{}
+	tx := grid.NewTransaction(space)
+
+	// when
+	tx.Create(grid.P(0, 2))
+	tx.Take(grid.P(0, 2), taker)
+	tx.Destroy(grid.P(0, 0))
+
+	// then
+	assert.That(tx.Exists(grid.P(0, 2)), t.Errorf, "position %v does not exist", grid.P(0, 2))
+	assert.That(tx.Taker(grid.P(0, 2)) == taker, t.Errorf, "position %v is taken by %v, want %v", grid.P(0, 2), tx.Taker(grid.P(0, 2)), taker)
+	assert.That(!tx.Exists(grid.P(0, 0)), t.Errorf, "position %v exists", grid.P(0, 0))
+}
+
+func TestCommittedTransactionChangesTheSpace(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	space.At(grid.P(0, 1)).Take(grid.DummyTaker())
+
+	taker := &RecordingSpaceTaker{}
+	tx := grid.NewTransaction(space)
+	tx.Create(grid.P(0, 2))
+	tx.Take(grid.P(0, 2), taker)
+	tx.Free(grid.P(0, 1))
+	tx.Destroy(grid.P(0, 0))
+
+	// when
+	err := tx.Commit()
+
+	// then
+	assert.That(err == nil, t.Fatalf, "unexpected error: %v", err)
+	assert.That(space.At(grid.P(0, 2)).Taker() == taker, t.Errorf, "position %v is not taken by the taker", grid.P(0, 2))
+	assert.That(!space.At(grid.P(0, 1)).Taken(), t.Errorf, "position %v is taken", grid.P(0, 1))
+	assert.That(!space.At(grid.P(0, 0)).Exists(), t.Errorf, "position %v exists", grid.P(0, 0))
+	assert.That(space.Min() == grid.P(0, 1), t.Errorf, "got min %v, want %v", space.Min(), grid.P(0, 1))
+	assert.That(space.Max() == grid.P(0, 2), t.Errorf, "got max %v, want %v", space.Max(), grid.P(0, 2))
+}
+
+func TestCommittedTransactionNotifiesTheTakers(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	leaving, arriving := &RecordingSpaceTaker{}, &RecordingSpaceTaker{}
+	space.At(grid.P(0, 0)).Take(leaving)
+	leaving.Calls = nil
+
+	tx := grid.NewTransaction(space)
+	tx.Free(grid.P(0, 0))
+	tx.Take(grid.P(0, 0), arriving)
+
+	// when
+	err := tx.Commit()
+
+	// then
+	assert.That(err == nil, t.Fatalf, "unexpected error: %v", err)
+	wantLeaving := []RecordedSpaceTakerCall{{Method: "ForceOff", Position: space.At(grid.P(0, 0))}}
+	wantArriving := []RecordedSpaceTakerCall{{Method: "LetOnto", Position: space.At(grid.P(0, 0))}}
+	assert.That(callsEqual(leaving.Calls, wantLeaving), t.Errorf, "got calls %v, want %v", leaving.Calls, wantLeaving)
+	assert.That(callsEqual(arriving.Calls, wantArriving), t.Errorf, "got calls %v, want %v", arriving.Calls, wantArriving)
+}
+
+func TestTransactionSwapsTwoTakers(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	first, second := &grid.OnePosTaker{}, &grid.OnePosTaker{}
+	space.At(grid.P(0, 0)).Take(first)
+	space.At(grid.P(0, 1)).Take(second)
+
+	tx := grid.NewTransaction(space)
+	tx.Free(grid.P(0, 0))
+	tx.Free(grid.P(0, 1))
+	tx.Take(grid.P(0, 0), second)
+	tx.Take(grid.P(0, 1), first)
+
+	// when
+	err := tx.Commit()
+
+	// then
+	assert.That(err == nil, t.Fatalf, "unexpected error: %v", err)
+	assert.That(space.At(grid.P(0, 0)).Taker() == second, t.Errorf, "position %v is not taken by the second taker", grid.P(0, 0))
+	assert.That(space.At(grid.P(0, 1)).Taker() == first, t.Errorf, "position %v is not taken by the first taker", grid.P(0, 1))
+	assert.That(first.AtPoint() == grid.P(0, 1), t.Errorf, "first taker is at %v, want %v", first.AtPoint(), grid.P(0, 1))
+	assert.That(second.AtPoint() == grid.P(0, 0), t.Errorf, "second taker is at %v, want %v", second.AtPoint(), grid.P(0, 0))
+}
+
+func TestTransactionConflictsWithChangesMadeAfterStaging(t *testing.T) {
+	kases := map[string]struct {
+		Stage  func(tx *grid.Transaction)
+		Change func(space *grid.Space)
+	}{
+		"CreatedInTheMeantime": {
+			Stage:  func(tx *grid.Transaction) { tx.Create(grid.P(0, 2)) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 2)).Create() },
+		},
+		"DestroyedInTheMeantime": {
+			Stage:  func(tx *grid.Transaction) { tx.Destroy(grid.P(0, 0)) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 0)).Destroy() },
+		},
+		"TakenBeforeDestroying": {
+			Stage:  func(tx *grid.Transaction) { tx.Destroy(grid.P(0, 0)) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 0)).Take(grid.DummyTaker()) },
+		},
+		"TakenBeforeTaking": {
+			Stage:  func(tx *grid.Transaction) { tx.Take(grid.P(0, 0), &RecordingSpaceTaker{}) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 0)).Take(grid.DummyTaker()) },
+		},
+		"DestroyedBeforeTaking": {
+			Stage:  func(tx *grid.Transaction) { tx.Take(grid.P(0, 0), &RecordingSpaceTaker{}) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 0)).Destroy() },
+		},
+		"FreedBeforeFreeing": {
+			Stage:  func(tx *grid.Transaction) { tx.Free(grid.P(0, 1)) },
+			Change: func(space *grid.Space) { space.At(grid.P(0, 1)).Free() },
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(2)
+			space.At(grid.P(0, 1)).Take(grid.DummyTaker())
+
+			tx := grid.NewTransaction(space)
+			// a change that would succeed on its own, staged first
+			tx.Create(grid.P(0, 5))
+			kase.Stage(tx)
+			kase.Change(space)
+
+			// when
+			err := tx.Commit()
+
+			// then
+			assert.That(errors.Is(err, grid.ErrTransactionConflict()), t.Errorf, "got error %v, want %v", err, grid.ErrTransactionConflict())
+			assert.That(!space.At(grid.P(0, 5)).Exists(), t.Errorf, "a change was made despite the conflict")
+		})
+	}
+}
+
+func TestFailedCommitDoesNotNotifyTakers(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	taker := &RecordingSpaceTaker{}
+
+	tx := grid.NewTransaction(space)
+	tx.Take(grid.P(0, 0), taker)
+	tx.Take(grid.P(0, 1), taker)
+	space.At(grid.P(0, 1)).Take(grid.DummyTaker())
+
+	// when
+	err := tx.Commit()
+
+	// then
+	assert.That(err != nil, t.Errorf, "commit should fail")
+	assert.That(!space.At(grid.P(0, 0)).Taken(), t.Errorf, "position %v is taken", grid.P(0, 0))
+	assert.That(len(taker.Calls) == 0, t.Errorf, "got calls %v, want none", taker.Calls)
+}
+
+func TestRolledBackTransactionDoesNotChangeTheSpace(t *testing.T) {
+	// given
+	space := lineSpace(2)
+	taker := &RecordingSpaceTaker{}
+
+	tx := grid.NewTransaction(space)
+	tx.Take(grid.P(0, 0), taker)
+	tx.Destroy(grid.P(0, 1))
+
+	// when
+	tx.Rollback()
+
+	// then
+	assert.That(!space.At(grid.P(0, 0)).Taken(), t.Errorf, "position %v is taken", grid.P(0, 0))
+	assert.That(space.At(grid.P(0, 1)).Exists(), t.Errorf, "position %v does not exist", grid.P(0, 1))
+	assert.That(len(taker.Calls) == 0, t.Errorf, "got calls %v, want none", taker.Calls)
+	assert.That(!tx.Exists(grid.P(0, 5)), t.Errorf, "the rolled back transaction sees a position that does not exist")
+}
+
+func TestFinishedTransactionCannotBeCommitted(t *testing.T) {
+	kases := map[string]func(space *grid.Space, tx *grid.Transaction){
+		"Committed":  func(_ *grid.Space, tx *grid.Transaction) { _ = tx.Commit() },
+		"RolledBack": func(_ *grid.Space, tx *grid.Transaction) { tx.Rollback() },
+		"FailedToCommit": func(space *grid.Space, tx *grid.Transaction) {
+			tx.Create(grid.P(0, 5))
+			space.At(grid.P(0, 5)).Create()
+			_ = tx.Commit()
+		},
+	}
+
+	for name, finish := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := lineSpace(2)
+			tx := grid.NewTransaction(space)
+			finish(space, tx)
+
+			// when
+			err := tx.Commit()
+
+			// then
+			assert.That(errors.Is(err, grid.ErrTransactionFinished()), t.Errorf, "got error %v, want %v", err, grid.ErrTransactionFinished())
+		})
+	}
+}
+
+func callsEqual(a, b []RecordedSpaceTakerCall) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package random provides the game with randomness that can be repeated exactly.
+//
+// All of it comes from a single seed, split into independent streams - one for each part of the game that needs it.
+// Drawing more numbers from one stream does not change what the others give, so adding a roll to combat does not change the maps that get generated.
+package random
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// A StreamID names one of the independent streams of random numbers.
+type StreamID string
+
+// The streams the game uses.
+const (
+	Combat StreamID = "combat"
+	AI     StreamID = "ai"
+	MapGen StreamID = "mapgen"
+)
+
+// A Service hands out streams of random numbers derived from its seed.
+type Service struct {
+	seed    int64
+	sources map[StreamID]*_Source
+	streams map[StreamID]*rand.Rand
+}
+
+// State is everything needed to restore a service to where it was.
+// It is meant to be stored in save games.
+//
+// Bytes left over from calling Read on a stream are not a part of the state.
+type State struct {
+	Seed int64
+	// Streams are the states of the streams that were used.
+	Streams map[StreamID]uint64
+}
+
+// New creates a service with the given seed.
+// Services with the same seed hand out the same numbers.
+func New(seed int64) *Service {
+	return &Service{
+		seed:    seed,
+		sources: map[StreamID]*_Source{},
+		streams: map[StreamID]*rand.Rand{},
+	}
+}
+
+// Restore creates a service that continues from the state.
+func Restore(state State) *Service {
+	s := New(state.Seed)
+	for id, at := range state.Streams {
+		s.stream(id, at)
+	}
+	return s
+}
+
+// Seed the service was created with.
+func (s *Service) Seed() int64 { return s.seed }
+
+// Stream is the stream of random numbers with the given ID.
+// Asking for the same stream again gives the same generator, which continues where it left off.
+func (s *Service) Stream(id StreamID) *rand.Rand {
+	if stream, ok := s.streams[id]; ok {
+		return stream
+	}
+	return s.stream(id, derive(s.seed, id))
+}
+
+// State is a snapshot of where the service is.
+// Drawing more numbers later does not change it.
+func (s *Service) State() State {
+	state := State{Seed: s.seed, Streams: make(map[StreamID]uint64, len(s.sources))}
+	for id, src := range s.sources {
+		state.Streams[id] = src.state
+	}
+	return state
+}
+
+func (s *Service) stream(id StreamID, at uint64) *rand.Rand {
+	src := &_Source{state: at}
+	s.sources[id] = src
+	s.streams[id] = rand.New(src)
+	return s.streams[id]
+}
+
+// derive picks the starting state of a stream, so that streams with different IDs are unrelated.
+func derive(seed int64, id StreamID) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	src := _Source{state: uint64(seed) ^ h.Sum64()}
+	return src.Uint64()
+}
+
+// _Source is a SplitMix64 generator.
+// Its whole state is a single number, which makes it easy to save.
+type _Source struct {
+	state uint64
+}
+
+var _ rand.Source64 = &_Source{}
+
+func (src *_Source) Seed(seed int64) { src.state = uint64(seed) }
+
+func (src *_Source) Int63() int64 { return int64(src.Uint64() >> 1) }
+
+func (src *_Source) Uint64() uint64 {
+	src.state += 0x9e3779b97f4a7c15
+	z := src.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package random_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/szabba/assert"
+
+	"github.com/szabba/tob-cob/game/random"
+)
+
+func TestServicesWithTheSameSeedAgree(t *testing.T) {
+	// given
+	first, second := random.New(42), random.New(42)
+
+	// when
+	a, b := draw(first.Stream(random.Combat), 100), draw(second.Stream(random.Combat), 100)
+
+	// then
+	assert.That(intsEqual(a, b), t.Errorf, "got %v and %v", a, b)
+}
+
+func TestServicesWithDifferentSeedsDiffer(t *testing.T) {
+	// given
+	first, second := random.New(1), random.New(2)
+
+	// when
+	a, b := draw(first.Stream(random.Combat), 100), draw(second.Stream(random.Combat), 100)
+
+	// then
+	assert.That(!intsEqual(a, b), t.Errorf, "both gave %v", a)
+}
+
+func TestStreamsAreIndependent(t *testing.T) {
+	// given
+	quiet, busy := random.New(42), random.New(42)
+	draw(busy.Stream(random.Combat), 1000)
+
+	// when
+	a, b := draw(quiet.Stream(random.MapGen), 100), draw(busy.Stream(random.MapGen), 100)
+
+	// then
+	assert.That(intsEqual(a, b), t.Errorf, "using the combat stream changed the map generation one")
+}
+
+func TestStreamsDiffer(t *testing.T) {
+	// given
+	service := random.New(42)
+
+	// when
+	a, b := draw(service.Stream(random.Combat), 100), draw(service.Stream(random.AI), 100)
+
+	// then
+	assert.That(!intsEqual(a, b), t.Errorf, "both gave %v", a)
+}
+
+func TestStreamContinuesWhereItLeftOff(t *testing.T) {
+	// given
+	service, fresh := random.New(42), random.New(42)
+	first := draw(service.Stream(random.AI), 10)
+
+	// when
+	second := draw(service.Stream(random.AI), 10)
+
+	// then
+	want := draw(fresh.Stream(random.AI), 20)
+	got := append(first, second...)
+	assert.That(intsEqual(got, want), t.Errorf, "got %v, want %v", got, want)
+}
+
+func TestRestoredServiceContinuesWhereStateWasTaken(t *testing.T) {
+	// given
+	service := random.New(42)
+	draw(service.Stream(random.Combat), 37)
+	draw(service.Stream(random.AI), 5)
+	state := service.State()
+
+	// when
+	restored := random.Restore(state)
+
+	// then
+	for _, id := range []random.StreamID{random.Combat, random.AI, random.MapGen} {
+		a, b := draw(service.Stream(id), 100), draw(restored.Stream(id), 100)
+		assert.That(intsEqual(a, b), t.Errorf, "stream %q differs after restoring: %v and %v", id, a, b)
+	}
+	assert.That(restored.Seed() == 42, t.Errorf, "got seed %d, want %d", restored.Seed(), 42)
+}
+
+func TestStateIsASnapshot(t *testing.T) {
+	// given
+	service := random.New(42)
+	draw(service.Stream(random.Combat), 3)
+	state := service.State()
+	before := state.Streams[random.Combat]
+
+	// when
+	draw(service.Stream(random.Combat), 3)
+
+	// then
+	assert.That(state.Streams[random.Combat] == before, t.Errorf, "the state changed after it was taken")
+}
+
+func draw(rng *rand.Rand, n int) []int {
+	got := make([]int, n)
+	for i := range got {
+		got[i] = rng.Intn(1000)
+	}
+	return got
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -15,8 +15,9 @@ require (
 	github.com/jezek/xgb v1.1.0 // indirect
 	github.com/szabba/assert/v2 v2.0.0
 	golang.org/x/exp/shiny v0.0.0-20230321023759-10a507213a29 // indirect
-	golang.org/x/image v0.6.0 // indirect
+	golang.org/x/image v0.6.0
 	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
 )
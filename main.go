@@ -10,17 +10,20 @@ import (
 	"image/color"
 	_ "image/png"
 	"io/fs"
-	"math"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/exp/slog"
 
-	"github.com/szabba/tob-cob/game/actions"
+	"github.com/szabba/tob-cob/game/ai"
+	"github.com/szabba/tob-cob/game/behaviour"
+	"github.com/szabba/tob-cob/game/entity"
 	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/game/random"
 
 	"github.com/szabba/tob-cob/run"
 	"github.com/szabba/tob-cob/run/ebitenginerun"
@@ -30,9 +33,24 @@ import (
 	"github.com/szabba/tob-cob/ui/draw"
 	"github.com/szabba/tob-cob/ui/geometry"
 	"github.com/szabba/tob-cob/ui/input"
+	"github.com/szabba/tob-cob/ui/text"
 )
 
-var _Black = color.Gray{Y: 0}
+var (
+	_Black = color.Gray{Y: 0}
+	_White = color.Gray{Y: 0xff}
+)
+
+const (
+	_PlayerFaction entity.Faction = "player"
+	_EnemyFaction  entity.Faction = "enemy"
+)
+
+// How long units wait for the way to clear before looking for another one, and how many times they look before giving up.
+const (
+	_NavigationPatience   = time.Second / 2
+	_NavigationMaxRepaths = 3
+)
 
 func main() {
 	configLogger()
@@ -103,38 +121,73 @@ func mainFallible() error {
 
 	assetFs, _ := fs.Sub(os.DirFS(execDir), "assets")
 
+	seed := seedFromEnv()
+
 	load := assets.Load(assetFs, func(loaded _Assets) run.Game {
-		return newGame(loaded)
+		return newGame(loaded, seed)
 	})
 
 	return ebitenginerun.Game(load, config)
 }
 
+// seedFromEnv is the seed for the game's randomness.
+// It comes from SEED when that is set, so that a session can be repeated, and from the clock otherwise.
+func seedFromEnv() int64 {
+	envSeed := os.Getenv("SEED")
+
+	seed, err := strconv.ParseInt(envSeed, 10, 64)
+	if err != nil {
+		if envSeed != "" {
+			slog.Warn(
+				"improper SEED",
+				slog.Group("env", slog.String("SEED", envSeed)),
+				slog.String("err", err.Error()))
+		}
+		seed = time.Now().UnixNano()
+	}
+
+	slog.Info(
+		"seeded randomness",
+		slog.Int64("seed", seed))
+	return seed
+}
+
 type _Game struct {
-	cursor   ui.Sprite
-	humanoid ui.Sprite
+	cursor    ui.Sprite
+	debugFont text.Font
 
-	space      *grid.Space
-	placements []grid.HeadedPlacement
-	actions    []actions.Action
+	rng *random.Service
+
+	space *grid.Space
+	paths *grid.HierarchicalPathFinder
+	crowd *grid.Crowd
+	world *entity.World
+	brain *ai.Brain
 
 	grid    ui.GridDimensions
 	outline ui.GridOutline
+	preview *ui.PathPreview
 	cam     ui.Camera
 	camCont *ui.CameraController
+
+	selection ui.Selection
+	selCont   *ui.SelectionController
 }
 
 type _Assets struct {
 	Cursor   draw.Image `asset:"cursor.png"`
 	Humanoid draw.Image `asset:"humanoid.png"`
 	Tile     draw.Image `asset:"tile.png"`
+
+	EnemyBehaviour behaviour.Spec `asset:"behaviour/enemy.json"`
 }
 
-func newGame(loaded _Assets) *_Game {
+func newGame(loaded _Assets, seed int64) *_Game {
 	g := new(_Game)
+	g.rng = random.New(seed)
 
-	g.humanoid = ui.NewSprite(loaded.Humanoid, ui.AnchorSouth())
 	g.cursor = ui.NewSprite(loaded.Cursor, ui.AnchorNorthWest())
+	g.debugFont = text.Basic()
 
 	g.space = grid.NewSpace()
 	for x := -10; x <= 10; x++ {
@@ -143,21 +196,43 @@ func newGame(loaded _Assets) *_Game {
 		}
 	}
 
-	g.placements = make([]grid.HeadedPlacement, 2)
-	g.placements[0].Place(g.space.At(grid.P(1, 1)))
-	g.placements[1].Place(g.space.At(grid.P(0, 0)))
-	g.actions = []actions.Action{actions.NoAction(), actions.NoAction()}
-
-	g.grid = ui.GridDimensions{
-		CellWidth:  30,
-		CellHeight: 30,
+	g.paths = grid.NewHierarchicalPathFinder(g.space, grid.DefaultClusterSize)
+	g.crowd = grid.NewCrowd()
+	g.world = entity.NewWorld(g.space)
+	g.world.Relations().Set(_PlayerFaction, _EnemyFaction, entity.Hostile)
+	humanoid := ui.StillAnimationSet(loaded.Humanoid)
+	for _, pt := range []grid.Point{grid.P(1, 1), grid.P(0, 0)} {
+		unit, _ := g.world.Spawn(pt)
+		unit.Faction = _PlayerFaction
+		entity.Set(unit, ui.NewAnimationPlayer(humanoid))
 	}
-	g.outline = ui.GridOutline{
-		Sprite:  ui.NewSprite(loaded.Tile, ui.AnchorCenter()),
-		Space:   g.space,
-		Dims:    g.grid,
-		Margins: ui.Margins{X: 2.5, Y: 2.5},
+	for _, pt := range []grid.Point{grid.P(8, 8), grid.P(8, -8)} {
+		unit, _ := g.world.Spawn(pt)
+		unit.Faction = _EnemyFaction
+		entity.Set(unit, ui.NewAnimationPlayer(humanoid))
 	}
+	g.brain = ai.NewBrain(g.world, g.paths.Through, g.rng)
+	for _, unit := range g.world.Faction(_EnemyFaction) {
+		tree, err := loaded.EnemyBehaviour.Build(g.brain.Library(unit))
+		if err != nil {
+			slog.Warn(
+				"cannot build enemy behaviour, falling back to considerations",
+				slog.Uint64("unit", uint64(unit.ID())),
+				slog.String("err", err.Error()))
+			continue
+		}
+		g.brain.Follow(unit, tree)
+	}
+
+	g.grid = gridDimensions()
+	g.outline = ui.NewGridOutline(
+		ui.NewSprite(loaded.Tile, ui.AnchorCenter()),
+		g.space,
+		g.grid,
+		ui.Margins{X: 2.5, Y: 2.5})
+
+	g.preview = ui.NewPathPreview(g.space, g.grid)
+	g.selCont = ui.NewSelectionController(&g.selection, g.grid)
 
 	g.cam = ui.NewCamera(geometry.V(0, 0))
 	g.camCont = ui.NewCamController(&g.cam)
@@ -165,6 +240,27 @@ func newGame(loaded _Assets) *_Game {
 	return g
 }
 
+func gridDimensions() ui.GridDimensions {
+	projection := strings.ToLower(strings.TrimSpace(os.Getenv("GRID_PROJECTION")))
+
+	switch projection {
+	case "isometric":
+		return ui.IsometricDimensions(30)
+	case "dimetric":
+		return ui.DimetricDimensions(30)
+	case "", "top-down":
+	default:
+		slog.Warn(
+			"improper GRID_PROJECTION",
+			slog.Group("env", slog.String("GRID_PROJECTION", projection)))
+	}
+
+	return ui.GridDimensions{
+		CellWidth:  30,
+		CellHeight: 30,
+	}
+}
+
 func (g *_Game) Draw(dst draw.Target, inSrc input.Source) {
 	dst.Clear(_Black)
 
@@ -173,22 +269,47 @@ func (g *_Game) Draw(dst draw.Target, inSrc input.Source) {
 	camMatrix := g.cam.Matrix(inSrc.Bounds())
 	dst.SetMatrix(camMatrix)
 	g.outline.Draw(dst)
+	g.preview.Draw(dst)
+	g.selection.Draw(dst, g.grid, ui.DefaultSelectionColor)
 
-	for _, placement := range g.placements {
-		matrix := placementTransform(g.outline, placement)
+	for _, unit := range g.world.All() {
+		anim, ok := entity.Get[*ui.AnimationPlayer](unit)
+		if !ok {
+			continue
+		}
+		matrix := placementTransform(g.outline, &unit.Placement)
 
-		sprite := g.humanoid.Transform(matrix)
+		sprite := anim.Sprite(ui.AnchorSouth()).Transform(matrix)
 		spriteGroup.Add(sprite)
 	}
 	spriteGroup.Draw()
 
 	dst.SetMatrix(geometry.Identity())
+	g.selCont.DrawBox(dst, ui.DefaultSelectionColor)
+	g.drawDebugInfo(dst, inSrc)
+
 	cursorM := geometry.Translation(inSrc.MousePosition())
 	g.cursor.
 		Transform(cursorM).
 		Draw()
 }
 
+func (g *_Game) drawDebugInfo(dst draw.Target, inSrc input.Source) {
+	underCursor := g.grid.UnderCursor(inSrc, g.cam)
+	info := fmt.Sprintf(
+		"cell: %d, %d\nzoom: %.2f",
+		underCursor.Row, underCursor.Column, g.cam.Zoom())
+	if unit, ok := g.world.At(underCursor); ok {
+		info += fmt.Sprintf("\nunit: %d (%s)", unit.ID(), unit.Faction)
+	}
+
+	layout := text.NewLayout(g.debugFont, info, text.Style{})
+
+	bounds := inSrc.Bounds()
+	at := geometry.V(bounds.Min.X+8, bounds.Max.Y-8)
+	layout.Draw(dst, geometry.Translation(at), ui.AnchorNorthWest().For(layout.Bounds()), _White)
+}
+
 func (g *_Game) Update(inSrc input.Source, dt time.Duration) (run.Game, error) {
 	if inSrc.JustPressed(input.MouseButtonLeft()) {
 		mouseAt := inSrc.MousePosition()
@@ -206,62 +327,113 @@ func (g *_Game) Update(inSrc input.Source, dt time.Duration) (run.Game, error) {
 		)
 	}
 
-	if inSrc.JustPressed(input.MouseButtonLeft()) && !g.placements[0].Headed() {
-		src := g.space.At(g.placements[0].AtPoint())
-		dst := g.space.At(g.grid.UnderCursor(inSrc, g.cam))
-		g.placements[0].Place(src)
-		path, _ := grid.NewPathFinder(g.space).FindPath(src, dst)
+	g.selection.Keep(g.commandable)
+	g.selCont.Process(inSrc, g.cam, g.playerPlacements())
 
-		if slog.Default().Enabled(nil, slog.LevelDebug) {
+	if inSrc.JustPressed(input.MouseButtonRight()) {
+		g.moveSelected(g.grid.UnderCursor(inSrc, g.cam))
+	}
 
-			asStr := fmt.Sprintf("%#v", path)
-			slog.Debug(
-				"found path",
-				slog.String("path", asStr))
-		}
+	if inSrc.JustPressed(input.KeyF()) {
+		g.toggleFollow()
+	}
+
+	g.cam.SetBounds(g.grid.Extent(g.space))
+	g.camCont.Process(inSrc, dt)
 
-		g.actions[0] = g.placements[0].FollowPath(path, time.Second/4)
+	if selected := g.selection.Selected(); len(selected) == 1 {
+		// The preview shows the way the unit would actually go when ordered to move.
+		if unit, ok := entity.Of(selected[0]); ok {
+			g.preview.FindPath = g.findPathFor(unit)
+		}
+		g.preview.Update(selected[0], inSrc, g.cam)
+	} else {
+		g.preview.Hide()
 	}
 
-	g.camCont.Process(inSrc)
+	g.brain.Update(dt, g.world.Faction(_EnemyFaction))
+	g.world.Update(dt)
 
-	for i, action := range g.actions {
-		g.actions[i] = runFor(action, dt)
+	for _, unit := range g.world.All() {
+		if anim, ok := entity.Get[*ui.AnimationPlayer](unit); ok {
+			anim.Update(&unit.Placement, dt)
+		}
 	}
 
 	return g, nil
 }
 
-func runFor(action actions.Action, dt time.Duration) actions.Action {
-	if action == nil {
-		return actions.NoAction()
+// playerPlacements are the placements of the units the player can select and give orders to.
+func (g *_Game) playerPlacements() []*grid.HeadedPlacement {
+	units := g.world.Faction(_PlayerFaction)
+	refs := make([]*grid.HeadedPlacement, len(units))
+	for i, unit := range units {
+		refs[i] = &unit.Placement
 	}
+	return refs
+}
 
-	status := action.Run(dt)
-
-	if status.Done() || status.Interrupted() {
-		return actions.NoAction()
+// commandable says whether the placement belongs to a unit the player still controls.
+func (g *_Game) commandable(placement *grid.HeadedPlacement) bool {
+	unit, ok := entity.Of(placement)
+	if !ok || unit.Faction != _PlayerFaction {
+		return false
 	}
-	return action
+	found, ok := g.world.Get(unit.ID())
+	return ok && found == unit
 }
 
-func placementTransform(outline ui.GridOutline, placement grid.HeadedPlacement) geometry.Mat {
-	src := placement.AtPoint()
-	grid := outline.Dims
-	bottom := geometry.V(0, -grid.CellHeight/2+math.Abs(outline.Margins.Y))
-	mat := grid.Matrix(src.Column, src.Row).Compose(geometry.Translation((bottom)))
-	if placement.Headed() {
-		dst := placement.Heading()
-		dstMatrix := grid.Matrix(dst.Column, dst.Row).Compose(geometry.Translation(bottom))
-		prog := placement.Progress()
-		// TODO: factor out mixing function?
-		for i := range [...]int{0, 1} {
-			for j := range [...]int{0, 1, 2} {
-				mat[i][j] = dstMatrix[i][j]*prog + mat[i][j]*(1-prog)
-			}
+// moveSelected sends the selected placements towards the target, each to a different cell.
+func (g *_Game) moveSelected(target grid.Point) {
+	assigned := grid.AssignDestinations(g.selection.Selected(), g.space.At(target))
+
+	for placement, dst := range assigned {
+		unit, ok := entity.Of(placement)
+		if !ok {
+			continue
 		}
+
+		slog.Debug(
+			"navigating",
+			slog.String("to", fmt.Sprintf("%#v", dst.AtPoint())))
+
+		unit.Actions.Replace(placement.Navigate(dst, grid.Navigation{
+			FindPath:   g.findPathFor(unit),
+			StepTime:   unit.Stats.StepTime,
+			Patience:   _NavigationPatience,
+			MaxRepaths: _NavigationMaxRepaths,
+			Crowd:      g.crowd,
+		}))
 	}
-	return mat
+}
+
+// findPathFor searches for the way the unit goes when ordered to move.
+// Units plan their way through the allies on the move, trading places with them when they meet.
+func (g *_Game) findPathFor(unit *entity.Entity) func(src, dst grid.Position) (grid.Path, bool) {
+	ally := g.world.PassableFor(unit)
+	return g.paths.Through(func(pos grid.Position) bool {
+		return ally(pos) && g.crowd.Navigating(pos)
+	})
+}
+
+func (g *_Game) toggleFollow() {
+	if g.camCont.Following() {
+		g.camCont.Unfollow()
+		return
+	}
+	if g.selection.Empty() {
+		return
+	}
+	followed := g.selection.Selected()[0]
+	g.camCont.Follow(func() geometry.Vec {
+		return g.grid.PlacementCenter(followed)
+	})
+}
+
+func placementTransform(outline ui.GridOutline, placement *grid.HeadedPlacement) geometry.Mat {
+	grid := outline.Dims
+	footing := grid.Footing(outline.Margins.Y)
+	return geometry.Translation(grid.PlacementCenter(placement).Add(footing))
 }
 
 func execDir() (string, error) {
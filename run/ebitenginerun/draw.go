@@ -7,6 +7,7 @@ package ebitenginerun
 import (
 	"image"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/szabba/tob-cob/ui/draw"
@@ -48,22 +49,75 @@ func (d _DrawImage) Bounds() geometry.Rect {
 	bounds := d.src.Bounds()
 
 	return geometry.R(
-		float64(bounds.Min.X),
-		float64(bounds.Min.Y),
+		0, 0,
 		float64(bounds.Dx()),
 		float64(bounds.Dy()),
 	)
 }
 
+func (d _DrawImage) SubImage(r geometry.Rect) draw.Image {
+	bounds := d.src.Bounds()
+
+	// The Y axis of the bounds points up, while that of the ebiten image points down.
+	sub := image.Rect(
+		bounds.Min.X+int(math.Round(r.Min.X)),
+		bounds.Max.Y-int(math.Round(r.Max.Y)),
+		bounds.Min.X+int(math.Round(r.Max.X)),
+		bounds.Max.Y-int(math.Round(r.Min.Y)),
+	)
+
+	src := d.src.SubImage(sub).(*ebiten.Image)
+	return _DrawImage{d.dst, src}
+}
+
 func (d _DrawImage) Draw(m geometry.Mat, anchor geometry.Vec) {
+	d.DrawWith(m, anchor, draw.DefaultOptions())
+}
+
+func (d _DrawImage) DrawWith(m geometry.Mat, anchor geometry.Vec, opts draw.Options) {
+	if r, ok := opts.Source(); ok {
+		sub := d.SubImage(r).(_DrawImage)
+		// The sub-image bounds start at the origin, so the anchor needs to follow.
+		sub.draw(m, anchor.Sub(r.Min), opts)
+		return
+	}
+	d.draw(m, anchor, opts)
+}
 
+// draw ignores the source rectangle in the options.
+func (d _DrawImage) draw(m geometry.Mat, anchor geometry.Vec, opts draw.Options) {
 	composed := d.dst.matrix.Compose(m).Compose(d.anchorOffset(anchor))
 
-	opts := &ebiten.DrawImageOptions{
-		GeoM: d.toGeoM(composed),
+	ebitenOpts := &ebiten.DrawImageOptions{
+		GeoM:  d.toGeoM(composed),
+		Blend: toBlend(opts.Blend()),
+	}
+
+	r, g, b, a := opts.ColorScale()
+	// Ebiten scales alpha-premultiplied colours.
+	ebitenOpts.ColorScale.Scale(float32(r*a), float32(g*a), float32(b*a), float32(a))
+
+	d.dst.dst.DrawImage(d.src, ebitenOpts)
+}
+
+func toBlend(b draw.Blend) ebiten.Blend {
+	switch b {
+	case draw.BlendAdd:
+		return ebiten.BlendLighter
+	case draw.BlendMultiply:
+		return _BlendMultiply
+	default:
+		return ebiten.BlendSourceOver
 	}
+}
 
-	d.dst.dst.DrawImage(d.src, opts)
+var _BlendMultiply = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorDestinationAlpha,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorOneMinusSourceAlpha,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOneMinusSourceAlpha,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
 }
 
 func (d _DrawImage) anchorOffset(anchor geometry.Vec) geometry.Mat {
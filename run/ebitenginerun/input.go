@@ -81,15 +81,26 @@ func (i _InputSource) MouseInsideWindow() bool {
 
 }
 
+func (_InputSource) Wheel() geometry.Vec {
+	x, y := ebiten.Wheel()
+	return geometry.V(x, y)
+}
+
 var mbMap = map[input.Button]ebiten.MouseButton{
-	input.MouseButtonLeft(): ebiten.MouseButtonLeft,
+	input.MouseButtonLeft():  ebiten.MouseButtonLeft,
+	input.MouseButtonRight(): ebiten.MouseButtonRight,
 }
 
 var keyMap = map[input.Button]ebiten.Key{
 	input.KeyF(): ebiten.KeyF,
 
+	input.KeyShift(): ebiten.KeyShift,
+
 	input.KeyLeft():  ebiten.KeyArrowLeft,
 	input.KeyUp():    ebiten.KeyArrowUp,
 	input.KeyRight(): ebiten.KeyArrowRight,
 	input.KeyDown():  ebiten.KeyArrowDown,
+
+	input.KeyEqual(): ebiten.KeyEqual,
+	input.KeyMinus(): ebiten.KeyMinus,
 }
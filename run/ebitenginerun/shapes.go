@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ebitenginerun
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+var _ draw.Shapes = &_DrawTarget{}
+
+func (d *_DrawTarget) FillRect(r geometry.Rect, c color.Color) {
+	d.fill(d.rectPath(r), c)
+}
+
+func (d *_DrawTarget) StrokeRect(r geometry.Rect, width float64, c color.Color) {
+	d.stroke(d.rectPath(r), width, c)
+}
+
+func (d *_DrawTarget) Line(from, to geometry.Vec, width float64, c color.Color) {
+	d.Polyline([]geometry.Vec{from, to}, width, c)
+}
+
+func (d *_DrawTarget) Polyline(points []geometry.Vec, width float64, c color.Color) {
+	if len(points) < 2 {
+		return
+	}
+	d.stroke(d.path(points, false), width, c)
+}
+
+func (d *_DrawTarget) FillCircle(center geometry.Vec, radius float64, c color.Color) {
+	d.fill(d.circlePath(center, radius), c)
+}
+
+func (d *_DrawTarget) StrokeCircle(center geometry.Vec, radius, width float64, c color.Color) {
+	d.stroke(d.circlePath(center, radius), width, c)
+}
+
+func (d *_DrawTarget) rectPath(r geometry.Rect) *vector.Path {
+	return d.path([]geometry.Vec{
+		r.Min,
+		geometry.V(r.Max.X, r.Min.Y),
+		r.Max,
+		geometry.V(r.Min.X, r.Max.Y),
+	}, true)
+}
+
+func (d *_DrawTarget) circlePath(center geometry.Vec, radius float64) *vector.Path {
+	// The matrix might turn the circle into an ellipse, so it is approximated by a polygon.
+	segments := int(math.Ceil(2 * math.Pi * radius * d.scale() / _CircleSegmentLength))
+	segments = int(math.Max(_MinCircleSegments, math.Min(_MaxCircleSegments, float64(segments))))
+
+	points := make([]geometry.Vec, segments)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		sin, cos := math.Sincos(angle)
+		points[i] = center.Add(geometry.V(cos, sin).Scaled(radius))
+	}
+	return d.path(points, true)
+}
+
+const (
+	_CircleSegmentLength = 4
+	_MinCircleSegments   = 12
+	_MaxCircleSegments   = 256
+)
+
+func (d *_DrawTarget) path(points []geometry.Vec, closed bool) *vector.Path {
+	toScreen := d.toScreen()
+	path := &vector.Path{}
+	for i, pt := range points {
+		onScreen := toScreen.Apply(pt)
+		x, y := float32(onScreen.X), float32(onScreen.Y)
+		if i == 0 {
+			path.MoveTo(x, y)
+		} else {
+			path.LineTo(x, y)
+		}
+	}
+	if closed {
+		path.Close()
+	}
+	return path
+}
+
+// toScreen transforms coordinates into the ones used by ebiten, with the Y axis pointing down.
+func (d *_DrawTarget) toScreen() geometry.Mat {
+	screenH := d.bounds.H()
+	flip := geometry.Mat{
+		{1, 0, 0},
+		{0, -1, screenH},
+	}
+	return flip.Compose(d.currentMatrix())
+}
+
+func (d *_DrawTarget) currentMatrix() geometry.Mat {
+	if d.matrix.Zero() {
+		return geometry.Identity()
+	}
+	return d.matrix
+}
+
+// scale is how many times the current matrix scales lengths, on average.
+func (d *_DrawTarget) scale() float64 {
+	m := d.currentMatrix()
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	return math.Sqrt(math.Abs(det))
+}
+
+func (d *_DrawTarget) fill(path *vector.Path, c color.Color) {
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	d.drawVertices(vs, is, c, ebiten.EvenOdd)
+}
+
+func (d *_DrawTarget) stroke(path *vector.Path, width float64, c color.Color) {
+	opts := &vector.StrokeOptions{
+		Width:    float32(width * d.scale()),
+		LineJoin: vector.LineJoinRound,
+		LineCap:  vector.LineCapRound,
+	}
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, opts)
+	d.drawVertices(vs, is, c, ebiten.FillAll)
+}
+
+func (d *_DrawTarget) drawVertices(vs []ebiten.Vertex, is []uint16, c color.Color, rule ebiten.FillRule) {
+	r, g, b, a := c.RGBA()
+	for i := range vs {
+		vs[i].SrcX = 1
+		vs[i].SrcY = 1
+		vs[i].ColorR = float32(r) / 0xffff
+		vs[i].ColorG = float32(g) / 0xffff
+		vs[i].ColorB = float32(b) / 0xffff
+		vs[i].ColorA = float32(a) / 0xffff
+	}
+
+	opts := &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		AntiAlias:      true,
+		FillRule:       rule,
+	}
+	d.dst.DrawTriangles(vs, is, whitePixel, opts)
+}
+
+// whitePixel is what shapes get filled with.
+// It is cut out of a bigger image, so that sampling it never reaches beyond the white.
+var whitePixel = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
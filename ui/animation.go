@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/ui/draw"
+)
+
+// A LoopMode says what an animation does once it reaches its last frame.
+type LoopMode int
+
+const (
+	// PlayOnce stays at the last frame.
+	PlayOnce LoopMode = iota
+	// Loop starts over from the first frame.
+	Loop
+	// PingPong plays the frames backwards down to the first one, then forwards again and so on.
+	PingPong
+)
+
+// A Frame of an animation.
+type Frame struct {
+	Image    draw.Image
+	Duration time.Duration
+}
+
+// An Animation is a sequence of frames, each shown for its own duration.
+//
+// The zero value has no frames.
+type Animation struct {
+	frames []Frame
+	mode   LoopMode
+	cycle  time.Duration
+}
+
+// NewAnimation creates an animation out of the frames.
+//
+// It panics when there are no frames, any of them has a nil image or a negative duration.
+func NewAnimation(mode LoopMode, frames ...Frame) Animation {
+	if len(frames) == 0 {
+		panic("animation without frames")
+	}
+	for _, f := range frames {
+		if f.Image == nil {
+			panic("nil animation frame image")
+		}
+		if f.Duration < 0 {
+			panic("negative animation frame duration")
+		}
+	}
+
+	cycle := append([]Frame(nil), frames...)
+	if mode == PingPong {
+		for i := len(frames) - 2; i > 0; i-- {
+			cycle = append(cycle, frames[i])
+		}
+	}
+
+	anim := Animation{frames: cycle, mode: mode}
+	for _, f := range cycle {
+		anim.cycle += f.Duration
+	}
+	return anim
+}
+
+// UniformAnimation creates an animation where each image is shown for the same duration.
+func UniformAnimation(mode LoopMode, frameDuration time.Duration, images ...draw.Image) Animation {
+	frames := make([]Frame, len(images))
+	for i, img := range images {
+		frames[i] = Frame{Image: img, Duration: frameDuration}
+	}
+	return NewAnimation(mode, frames...)
+}
+
+// Empty says whether the animation has no frames.
+func (anim Animation) Empty() bool { return len(anim.frames) == 0 }
+
+// Duration is how long it takes to show all the frames once.
+// For a ping-pong animation that includes going back to the first frame.
+func (anim Animation) Duration() time.Duration { return anim.cycle }
+
+// Done says whether an animation that does not loop has reached its end after elapsed time.
+func (anim Animation) Done(elapsed time.Duration) bool {
+	return anim.mode == PlayOnce && elapsed >= anim.cycle
+}
+
+// FrameAt is the image to show after elapsed time.
+// It is nil for an animation without frames.
+func (anim Animation) FrameAt(elapsed time.Duration) draw.Image {
+	if anim.Empty() {
+		return nil
+	}
+	if anim.Done(elapsed) || anim.cycle == 0 {
+		return anim.frames[len(anim.frames)-1].Image
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	elapsed %= anim.cycle
+	for _, f := range anim.frames {
+		if elapsed < f.Duration {
+			return f.Image
+		}
+		elapsed -= f.Duration
+	}
+	return anim.frames[len(anim.frames)-1].Image
+}
+
+// FrameAtProgress is the image to show when the fraction progress of the animation's duration has passed.
+// The progress wraps around for animations that loop.
+func (anim Animation) FrameAtProgress(progress float64) draw.Image {
+	elapsed := time.Duration(progress * float64(anim.cycle))
+	return anim.FrameAt(elapsed)
+}
@@ -0,0 +1,124 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui
+
+import (
+	"time"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui/draw"
+)
+
+// A Facing is the direction along the grid something is turned towards.
+//
+// North is towards higher rows and east towards higher columns.
+// The zero value faces south.
+type Facing int
+
+const (
+	FacingSouth Facing = iota
+	FacingWest
+	FacingNorth
+	FacingEast
+)
+
+// FacingTowards says which way something at src needs to face to look at dst.
+// When the points are not in a straight line, the direction with the bigger difference wins.
+// It reports false when the points are the same.
+func FacingTowards(src, dst grid.Point) (Facing, bool) {
+	dRow, dCol := dst.Row-src.Row, dst.Column-src.Column
+	switch {
+	case dRow == 0 && dCol == 0:
+		return FacingSouth, false
+	case abs(dCol) > abs(dRow) && dCol > 0:
+		return FacingEast, true
+	case abs(dCol) > abs(dRow):
+		return FacingWest, true
+	case dRow > 0:
+		return FacingNorth, true
+	default:
+		return FacingSouth, true
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// An AnimationSet holds the clips for something that can stand still and walk in four directions.
+// The clips are indexed by facing.
+//
+// A walk cycle is played over a single step on the grid.
+// When a walk clip is empty, the idle one is used instead.
+type AnimationSet struct {
+	Idle, Walk [4]Animation
+}
+
+// StillAnimationSet uses the same image for everything.
+func StillAnimationSet(img draw.Image) AnimationSet {
+	still := UniformAnimation(PlayOnce, 0, img)
+	set := AnimationSet{}
+	for i := range set.Idle {
+		set.Idle[i] = still
+	}
+	return set
+}
+
+// An AnimationPlayer picks the frames to show for a placement depending on how it moves.
+type AnimationPlayer struct {
+	set      AnimationSet
+	facing   Facing
+	walking  bool
+	progress float64
+	elapsed  time.Duration
+}
+
+// NewAnimationPlayer creates a player that starts out idle, facing south.
+func NewAnimationPlayer(set AnimationSet) *AnimationPlayer {
+	return &AnimationPlayer{set: set}
+}
+
+// Update tracks the movement of the placement and advances the idle animation by dt.
+func (p *AnimationPlayer) Update(placement *grid.HeadedPlacement, dt time.Duration) {
+	walking := placement.Headed()
+	if walking {
+		if facing, ok := FacingTowards(placement.AtPoint(), placement.Heading()); ok {
+			p.facing = facing
+		}
+		p.progress = placement.Progress()
+	}
+
+	if walking != p.walking {
+		p.elapsed = 0
+	}
+	p.walking = walking
+
+	if !walking {
+		p.elapsed += dt
+	}
+}
+
+// Facing is the direction in which the placement was last seen moving.
+func (p *AnimationPlayer) Facing() Facing { return p.facing }
+
+// Walking says whether the placement was moving when it was last seen.
+func (p *AnimationPlayer) Walking() bool { return p.walking }
+
+// Frame is the image to show now.
+func (p *AnimationPlayer) Frame() draw.Image {
+	walk := p.set.Walk[p.facing]
+	if p.walking && !walk.Empty() {
+		return walk.FrameAtProgress(p.progress)
+	}
+	return p.set.Idle[p.facing].FrameAt(p.elapsed)
+}
+
+// Sprite shows the current frame with the given anchor.
+func (p *AnimationPlayer) Sprite(anchor Anchor) Sprite {
+	return NewSprite(p.Frame(), anchor)
+}
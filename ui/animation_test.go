@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw"
+)
+
+func TestAnimationFrameAt(t *testing.T) {
+	kases := map[string]struct {
+		Mode    ui.LoopMode
+		Elapsed time.Duration
+		Frame   string
+	}{
+		"Start":                   {Frame: "a"},
+		"SecondFrame":             {Elapsed: time.Second, Frame: "b"},
+		"LongerFrame":             {Elapsed: 2500 * time.Millisecond, Frame: "b"},
+		"LastFrame":               {Elapsed: 3 * time.Second, Frame: "c"},
+		"PlayOnce/AfterTheEnd":    {Elapsed: 10 * time.Second, Frame: "c"},
+		"Loop/AfterTheEnd":        {Mode: ui.Loop, Elapsed: 4 * time.Second, Frame: "a"},
+		"Loop/SecondTime":         {Mode: ui.Loop, Elapsed: 5 * time.Second, Frame: "b"},
+		"PingPong/LastFrame":      {Mode: ui.PingPong, Elapsed: 3 * time.Second, Frame: "c"},
+		"PingPong/GoingBack":      {Mode: ui.PingPong, Elapsed: 4 * time.Second, Frame: "b"},
+		"PingPong/BackAtTheStart": {Mode: ui.PingPong, Elapsed: 6 * time.Second, Frame: "a"},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			anim := ui.NewAnimation(tt.Mode,
+				ui.Frame{Image: named("a"), Duration: time.Second},
+				ui.Frame{Image: named("b"), Duration: 2 * time.Second},
+				ui.Frame{Image: named("c"), Duration: time.Second})
+
+			// when
+			frame := anim.FrameAt(tt.Elapsed)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(nameOf(frame), tt.Frame))
+		})
+	}
+}
+
+func TestAnimationDuration(t *testing.T) {
+	kases := map[string]struct {
+		Mode     ui.LoopMode
+		Duration time.Duration
+	}{
+		"PlayOnce": {Mode: ui.PlayOnce, Duration: 3 * time.Second},
+		"Loop":     {Mode: ui.Loop, Duration: 3 * time.Second},
+		"PingPong": {Mode: ui.PingPong, Duration: 4 * time.Second},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			anim := ui.UniformAnimation(tt.Mode, time.Second, named("a"), named("b"), named("c"))
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(anim.Duration(), tt.Duration))
+		})
+	}
+}
+
+func TestAnimationThatPlaysOnceIsDoneAtTheEnd(t *testing.T) {
+	// given
+	anim := ui.UniformAnimation(ui.PlayOnce, time.Second, named("a"), named("b"))
+
+	// when
+	// then
+	assert.Using(t.Errorf).
+		That(!anim.Done(time.Second), "animation should not be done halfway").
+		That(anim.Done(2*time.Second), "animation should be done at the end")
+}
+
+func TestLoopingAnimationIsNeverDone(t *testing.T) {
+	// given
+	anim := ui.UniformAnimation(ui.Loop, time.Second, named("a"), named("b"))
+
+	// when
+	// then
+	assert.Using(t.Errorf).That(!anim.Done(time.Hour), "looping animation should not be done")
+}
+
+func TestAnimationFrameAtProgress(t *testing.T) {
+	// given
+	anim := ui.UniformAnimation(ui.Loop, time.Second, named("a"), named("b"), named("c"), named("d"))
+
+	// when
+	frame := anim.FrameAtProgress(0.6)
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(nameOf(frame), "c"))
+}
+
+func TestFacingTowards(t *testing.T) {
+	kases := map[string]struct {
+		Dst    grid.Point
+		Facing ui.Facing
+		OK     bool
+	}{
+		"Same":              {Dst: grid.P(0, 0), Facing: ui.FacingSouth},
+		"North":             {Dst: grid.P(1, 0), Facing: ui.FacingNorth, OK: true},
+		"South":             {Dst: grid.P(-1, 0), Facing: ui.FacingSouth, OK: true},
+		"East":              {Dst: grid.P(0, 1), Facing: ui.FacingEast, OK: true},
+		"West":              {Dst: grid.P(0, -1), Facing: ui.FacingWest, OK: true},
+		"MostlyEast":        {Dst: grid.P(1, 3), Facing: ui.FacingEast, OK: true},
+		"MostlySouth":       {Dst: grid.P(-3, 1), Facing: ui.FacingSouth, OK: true},
+		"DiagonalNorthWest": {Dst: grid.P(2, -2), Facing: ui.FacingNorth, OK: true},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			facing, ok := ui.FacingTowards(grid.P(0, 0), tt.Dst)
+
+			// then
+			assert.Using(t.Errorf).
+				That(theval.Equal(facing, tt.Facing)).
+				That(theval.Equal(ok, tt.OK))
+		})
+	}
+}
+
+func TestAnimationPlayerWalksInTheDirectionOfMovement(t *testing.T) {
+	// given
+	space, placement := placedOnARow()
+	player := ui.NewAnimationPlayer(walkingSet())
+	move := placement.MoveTo(space.At(grid.P(0, 1)), time.Second)
+
+	// when
+	move.Run(time.Second / 2)
+	player.Update(placement, time.Second/2)
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(player.Facing(), ui.FacingEast)).
+		That(player.Walking(), "player should be walking").
+		That(theval.Equal(nameOf(player.Frame()), "walk-east-2"))
+}
+
+func TestAnimationPlayerIsIdleAfterArriving(t *testing.T) {
+	// given
+	space, placement := placedOnARow()
+	player := ui.NewAnimationPlayer(walkingSet())
+	move := placement.MoveTo(space.At(grid.P(0, -1)), time.Second)
+	move.Run(time.Second / 2)
+	player.Update(placement, time.Second/2)
+
+	// when
+	move.Run(time.Second)
+	player.Update(placement, time.Second/2)
+	player.Update(placement, time.Second)
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(player.Facing(), ui.FacingWest)).
+		That(!player.Walking(), "player should not be walking").
+		That(theval.Equal(nameOf(player.Frame()), "idle-west-1"))
+}
+
+func TestAnimationPlayerUsesIdleClipWhenWalkClipIsMissing(t *testing.T) {
+	// given
+	space, placement := placedOnARow()
+	player := ui.NewAnimationPlayer(ui.StillAnimationSet(named("still")))
+	move := placement.MoveTo(space.At(grid.P(0, 1)), time.Second)
+
+	// when
+	move.Run(time.Second / 2)
+	player.Update(placement, time.Second/2)
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(nameOf(player.Frame()), "still"))
+}
+
+func placedOnARow() (*grid.Space, *grid.HeadedPlacement) {
+	space := grid.NewSpace()
+	for col := -1; col <= 1; col++ {
+		space.At(grid.P(0, col)).Create()
+	}
+	placement := &grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+	return space, placement
+}
+
+func walkingSet() ui.AnimationSet {
+	set := ui.AnimationSet{}
+	for facing, name := range map[ui.Facing]string{
+		ui.FacingSouth: "south",
+		ui.FacingWest:  "west",
+		ui.FacingNorth: "north",
+		ui.FacingEast:  "east",
+	} {
+		set.Idle[facing] = ui.UniformAnimation(
+			ui.Loop, time.Second,
+			named("idle-"+name+"-0"), named("idle-"+name+"-1"))
+		set.Walk[facing] = ui.UniformAnimation(
+			ui.Loop, time.Second/4,
+			named("walk-"+name+"-0"), named("walk-"+name+"-1"),
+			named("walk-"+name+"-2"), named("walk-"+name+"-3"))
+	}
+	return set
+}
+
+func named(name string) draw.Image { return &RecordingImage{Name: name} }
+
+func nameOf(img draw.Image) string {
+	if img == nil {
+		return ""
+	}
+	return img.(*RecordingImage).Name
+}
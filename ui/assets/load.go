@@ -10,12 +10,17 @@ import (
 	"image"
 	_ "image/png"
 	"io/fs"
+	"path"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/szabba/tob-cob/game/behaviour"
 	"github.com/szabba/tob-cob/run"
 	"github.com/szabba/tob-cob/ui/draw"
 	"github.com/szabba/tob-cob/ui/input"
+	"github.com/szabba/tob-cob/ui/text"
 	"golang.org/x/exp/slog"
 )
 
@@ -118,8 +123,13 @@ func (l *_Load[Loaded]) computeTasks() ([]taskFunc, error) {
 			continue
 		}
 
-		if f.Type == imgType {
+		switch f.Type {
+		case imgType:
 			tasks = append(tasks, l.loadImage(f))
+		case fontType:
+			tasks = append(tasks, l.loadFont(f))
+		case specType:
+			tasks = append(tasks, l.loadBehaviour(f))
 		}
 	}
 
@@ -131,33 +141,148 @@ func (l *_Load[Loaded]) loadImage(typField reflect.StructField) taskFunc {
 
 		fname := typField.Tag.Get(tagKey)
 
+		imported, err := l.importImage(dst, fname)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("loaded image", slog.String("name", fname))
+
+		return l.set(typField, imported)
+	}
+}
+
+// loadFont loads a font into a field.
+//
+// TrueType and OpenType fonts need a size struct tag with the font size.
+// Any other file is understood as an image with a bitmap font.
+// Its glyphs struct tag says how big the glyphs are, like 8x12.
+// The optional chars struct tag lists the runes in the image, in order.
+// Without it, the image is expected to hold the printable ASCII characters.
+func (l *_Load[Loaded]) loadFont(typField reflect.StructField) taskFunc {
+	return func(dst draw.Target) error {
+
+		fname := typField.Tag.Get(tagKey)
+
+		var (
+			font text.Font
+			err  error
+		)
+		switch strings.ToLower(path.Ext(fname)) {
+		case ".ttf", ".otf":
+			font, err = l.parseFont(fname, typField.Tag)
+		default:
+			font, err = l.bitmapFont(dst, fname, typField.Tag)
+		}
+		if err != nil {
+			return err
+		}
+
+		slog.Info("loaded font", slog.String("name", fname))
+
+		return l.set(typField, font)
+	}
+}
+
+// loadBehaviour loads a behaviour tree spec into a field.
+// It is left to the game to build the tree with the library it has for it.
+func (l *_Load[Loaded]) loadBehaviour(typField reflect.StructField) taskFunc {
+	return func(_ draw.Target) error {
+
+		fname := typField.Tag.Get(tagKey)
+
 		file, err := l.fs.Open(fname)
 		if err != nil {
-			return fmt.Errorf("cannot open image %q: %w", fname, err)
+			return fmt.Errorf("cannot open behaviour %q: %w", fname, err)
 		}
 		defer file.Close()
 
-		img, _, err := image.Decode(file)
+		spec, err := behaviour.Parse(file)
 		if err != nil {
-			return fmt.Errorf("cannot decode image %q: %w", fname, err)
+			return fmt.Errorf("cannot load behaviour %q: %w", fname, err)
 		}
 
-		imported := dst.Import(img)
+		slog.Info("loaded behaviour", slog.String("name", fname))
 
-		valField := reflect.ValueOf(&l.loaded).Elem().FieldByIndex(typField.Index)
-		if !valField.CanSet() {
-			return fmt.Errorf("cannot set %s", typField.Name)
-		}
+		return l.set(typField, spec)
+	}
+}
 
-		slog.Info("loaded image", slog.String("name", fname))
+func (l *_Load[Loaded]) parseFont(fname string, tag reflect.StructTag) (text.Font, error) {
+	size, err := strconv.ParseFloat(tag.Get(sizeTagKey), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size of font %q: %w", fname, err)
+	}
 
-		valField.Set(reflect.ValueOf(imported))
-		return nil
+	data, err := fs.ReadFile(l.fs, fname)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read font %q: %w", fname, err)
+	}
+
+	font, err := text.ParseFace(data, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load font %q: %w", fname, err)
+	}
+	return font, nil
+}
+
+func (l *_Load[Loaded]) bitmapFont(dst draw.Target, fname string, tag reflect.StructTag) (text.Font, error) {
+	var width, height float64
+	_, err := fmt.Sscanf(tag.Get(glyphsTagKey), "%gx%g", &width, &height)
+	if err != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid glyph size %q of font %q", tag.Get(glyphsTagKey), fname)
+	}
+
+	chars, ok := tag.Lookup(charsTagKey)
+	if !ok {
+		chars = text.PrintableASCII
+	}
+
+	sheet, err := l.importImage(dst, fname)
+	if err != nil {
+		return nil, err
+	}
+	return text.NewBitmapFont(sheet, width, height, chars), nil
+}
+
+func (l *_Load[Loaded]) importImage(dst draw.Target, fname string) (draw.Image, error) {
+	file, err := l.fs.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image %q: %w", fname, err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image %q: %w", fname, err)
+	}
+
+	return dst.Import(img), nil
+}
+
+func (l *_Load[Loaded]) set(typField reflect.StructField, value any) error {
+	valField := reflect.ValueOf(&l.loaded).Elem().FieldByIndex(typField.Index)
+	if !valField.CanSet() {
+		return fmt.Errorf("cannot set %s", typField.Name)
 	}
+
+	valField.Set(reflect.ValueOf(value))
+	return nil
 }
 
 var imgType = func() reflect.Type {
 	return reflect.TypeOf(new(draw.Image)).Elem()
 }()
 
-const tagKey = "asset"
+var fontType = func() reflect.Type {
+	return reflect.TypeOf(new(text.Font)).Elem()
+}()
+
+var specType = reflect.TypeOf(behaviour.Spec{})
+
+const (
+	tagKey       = "asset"
+	sizeTagKey   = "size"
+	glyphsTagKey = "glyphs"
+	charsTagKey  = "chars"
+)
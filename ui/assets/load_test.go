@@ -5,23 +5,30 @@
 package assets_test
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
+	"image"
+	"image/png"
 	"io/fs"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/szabba/assert/v2"
 	"github.com/szabba/assert/v2/assertions/theerr"
 	"github.com/szabba/assert/v2/assertions/theval"
+	"golang.org/x/image/font/gofont/goregular"
 
+	"github.com/szabba/tob-cob/game/behaviour"
 	"github.com/szabba/tob-cob/run"
 	"github.com/szabba/tob-cob/ui/assets"
 	"github.com/szabba/tob-cob/ui/draw"
 	"github.com/szabba/tob-cob/ui/draw/testdraw"
 	"github.com/szabba/tob-cob/ui/input"
 	"github.com/szabba/tob-cob/ui/input/testinput"
+	"github.com/szabba/tob-cob/ui/text"
 )
 
 //go:embed test-assets
@@ -133,6 +140,185 @@ func TestLoadStructWithSingleImageField(t *testing.T) {
 		That(theerr.IsNil(err))
 }
 
+func TestLoadFonts(t *testing.T) {
+	kases := map[string]struct {
+		Load   func() (text.Font, error)
+		Loaded bool
+	}{
+		"TrueType": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.ttf" size:"16"`
+			}) text.Font {
+				return a.Font
+			}),
+			Loaded: true,
+		},
+		"TrueTypeWithoutSize": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.ttf"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+		"TrueTypeWithNegativeSize": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.ttf" size:"-1"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+		"TrueTypeThatIsNotAFont": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"broken.ttf" size:"16"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+		"Bitmap": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.png" glyphs:"2x3"`
+			}) text.Font {
+				return a.Font
+			}),
+			Loaded: true,
+		},
+		"BitmapWithChars": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.png" glyphs:"2x3" chars:"abcd"`
+			}) text.Font {
+				return a.Font
+			}),
+			Loaded: true,
+		},
+		"BitmapWithoutGlyphSize": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.png"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+		"BitmapWithBadGlyphSize": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"font.png" glyphs:"2 by 3"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+		"BitmapThatIsMissing": {
+			Load: loadFont(func(a struct {
+				Font text.Font `asset:"missing.png" glyphs:"2x3"`
+			}) text.Font {
+				return a.Font
+			}),
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+
+			// when
+			font, err := kase.Load()
+
+			// then
+			assert.Using(t.Errorf).
+				That(kase.Loaded == (err == nil), "unexpected error: %v", err).
+				That(kase.Loaded == (font != nil), "font loaded: %v", font != nil)
+		})
+	}
+}
+
+func TestLoadBehaviours(t *testing.T) {
+	kases := map[string]struct {
+		Load   func() (behaviour.Spec, error)
+		Loaded bool
+	}{
+		"Tree": {
+			Load: loadBehaviour(func(a struct {
+				Tree behaviour.Spec `asset:"tree.json"`
+			}) behaviour.Spec {
+				return a.Tree
+			}),
+			Loaded: true,
+		},
+		"TreeThatIsNotJSON": {
+			Load: loadBehaviour(func(a struct {
+				Tree behaviour.Spec `asset:"broken.json"`
+			}) behaviour.Spec {
+				return a.Tree
+			}),
+		},
+		"TreeThatIsMissing": {
+			Load: loadBehaviour(func(a struct {
+				Tree behaviour.Spec `asset:"missing.json"`
+			}) behaviour.Spec {
+				return a.Tree
+			}),
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+
+			// when
+			spec, err := kase.Load()
+
+			// then
+			assert.Using(t.Errorf).
+				That(kase.Loaded == (err == nil), "unexpected error: %v", err).
+				That(kase.Loaded == (spec.Type == "wait"), "loaded spec: %#v", spec)
+		})
+	}
+}
+
+// loadFont returns a function that loads a font with the help of the field getter.
+func loadFont[Fonts any](field func(Fonts) text.Font) func() (text.Font, error) {
+	return func() (text.Font, error) {
+		fontsFS := fstest.MapFS{
+			"font.ttf":   {Data: goregular.TTF},
+			"broken.ttf": {Data: []byte("not a font")},
+			"font.png":   {Data: encodePNG(image.NewRGBA(image.Rect(0, 0, 4, 6)))},
+		}
+
+		var loaded text.Font
+		game := assets.Load(fontsFS, func(fonts Fonts) run.Game {
+			loaded = field(fonts)
+			return nil
+		})
+
+		game.Draw(&testdraw.Target{}, nil)
+		_, err := game.Update(testinput.Source{}, dt)
+		return loaded, err
+	}
+}
+
+// loadBehaviour returns a function that loads a behaviour tree spec with the help of the field getter.
+func loadBehaviour[Behaviours any](field func(Behaviours) behaviour.Spec) func() (behaviour.Spec, error) {
+	return func() (behaviour.Spec, error) {
+		behavioursFS := fstest.MapFS{
+			"tree.json":   {Data: []byte(`{"type": "wait", "duration": "1s"}`)},
+			"broken.json": {Data: []byte(`{"type": `)},
+		}
+
+		var loaded behaviour.Spec
+		game := assets.Load(behavioursFS, func(behaviours Behaviours) run.Game {
+			loaded = field(behaviours)
+			return nil
+		})
+
+		game.Draw(&testdraw.Target{}, nil)
+		_, err := game.Update(testinput.Source{}, dt)
+		return loaded, err
+	}
+}
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
 type DummyGame struct{}
 
 func (DummyGame) Draw(_ draw.Target, _ input.Source) {}
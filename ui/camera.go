@@ -5,33 +5,138 @@
 package ui
 
 import (
+	"fmt"
+	"math"
+	"time"
+
 	"github.com/szabba/tob-cob/ui/geometry"
 	"github.com/szabba/tob-cob/ui/input"
 	"golang.org/x/exp/slog"
 )
 
+// Zoom limits used by cameras that were not given any.
+const (
+	DefaultMinZoom = 0.25
+	DefaultMaxZoom = 4
+)
+
 // A Camera describes the coordinate tranformation between the world and the window.
 //
 // The zero value looks at the origin point (pixel.ZV) in world coordinates.
+// It is neither zoomed nor rotated.
 type Camera struct {
 	lookAt geometry.Vec
+
+	// The zoom is stored as an offset from 1 so that the zero value is not zoomed.
+	zoomOffset float64
+	rotation   float64
+
+	minZoom, maxZoom float64
+
+	bounds  geometry.Rect
+	bounded bool
 }
 
 // NewCamera creates a camera that will put lookAt in the center of the window.
 func NewCamera(lookAt geometry.Vec) Camera {
-	return Camera{lookAt}
+	return Camera{lookAt: lookAt}
 }
 
+// LookAt is the point in world coordinates that is put in the center of the window.
+func (cam *Camera) LookAt() geometry.Vec { return cam.lookAt }
+
 // MoveBy changes the point being looked at by delta in window coordinates.
 func (cam *Camera) MoveBy(delta geometry.Vec) {
-	cam.lookAt = cam.lookAt.Add(delta)
+	cam.MoveTo(cam.lookAt.Add(cam.windowToWorldDelta(delta)))
+}
 
+// MoveTo changes the point being looked at to lookAt in world coordinates.
+func (cam *Camera) MoveTo(lookAt geometry.Vec) {
+	cam.lookAt = lookAt
+	cam.clamp()
+}
+
+// SetBounds restricts the points the camera can look at to the area in world coordinates.
+// The point currently looked at gets moved inside the area.
+func (cam *Camera) SetBounds(area geometry.Rect) {
+	cam.bounds, cam.bounded = area, true
+	cam.clamp()
+}
+
+// ClearBounds lets the camera look at any point.
+func (cam *Camera) ClearBounds() {
+	cam.bounds, cam.bounded = geometry.Rect{}, false
+}
+
+func (cam *Camera) clamp() {
+	if !cam.bounded {
+		return
+	}
+	cam.lookAt = cam.bounds.Clamp(cam.lookAt)
+}
+
+// Zoom says how many times bigger things in the world appear in the window.
+func (cam *Camera) Zoom() float64 { return 1 + cam.zoomOffset }
+
+// ZoomLimits are the smallest and biggest zoom the camera allows.
+func (cam *Camera) ZoomLimits() (min, max float64) {
+	if cam.minZoom == 0 && cam.maxZoom == 0 {
+		return DefaultMinZoom, DefaultMaxZoom
+	}
+	return cam.minZoom, cam.maxZoom
+}
+
+// SetZoomLimits changes the smallest and biggest zoom the camera allows.
+// The current zoom gets clamped to the new limits.
+//
+// It panics unless 0 < min <= max.
+func (cam *Camera) SetZoomLimits(min, max float64) {
+	if min <= 0 || max < min {
+		panic(fmt.Sprintf("invalid zoom limits [%v, %v]", min, max))
+	}
+	cam.minZoom, cam.maxZoom = min, max
+	cam.setZoom(cam.Zoom())
+}
+
+// ZoomBy multiplies the zoom by factor, keeping the center of the window in place.
+// The zoom never leaves the zoom limits.
+func (cam *Camera) ZoomBy(factor float64) {
+	cam.setZoom(cam.Zoom() * factor)
+}
+
+// ZoomAt multiplies the zoom by factor.
+// The point of the world that was visible at onScreen (in window coordinates) remains there.
+// The zoom never leaves the zoom limits.
+func (cam *Camera) ZoomAt(factor float64, onScreen geometry.Vec, bounds geometry.Rect) {
+	inWorld := cam.Matrix(bounds).Invert().Apply(onScreen)
+
+	cam.ZoomBy(factor)
+
+	drift := onScreen.Sub(cam.Matrix(bounds).Apply(inWorld))
+	cam.MoveBy(drift.Scaled(-1))
+}
+
+func (cam *Camera) setZoom(zoom float64) {
+	min, max := cam.ZoomLimits()
+	zoom = math.Max(min, math.Min(max, zoom))
+	cam.zoomOffset = zoom - 1
+}
+
+// Rotation is the counter-clockwise angle in radians by which the world appears rotated in the window.
+func (cam *Camera) Rotation() float64 { return cam.rotation }
+
+// RotateBy rotates the world as seen in the window counter-clockwise around the center of the window.
+func (cam *Camera) RotateBy(radians float64) {
+	cam.rotation = math.Remainder(cam.rotation+radians, 2*math.Pi)
 }
 
 // Matrix computes the world-to-window coordinate transformation matrix.
 func (cam *Camera) Matrix(bounds geometry.Rect) geometry.Mat {
 	center := bounds.Center()
-	matrix := geometry.Translation(center.Add(cam.lookAt.Scaled(-1)))
+	matrix := geometry.Translation(center).
+		Compose(geometry.Rotation(cam.rotation)).
+		Compose(geometry.Scale(cam.Zoom())).
+		Compose(geometry.Translation(cam.lookAt.Scaled(-1)))
 
 	if slog.Default().Enabled(nil, slog.LevelDebug) {
 		slog.Debug(
@@ -47,6 +152,9 @@ func (cam *Camera) Matrix(bounds geometry.Rect) geometry.Mat {
 				slog.Float64("y", cam.lookAt.Y),
 			),
 
+			slog.Float64("zoom", cam.Zoom()),
+			slog.Float64("rotation", cam.rotation),
+
 			slog.String("matrix", matrix.String()),
 		)
 	}
@@ -54,41 +162,132 @@ func (cam *Camera) Matrix(bounds geometry.Rect) geometry.Mat {
 	return matrix
 }
 
+func (cam *Camera) windowToWorldDelta(delta geometry.Vec) geometry.Vec {
+	toWorld := geometry.Rotation(-cam.rotation).Compose(geometry.Scale(1 / cam.Zoom()))
+	return toWorld.Apply(delta)
+}
+
+// A CameraController moves a camera in response to user input.
+//
+// The camera accelerates and slows down smoothly instead of jumping by a fixed distance every update.
+// It can also follow a moving point.
 type CameraController struct {
-	cam *Camera
+	cam      *Camera
+	velocity geometry.Vec // in window coordinates per second
+	follow   func() geometry.Vec
 }
 
 func NewCamController(cam *Camera) *CameraController {
 	return &CameraController{cam: cam}
 }
 
-func (cont *CameraController) Process(src input.Source) {
+// Follow makes the camera smoothly track the point returned by target (in world coordinates).
+// Following stops when the user moves the camera by hand.
+func (cont *CameraController) Follow(target func() geometry.Vec) {
+	cont.follow = target
+}
+
+// Unfollow stops the camera from following anything.
+func (cont *CameraController) Unfollow() { cont.follow = nil }
+
+// Following says whether the camera follows some point.
+func (cont *CameraController) Following() bool { return cont.follow != nil }
+
+func (cont *CameraController) Process(src input.Source, dt time.Duration) {
 	if !src.Focused() {
 		return
 	}
-	delta := cont.lookAtDelta(src)
-	cont.cam.MoveBy(delta)
+
+	dir := cont.lookAtDirection(src)
+	if dir != (geometry.Vec{}) {
+		cont.Unfollow()
+	}
+	cont.accelerate(dir, dt)
+	cont.cam.MoveBy(cont.velocity.Scaled(dt.Seconds()))
+
+	cont.track(dt)
+	cont.zoom(src, dt)
+}
+
+func (cont *CameraController) accelerate(dir geometry.Vec, dt time.Duration) {
+	target := geometry.Vec{}
+	rate := _Deceleration
+	if dir != (geometry.Vec{}) {
+		target = dir.Scaled(_MaxSpeed / dir.Len())
+		rate = _Acceleration
+	}
+
+	diff := target.Sub(cont.velocity)
+	maxChange := rate * dt.Seconds()
+	if diff.Len() <= maxChange {
+		cont.velocity = target
+		return
+	}
+	cont.velocity = cont.velocity.Add(diff.Scaled(maxChange / diff.Len()))
+}
+
+func (cont *CameraController) track(dt time.Duration) {
+	if cont.follow == nil {
+		return
+	}
+	// Cover a fixed fraction of the remaining distance per unit of time, regardless of the update rate.
+	t := 1 - math.Exp2(-dt.Seconds()/_FollowHalfLife.Seconds())
+	lookAt := cont.cam.LookAt().Lerp(cont.follow(), t)
+	cont.cam.MoveTo(lookAt)
+}
+
+func (cont *CameraController) zoom(src input.Source, dt time.Duration) {
+	if src.MouseInsideWindow() {
+		wheel := src.Wheel().Y
+		if wheel != 0 {
+			factor := math.Pow(_ZoomStep, wheel)
+			cont.cam.ZoomAt(factor, src.MousePosition(), src.Bounds())
+		}
+	}
+
+	factor := math.Pow(_KeyZoomRate, dt.Seconds())
+	if src.Pressed(input.KeyEqual()) {
+		cont.cam.ZoomBy(factor)
+	}
+	if src.Pressed(input.KeyMinus()) {
+		cont.cam.ZoomBy(1 / factor)
+	}
 }
 
-func (cont *CameraController) lookAtDelta(src input.Source) geometry.Vec {
+const (
+	// Camera movement speed, in window pixels per second.
+	_MaxSpeed = 300.0
+	// How quickly the camera gets up to speed and stops, in window pixels per second squared.
+	_Acceleration = 1500.0
+	_Deceleration = 2000.0
+	// How long it takes a following camera to halve the distance to its target.
+	_FollowHalfLife = time.Second / 8
+
+	// How much a single notch of the mouse wheel zooms by.
+	_ZoomStep = 1.1
+	// How much holding down a zoom key zooms by every second.
+	_KeyZoomRate = 2.0
+)
+
+func (cont *CameraController) lookAtDirection(src input.Source) geometry.Vec {
 	if !src.MouseInsideWindow() {
 		return geometry.Vec{}
 	}
 
-	delta := geometry.Vec{}
+	dir := geometry.Vec{}
 	if src.Pressed(input.KeyLeft()) || cont.mouseNearLeftEdge(src) {
-		delta.X -= 5
+		dir.X -= 1
 	}
 	if src.Pressed(input.KeyRight()) || cont.mouseNearRightEdge(src) {
-		delta.X += 5
+		dir.X += 1
 	}
 	if src.Pressed(input.KeyUp()) || cont.mouseNearTopEdge(src) {
-		delta.Y += 5
+		dir.Y += 1
 	}
 	if src.Pressed(input.KeyDown()) || cont.mouseNearBottomEdge(src) {
-		delta.Y -= 5
+		dir.Y -= 1
 	}
-	return delta
+	return dir
 }
 
 func (*CameraController) mouseNearLeftEdge(src input.Source) bool {
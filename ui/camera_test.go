@@ -5,12 +5,16 @@
 package ui_test
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/szabba/assert"
 
 	"github.com/szabba/tob-cob/ui"
 	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/input"
+	"github.com/szabba/tob-cob/ui/input/testinput"
 )
 
 func TestZeroCameraCentersTheOrigin(t *testing.T) {
@@ -61,3 +65,319 @@ func TestCameraMoveByShiftsThePointBeingLookedAt(t *testing.T) {
 		onscreen == center,
 		t.Errorf, "origin at %s, want it at %s", onscreen, center)
 }
+
+func TestZeroCameraIsNotZoomedNorRotated(t *testing.T) {
+	// given
+	// when
+	cam := ui.Camera{}
+
+	// then
+	assert.That(cam.Zoom() == 1, t.Errorf, "got zoom %v, want %v", cam.Zoom(), 1)
+	assert.That(cam.Rotation() == 0, t.Errorf, "got rotation %v, want %v", cam.Rotation(), 0)
+}
+
+func TestCameraZoomScalesDistancesFromTheLookAtPoint(t *testing.T) {
+	// given
+	bounds := geometry.R(0, 0, 800, 600)
+	center := bounds.Center()
+	lookAt := geometry.V(300, 200)
+	cam := ui.NewCamera(lookAt)
+
+	// when
+	cam.ZoomBy(2)
+
+	// then
+	onscreen := cam.Matrix(bounds).Apply(lookAt.Add(geometry.V(10, 5)))
+	want := center.Add(geometry.V(20, 10))
+	assertNear(t, onscreen, want)
+}
+
+func TestCameraZoomStaysWithinLimits(t *testing.T) {
+	tests := map[string]struct {
+		Factor float64
+		Zoom   float64
+	}{
+		"WithinLimits": {Factor: 1.5, Zoom: 1.5},
+		"AboveMax":     {Factor: 100, Zoom: 2},
+		"BelowMin":     {Factor: 0.01, Zoom: 0.5},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cam := ui.Camera{}
+			cam.SetZoomLimits(0.5, 2)
+
+			// when
+			cam.ZoomBy(tt.Factor)
+
+			// then
+			assert.That(cam.Zoom() == tt.Zoom, t.Errorf, "got zoom %v, want %v", cam.Zoom(), tt.Zoom)
+		})
+	}
+}
+
+func TestCameraZoomIsClampedWhenLimitsChange(t *testing.T) {
+	// given
+	cam := ui.Camera{}
+	cam.ZoomBy(3)
+
+	// when
+	cam.SetZoomLimits(0.5, 2)
+
+	// then
+	assert.That(cam.Zoom() == 2, t.Errorf, "got zoom %v, want %v", cam.Zoom(), 2)
+}
+
+func TestCameraZoomAtKeepsThePointUnderTheCursorInPlace(t *testing.T) {
+	// given
+	bounds := geometry.R(0, 0, 800, 600)
+	cursor := geometry.V(650, 120)
+	cam := ui.NewCamera(geometry.V(30, -70))
+	cam.RotateBy(math.Pi / 6)
+	inWorld := cam.Matrix(bounds).Invert().Apply(cursor)
+
+	// when
+	cam.ZoomAt(1.7, cursor, bounds)
+
+	// then
+	assert.That(cam.Zoom() == 1.7, t.Errorf, "got zoom %v, want %v", cam.Zoom(), 1.7)
+	onscreen := cam.Matrix(bounds).Apply(inWorld)
+	assertNear(t, onscreen, cursor)
+}
+
+func TestCameraRotatesAroundTheLookAtPoint(t *testing.T) {
+	// given
+	bounds := geometry.R(0, 0, 800, 600)
+	center := bounds.Center()
+	lookAt := geometry.V(300, 200)
+	cam := ui.NewCamera(lookAt)
+
+	// when
+	cam.RotateBy(math.Pi / 2)
+
+	// then
+	assertNear(t, cam.Matrix(bounds).Apply(lookAt), center)
+	onscreen := cam.Matrix(bounds).Apply(lookAt.Add(geometry.V(10, 0)))
+	assertNear(t, onscreen, center.Add(geometry.V(0, 10)))
+}
+
+func TestCameraMoveByIsInWindowCoordinatesWhenZoomedAndRotated(t *testing.T) {
+	// given
+	bounds := geometry.R(0, 0, 800, 600)
+	center := bounds.Center()
+	lookAt := geometry.V(300, 200)
+	cam := ui.NewCamera(lookAt)
+	cam.ZoomBy(2)
+	cam.RotateBy(math.Pi / 3)
+	before := cam.Matrix(bounds).Apply(lookAt)
+
+	// when
+	cam.MoveBy(geometry.V(40, -10))
+
+	// then
+	after := cam.Matrix(bounds).Apply(lookAt)
+	assertNear(t, before, center)
+	assertNear(t, after, center.Sub(geometry.V(40, -10)))
+}
+
+func TestCameraControllerZoomsTowardsTheCursorWithTheWheel(t *testing.T) {
+	// given
+	bounds := geometry.R(0, 0, 800, 600)
+	cursor := geometry.V(500, 400)
+
+	src := testinput.Source{}
+	src.Mock.Focused = func() bool { return true }
+	src.Mock.MouseInsideWindow = func() bool { return true }
+	src.Mock.Bounds = func() geometry.Rect { return bounds }
+	src.Mock.MousePosition = func() geometry.Vec { return cursor }
+	src.Mock.Wheel = func() geometry.Vec { return geometry.V(0, 1) }
+
+	cam := ui.Camera{}
+	inWorld := cam.Matrix(bounds).Invert().Apply(cursor)
+	cont := ui.NewCamController(&cam)
+
+	// when
+	cont.Process(src, time.Second/60)
+
+	// then
+	assert.That(cam.Zoom() > 1, t.Errorf, "got zoom %v, want more than %v", cam.Zoom(), 1)
+	assertNear(t, cam.Matrix(bounds).Apply(inWorld), cursor)
+}
+
+func TestCameraControllerZoomsOutWithTheMinusKey(t *testing.T) {
+	// given
+	src := testinput.Source{}
+	src.Mock.Focused = func() bool { return true }
+	src.Mock.Bounds = func() geometry.Rect { return geometry.R(0, 0, 800, 600) }
+	src.Mock.Pressed = func(btn input.Button) bool { return btn == input.KeyMinus() }
+
+	cam := ui.Camera{}
+	cont := ui.NewCamController(&cam)
+
+	// when
+	cont.Process(src, time.Second/60)
+
+	// then
+	assert.That(cam.Zoom() < 1, t.Errorf, "got zoom %v, want less than %v", cam.Zoom(), 1)
+}
+
+func TestCameraControllerKeyZoomDoesNotDependOnTheUpdateRate(t *testing.T) {
+	// given
+	src := testinput.Source{}
+	src.Mock.Focused = func() bool { return true }
+	src.Mock.Bounds = func() geometry.Rect { return geometry.R(0, 0, 800, 600) }
+	src.Mock.Pressed = func(btn input.Button) bool { return btn == input.KeyEqual() }
+
+	slowCam, fastCam := ui.Camera{}, ui.Camera{}
+	slow, fast := ui.NewCamController(&slowCam), ui.NewCamController(&fastCam)
+
+	// when
+	for i := 0; i < 15; i++ {
+		slow.Process(src, time.Second/30)
+	}
+	for i := 0; i < 60; i++ {
+		fast.Process(src, time.Second/120)
+	}
+
+	// then
+	assert.That(slowCam.Zoom() > 1, t.Errorf, "got zoom %v, want more than %v", slowCam.Zoom(), 1)
+	assert.That(
+		math.Abs(slowCam.Zoom()-fastCam.Zoom()) < 1e-6,
+		t.Errorf, "zoomed to %v at 30 updates per second and to %v at 120", slowCam.Zoom(), fastCam.Zoom())
+}
+
+func assertNear(t *testing.T, got, want geometry.Vec) {
+	t.Helper()
+	const epsilon = 1e-9
+	diff := got.Sub(want)
+	assert.That(
+		math.Abs(diff.X) < epsilon && math.Abs(diff.Y) < epsilon,
+		t.Errorf, "got %#v, want %#v", got, want)
+}
+
+func TestCameraLookAtIsClampedToBounds(t *testing.T) {
+	// given
+	cam := ui.NewCamera(geometry.V(500, -500))
+
+	// when
+	cam.SetBounds(geometry.R(-100, -50, 200, 100))
+
+	// then
+	want := geometry.V(100, -50)
+	assert.That(cam.LookAt() == want, t.Errorf, "looking at %#v, want %#v", cam.LookAt(), want)
+}
+
+func TestCameraCannotBeMovedOutOfBounds(t *testing.T) {
+	// given
+	cam := ui.Camera{}
+	cam.SetBounds(geometry.R(-100, -50, 200, 100))
+
+	// when
+	cam.MoveBy(geometry.V(-1000, 30))
+
+	// then
+	want := geometry.V(-100, 30)
+	assert.That(cam.LookAt() == want, t.Errorf, "looking at %#v, want %#v", cam.LookAt(), want)
+}
+
+func TestCameraWithClearedBoundsCanMoveAnywhere(t *testing.T) {
+	// given
+	cam := ui.Camera{}
+	cam.SetBounds(geometry.R(-100, -50, 200, 100))
+	cam.ClearBounds()
+
+	// when
+	cam.MoveTo(geometry.V(-1000, 30))
+
+	// then
+	want := geometry.V(-1000, 30)
+	assert.That(cam.LookAt() == want, t.Errorf, "looking at %#v, want %#v", cam.LookAt(), want)
+}
+
+func TestCameraControllerAcceleratesSmoothly(t *testing.T) {
+	// given
+	src := scrollingRight()
+	cam := ui.Camera{}
+	cont := ui.NewCamController(&cam)
+
+	// when
+	cont.Process(src, time.Second/60)
+	first := cam.LookAt().X
+	cont.Process(src, time.Second/60)
+	second := cam.LookAt().X - first
+
+	// then
+	assert.That(first > 0, t.Errorf, "the camera moved by %v, want it to move right", first)
+	assert.That(second > first, t.Errorf, "the camera moved by %v then %v, want it to speed up", first, second)
+}
+
+func TestCameraControllerSpeedDoesNotDependOnTheUpdateRate(t *testing.T) {
+	// given
+	src := scrollingRight()
+	slowCam, fastCam := ui.Camera{}, ui.Camera{}
+	slow, fast := ui.NewCamController(&slowCam), ui.NewCamController(&fastCam)
+
+	// when
+	for i := 0; i < 30; i++ {
+		slow.Process(src, time.Second/30)
+	}
+	for i := 0; i < 120; i++ {
+		fast.Process(src, time.Second/120)
+	}
+
+	// then
+	slowX, fastX := slowCam.LookAt().X, fastCam.LookAt().X
+	assert.That(
+		math.Abs(slowX-fastX) < 0.05*fastX,
+		t.Errorf, "moved by %v at 30 updates per second and by %v at 120", slowX, fastX)
+}
+
+func TestCameraControllerFollowsATarget(t *testing.T) {
+	// given
+	src := testinput.Source{}
+	src.Mock.Focused = func() bool { return true }
+
+	target := geometry.V(100, 40)
+	cam := ui.Camera{}
+	cont := ui.NewCamController(&cam)
+	cont.Follow(func() geometry.Vec { return target })
+
+	// when
+	cont.Process(src, time.Second/60)
+	afterOne := cam.LookAt()
+	for i := 0; i < 5*60; i++ {
+		cont.Process(src, time.Second/60)
+	}
+
+	// then
+	assert.That(
+		afterOne != geometry.Vec{} && afterOne.Sub(target).Len() > 1,
+		t.Errorf, "after a single update looking at %#v, want to be partway to %#v", afterOne, target)
+	assertNear(t, cam.LookAt(), target)
+	assert.That(cont.Following(), t.Errorf, "the controller should still be following")
+}
+
+func TestCameraControllerStopsFollowingWhenMovedByHand(t *testing.T) {
+	// given
+	src := scrollingRight()
+	cam := ui.Camera{}
+	cont := ui.NewCamController(&cam)
+	cont.Follow(func() geometry.Vec { return geometry.V(-100, 0) })
+
+	// when
+	cont.Process(src, time.Second/60)
+
+	// then
+	assert.That(!cont.Following(), t.Errorf, "the controller should not be following")
+}
+
+func scrollingRight() testinput.Source {
+	src := testinput.Source{}
+	src.Mock.Focused = func() bool { return true }
+	src.Mock.MouseInsideWindow = func() bool { return true }
+	src.Mock.Bounds = func() geometry.Rect { return geometry.R(0, 0, 800, 600) }
+	src.Mock.MousePosition = func() geometry.Vec { return geometry.V(400, 300) }
+	src.Mock.Pressed = func(btn input.Button) bool { return btn == input.KeyRight() }
+	return src
+}
@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package draw
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// A Blend says how the colours of what gets drawn combine with what is already on the target.
+type Blend int
+
+const (
+	// BlendNormal draws over what is already there, letting it show through transparent parts.
+	BlendNormal Blend = iota
+	// BlendAdd adds colours to what is already there, making it lighter.
+	BlendAdd
+	// BlendMultiply multiplies colours with what is already there, making it darker.
+	BlendMultiply
+)
+
+// Options control how an image gets drawn.
+//
+// The zero value draws the whole image as it is.
+type Options struct {
+	source    geometry.Rect
+	hasSource bool
+
+	tint color.Color
+
+	// Stored as 1 - alpha so that the zero value is opaque.
+	transparency float64
+
+	blend Blend
+}
+
+func DefaultOptions() Options { return Options{} }
+
+// WithSource limits drawing to the part of the image within r.
+//
+// The rectangle uses the same coordinate system as the image bounds do.
+// The part drawn ends up where it would if the whole image was drawn.
+func (o Options) WithSource(r geometry.Rect) Options {
+	o.source = r
+	o.hasSource = true
+	return o
+}
+
+// Source is the part of the image to draw.
+// It reports false when the whole image should be drawn.
+func (o Options) Source() (r geometry.Rect, ok bool) { return o.source, o.hasSource }
+
+// WithTint multiplies the colour of every drawn pixel by c.
+// The transparency of c gets applied too.
+func (o Options) WithTint(c color.Color) Options {
+	o.tint = c
+	return o
+}
+
+// Tint is the colour that drawn pixels get multiplied by.
+// It is opaque white unless set otherwise.
+func (o Options) Tint() color.Color {
+	if o.tint == nil {
+		return color.White
+	}
+	return o.tint
+}
+
+// WithAlpha makes the drawn image partially transparent.
+// The alpha gets clamped between 0 (invisible) and 1 (opaque).
+func (o Options) WithAlpha(alpha float64) Options {
+	o.transparency = 1 - math.Max(0, math.Min(1, alpha))
+	return o
+}
+
+// Alpha is how opaque the drawn image is, from 0 (invisible) to 1 (opaque).
+func (o Options) Alpha() float64 { return 1 - o.transparency }
+
+func (o Options) WithBlend(b Blend) Options {
+	o.blend = b
+	return o
+}
+
+func (o Options) Blend() Blend { return o.blend }
+
+// ColorScale combines the tint and alpha into factors to multiply the channels of each drawn pixel by.
+// The factors apply to colours that are not alpha-premultiplied.
+func (o Options) ColorScale() (r, g, b, a float64) {
+	tint := color.NRGBAModel.Convert(o.Tint()).(color.NRGBA)
+	const max = 0xff
+	return float64(tint.R) / max,
+		float64(tint.G) / max,
+		float64(tint.B) / max,
+		float64(tint.A) / max * o.Alpha()
+}
@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package draw_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+func TestDefaultOptionsDrawTheWholeImageAsItIs(t *testing.T) {
+	// given
+	// when
+	opts := draw.DefaultOptions()
+
+	// then
+	_, hasSource := opts.Source()
+	r, g, b, a := opts.ColorScale()
+	assert.Using(t.Errorf).
+		That(!hasSource, "default options should not have a source rectangle").
+		That(theval.Equal(opts.Alpha(), 1.0)).
+		That(theval.Equal(opts.Blend(), draw.BlendNormal)).
+		That(theval.Equal([4]float64{r, g, b, a}, [4]float64{1, 1, 1, 1}))
+}
+
+func TestOptionsWithSource(t *testing.T) {
+	// given
+	want := geometry.R(2, 3, 10, 20)
+
+	// when
+	opts := draw.DefaultOptions().WithSource(want)
+
+	// then
+	r, ok := opts.Source()
+	assert.Using(t.Errorf).
+		That(ok, "options should have a source rectangle").
+		That(theval.Equal(r, want))
+}
+
+func TestOptionsAlphaIsClamped(t *testing.T) {
+	kases := map[string]struct {
+		Alpha, Want float64
+	}{
+		"Opaque":      {Alpha: 1, Want: 1},
+		"Half":        {Alpha: 0.5, Want: 0.5},
+		"Invisible":   {Alpha: 0, Want: 0},
+		"BelowZero":   {Alpha: -3, Want: 0},
+		"AboveOpaque": {Alpha: 2, Want: 1},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			opts := draw.DefaultOptions().WithAlpha(tt.Alpha)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(opts.Alpha(), tt.Want))
+		})
+	}
+}
+
+func TestOptionsColorScaleCombinesTintAndAlpha(t *testing.T) {
+	// given
+	tint := color.NRGBA{R: 0xff, G: 0, B: 0x33, A: 0xff}
+
+	// when
+	opts := draw.DefaultOptions().WithTint(tint).WithAlpha(0.5)
+
+	// then
+	r, g, b, a := opts.ColorScale()
+	assert.Using(t.Errorf).That(theval.Equal([4]float64{r, g, b, a}, [4]float64{1, 0, 0.2, 0.5}))
+}
+
+func TestOptionsColorScaleUsesTransparencyOfTheTint(t *testing.T) {
+	// given
+	tint := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x33}
+
+	// when
+	opts := draw.DefaultOptions().WithTint(tint)
+
+	// then
+	_, _, _, a := opts.ColorScale()
+	assert.Using(t.Errorf).That(theval.Equal(a, 0.2))
+}
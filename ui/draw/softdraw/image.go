@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package softdraw
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+type _Image struct {
+	dst *Target
+	src image.Image
+	// The part of src that is used, in the coordinate system of the bounds of src.
+	region geometry.Rect
+}
+
+var _ draw.Image = _Image{}
+
+func (img _Image) Bounds() geometry.Rect {
+	return geometry.R(0, 0, img.region.W(), img.region.H())
+}
+
+func (img _Image) Draw(m geometry.Mat, anchor geometry.Vec) {
+	img.DrawWith(m, anchor, draw.DefaultOptions())
+}
+
+func (img _Image) DrawWith(m geometry.Mat, anchor geometry.Vec, opts draw.Options) {
+	visible := img.Bounds()
+	if r, ok := opts.Source(); ok {
+		visible = geometry.Rect{Min: visible.Clamp(r.Min), Max: visible.Clamp(r.Max)}
+	}
+
+	toTarget := img.dst.matrix.Compose(m).Compose(geometry.Translation(anchor.Scaled(-1)))
+	scaleR, scaleG, scaleB, scaleA := opts.ColorScale()
+
+	img.dst.paint(transformRect(toTarget, visible), toTarget.Invert(), func(local geometry.Vec) (color.RGBA64, bool) {
+		if !covers(visible, local) {
+			return color.RGBA64{}, false
+		}
+		c := color.RGBA64Model.Convert(img.pixelAt(local)).(color.RGBA64)
+		// The colour is alpha-premultiplied, so the alpha scales all the channels.
+		return color.RGBA64{
+			R: uint16(float64(c.R) * scaleR * scaleA),
+			G: uint16(float64(c.G) * scaleG * scaleA),
+			B: uint16(float64(c.B) * scaleB * scaleA),
+			A: uint16(float64(c.A) * scaleA),
+		}, true
+	}, opts.Blend())
+}
+
+// covers says whether pt is inside r, counting in the bottom and left edges only.
+// That way neighbouring pixels are never both covered.
+func covers(r geometry.Rect, pt geometry.Vec) bool {
+	return r.Min.X <= pt.X && pt.X < r.Max.X &&
+		r.Min.Y <= pt.Y && pt.Y < r.Max.Y
+}
+
+// pixelAt finds the pixel of the source image at a point in the coordinate system of the bounds.
+func (img _Image) pixelAt(pt geometry.Vec) color.Color {
+	srcBounds := img.src.Bounds()
+	// The Y axis of the bounds points up, while that of the source image points down.
+	x := srcBounds.Min.X + int(math.Floor(img.region.Min.X+pt.X))
+	y := srcBounds.Max.Y - 1 - int(math.Floor(img.region.Min.Y+pt.Y))
+	return img.src.At(x, y)
+}
+
+func (img _Image) SubImage(r geometry.Rect) draw.Image {
+	offset := img.region.Min
+	img.region = geometry.Rect{
+		Min: img.region.Clamp(r.Min.Add(offset)),
+		Max: img.region.Clamp(r.Max.Add(offset)),
+	}
+	return img
+}
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package softdraw
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+func (t *Target) FillRect(r geometry.Rect, c color.Color) {
+	t.shape(r, c, r.Contains)
+}
+
+func (t *Target) StrokeRect(r geometry.Rect, width float64, c color.Color) {
+	half := math.Abs(width) / 2
+	outer := grow(r, half)
+	inner := grow(r, -half)
+	t.shape(outer, c, func(pt geometry.Vec) bool {
+		return outer.Contains(pt) && !strictlyInside(inner, pt)
+	})
+}
+
+func (t *Target) Line(from, to geometry.Vec, width float64, c color.Color) {
+	t.Polyline([]geometry.Vec{from, to}, width, c)
+}
+
+func (t *Target) Polyline(points []geometry.Vec, width float64, c color.Color) {
+	if len(points) < 2 {
+		return
+	}
+	half := math.Abs(width) / 2
+	area := grow(geometry.Bounding(points...), half)
+	t.shape(area, c, func(pt geometry.Vec) bool {
+		for i := 1; i < len(points); i++ {
+			if distanceToSegment(pt, points[i-1], points[i]) <= half {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (t *Target) FillCircle(center geometry.Vec, radius float64, c color.Color) {
+	area := grow(geometry.Rect{Min: center, Max: center}, radius)
+	t.shape(area, c, func(pt geometry.Vec) bool {
+		return pt.Sub(center).Len() <= radius
+	})
+}
+
+func (t *Target) StrokeCircle(center geometry.Vec, radius, width float64, c color.Color) {
+	half := math.Abs(width) / 2
+	area := grow(geometry.Rect{Min: center, Max: center}, radius+half)
+	t.shape(area, c, func(pt geometry.Vec) bool {
+		return math.Abs(pt.Sub(center).Len()-radius) <= half
+	})
+}
+
+// shape fills the pixels covered according to the inside function.
+// The function gets points in the coordinates the shape was described in.
+// The area should contain the whole shape, in the same coordinates.
+func (t *Target) shape(area geometry.Rect, c color.Color, inside func(pt geometry.Vec) bool) {
+	rgba := color.RGBA64Model.Convert(c).(color.RGBA64)
+	onTarget := transformRect(t.matrix, area)
+	t.paint(onTarget, t.matrix.Invert(), func(local geometry.Vec) (color.RGBA64, bool) {
+		return rgba, inside(local)
+	}, draw.BlendNormal)
+}
+
+func transformRect(m geometry.Mat, r geometry.Rect) geometry.Rect {
+	return geometry.Bounding(
+		m.Apply(r.Min),
+		m.Apply(geometry.V(r.Max.X, r.Min.Y)),
+		m.Apply(r.Max),
+		m.Apply(geometry.V(r.Min.X, r.Max.Y)))
+}
+
+func grow(r geometry.Rect, by float64) geometry.Rect {
+	offset := geometry.V(by, by)
+	return geometry.Rect{Min: r.Min.Sub(offset), Max: r.Max.Add(offset)}
+}
+
+func strictlyInside(r geometry.Rect, pt geometry.Vec) bool {
+	return r.Min.X < pt.X && pt.X < r.Max.X &&
+		r.Min.Y < pt.Y && pt.Y < r.Max.Y
+}
+
+func distanceToSegment(pt, a, b geometry.Vec) float64 {
+	ab, ap := b.Sub(a), pt.Sub(a)
+	lenSq := ab.X*ab.X + ab.Y*ab.Y
+	if lenSq == 0 {
+		return ap.Len()
+	}
+	t := (ap.X*ab.X + ap.Y*ab.Y) / lenSq
+	t = math.Max(0, math.Min(1, t))
+	closest := a.Add(ab.Scaled(t))
+	return pt.Sub(closest).Len()
+}
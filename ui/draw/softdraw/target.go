@@ -0,0 +1,134 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package softdraw draws onto an in-memory image, one pixel at a time.
+//
+// It is slow, but it needs no graphics card.
+// That lets tests check what ends up on the screen.
+package softdraw
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// A Target draws onto an in-memory image.
+//
+// Like any other target it uses coordinates with the Y axis pointing up.
+// The origin is at the bottom-left corner of the image.
+type Target struct {
+	img    *image.RGBA
+	matrix geometry.Mat
+}
+
+var _ draw.Target = new(Target)
+
+// NewTarget creates a target that draws onto a transparent image of the given size.
+func NewTarget(width, height int) *Target {
+	return &Target{
+		img:    image.NewRGBA(image.Rect(0, 0, width, height)),
+		matrix: geometry.Identity(),
+	}
+}
+
+// Image is what has been drawn so far.
+// Its Y axis points down, like that of any other image.Image.
+func (t *Target) Image() *image.RGBA { return t.img }
+
+// At is the colour of the pixel that contains pt.
+// It is transparent black outside of the image.
+func (t *Target) At(pt geometry.Vec) color.RGBA {
+	x, y := int(math.Floor(pt.X)), t.img.Rect.Dy()-1-int(math.Floor(pt.Y))
+	return t.img.RGBAAt(x, y)
+}
+
+// Bounds of the target, in its own coordinates.
+func (t *Target) Bounds() geometry.Rect {
+	size := t.img.Rect.Size()
+	return geometry.R(0, 0, float64(size.X), float64(size.Y))
+}
+
+func (t *Target) Clear(c color.Color) {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	for i := 0; i < len(t.img.Pix); i += 4 {
+		t.img.Pix[i+0] = rgba.R
+		t.img.Pix[i+1] = rgba.G
+		t.img.Pix[i+2] = rgba.B
+		t.img.Pix[i+3] = rgba.A
+	}
+}
+
+func (t *Target) SetMatrix(m geometry.Mat) {
+	if m.Zero() {
+		m = geometry.Identity()
+	}
+	t.matrix = m
+}
+
+func (t *Target) Import(img image.Image) draw.Image {
+	size := img.Bounds().Size()
+	return _Image{
+		dst:    t,
+		src:    img,
+		region: geometry.R(0, 0, float64(size.X), float64(size.Y)),
+	}
+}
+
+// paint blends c into every pixel whose center is covered.
+// The covered function gets the pixel center transformed by toLocal.
+// Only pixels within area (in target coordinates) get checked.
+func (t *Target) paint(
+	area geometry.Rect,
+	toLocal geometry.Mat,
+	covered func(local geometry.Vec) (c color.RGBA64, ok bool),
+	blend draw.Blend,
+) {
+	bounds := t.Bounds()
+	area = geometry.Rect{Min: bounds.Clamp(area.Min), Max: bounds.Clamp(area.Max)}
+	height := t.img.Rect.Dy()
+
+	for x := int(math.Floor(area.Min.X)); float64(x) < area.Max.X; x++ {
+		for y := int(math.Floor(area.Min.Y)); float64(y) < area.Max.Y; y++ {
+			center := geometry.V(float64(x)+0.5, float64(y)+0.5)
+			c, ok := covered(toLocal.Apply(center))
+			if !ok {
+				continue
+			}
+			row := height - 1 - y
+			under := t.img.RGBA64At(x, row)
+			t.img.SetRGBA64(x, row, blendColors(c, under, blend))
+		}
+	}
+}
+
+// blendColors combines alpha-premultiplied colours.
+func blendColors(src, dst color.RGBA64, blend draw.Blend) color.RGBA64 {
+	const max = 0xffff
+	s := [4]float64{float64(src.R), float64(src.G), float64(src.B), float64(src.A)}
+	d := [4]float64{float64(dst.R), float64(dst.G), float64(dst.B), float64(dst.A)}
+	sa := s[3] / max
+
+	var out [4]float64
+	for i := range out {
+		switch blend {
+		case draw.BlendAdd:
+			out[i] = s[i] + d[i]
+		case draw.BlendMultiply:
+			if i == 3 {
+				out[i] = d[i]
+			} else {
+				out[i] = s[i]*d[i]/max + d[i]*(1-sa)
+			}
+		default:
+			out[i] = s[i] + d[i]*(1-sa)
+		}
+		out[i] = math.Round(math.Max(0, math.Min(max, out[i])))
+	}
+
+	return color.RGBA64{uint16(out[0]), uint16(out[1]), uint16(out[2]), uint16(out[3])}
+}
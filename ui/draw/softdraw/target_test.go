@@ -0,0 +1,277 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package softdraw_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/draw/softdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+var (
+	transparent = color.RGBA{}
+	black       = color.RGBA{A: 0xff}
+	red         = color.RGBA{R: 0xff, A: 0xff}
+	green       = color.RGBA{G: 0xff, A: 0xff}
+	blue        = color.RGBA{B: 0xff, A: 0xff}
+)
+
+func TestNewTargetIsTransparent(t *testing.T) {
+	// given
+	// when
+	tgt := softdraw.NewTarget(4, 3)
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(tgt.At(geometry.V(1, 1)), transparent))
+}
+
+func TestClear(t *testing.T) {
+	// given
+	tgt := softdraw.NewTarget(4, 3)
+
+	// when
+	tgt.Clear(red)
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(tgt.At(geometry.V(0, 0)), red)).
+		That(theval.Equal(tgt.At(geometry.V(3, 2)), red))
+}
+
+func TestFillRect(t *testing.T) {
+	kases := map[string]struct {
+		Matrix geometry.Mat
+		At     geometry.Vec
+		Color  color.RGBA
+	}{
+		"Inside":                 {At: geometry.V(3, 2), Color: red},
+		"Outside":                {At: geometry.V(6, 2), Color: black},
+		"InsideWhenTranslated":   {Matrix: geometry.Translation(geometry.V(3, 3)), At: geometry.V(6, 5), Color: red},
+		"OutsideWhenTranslated":  {Matrix: geometry.Translation(geometry.V(3, 3)), At: geometry.V(3, 2), Color: black},
+		"InsideWhenScaled":       {Matrix: geometry.Scale(2), At: geometry.V(8, 6), Color: red},
+		"OutsideWhenNotScaled":   {At: geometry.V(8, 6), Color: black},
+		"TopLeftCornerIsFilled":  {At: geometry.V(2, 3), Color: red},
+		"TopRightCornerIsFilled": {At: geometry.V(4, 3), Color: red},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			tgt := softdraw.NewTarget(10, 10)
+			tgt.Clear(black)
+			tgt.SetMatrix(tt.Matrix)
+
+			// when
+			tgt.FillRect(geometry.R(2, 1, 3, 3), red)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(tgt.At(tt.At), tt.Color))
+		})
+	}
+}
+
+func TestStrokeRect(t *testing.T) {
+	kases := map[string]struct {
+		At    geometry.Vec
+		Color color.RGBA
+	}{
+		"OnTheLeftEdge":   {At: geometry.V(2, 5), Color: red},
+		"OnTheBottomEdge": {At: geometry.V(5, 2), Color: red},
+		"Inside":          {At: geometry.V(5, 5), Color: black},
+		"Outside":         {At: geometry.V(0, 0), Color: black},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			tgt := softdraw.NewTarget(10, 10)
+			tgt.Clear(black)
+
+			// when
+			tgt.StrokeRect(geometry.R(2.5, 2.5, 6, 6), 1, red)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(tgt.At(tt.At), tt.Color))
+		})
+	}
+}
+
+func TestLine(t *testing.T) {
+	kases := map[string]struct {
+		At    geometry.Vec
+		Color color.RGBA
+	}{
+		"AtTheStart":       {At: geometry.V(1, 1), Color: green},
+		"InTheMiddle":      {At: geometry.V(5, 5), Color: green},
+		"AtTheEnd":         {At: geometry.V(8, 8), Color: green},
+		"NextToTheLine":    {At: geometry.V(7, 4), Color: black},
+		"PastTheEnd":       {At: geometry.V(9.5, 9.5), Color: black},
+		"BeforeTheStartTo": {At: geometry.V(0, 0), Color: black},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			tgt := softdraw.NewTarget(10, 10)
+			tgt.Clear(black)
+
+			// when
+			tgt.Line(geometry.V(1.5, 1.5), geometry.V(8.5, 8.5), 1, green)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(tgt.At(tt.At), tt.Color))
+		})
+	}
+}
+
+func TestPolyline(t *testing.T) {
+	// given
+	tgt := softdraw.NewTarget(10, 10)
+	tgt.Clear(black)
+
+	// when
+	tgt.Polyline([]geometry.Vec{
+		geometry.V(1.5, 1.5),
+		geometry.V(8.5, 1.5),
+		geometry.V(8.5, 8.5),
+	}, 1, blue)
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(tgt.At(geometry.V(4, 1)), blue)).
+		That(theval.Equal(tgt.At(geometry.V(8, 5)), blue)).
+		That(theval.Equal(tgt.At(geometry.V(4, 5)), black))
+}
+
+func TestCircles(t *testing.T) {
+	kases := map[string]struct {
+		Draw  func(tgt *softdraw.Target)
+		At    geometry.Vec
+		Color color.RGBA
+	}{
+		"Filled/Center": {
+			Draw:  func(tgt *softdraw.Target) { tgt.FillCircle(geometry.V(5, 5), 3, red) },
+			At:    geometry.V(5, 5),
+			Color: red,
+		},
+		"Filled/NearTheEdge": {
+			Draw:  func(tgt *softdraw.Target) { tgt.FillCircle(geometry.V(5, 5), 3, red) },
+			At:    geometry.V(7, 5),
+			Color: red,
+		},
+		"Filled/OutsideTheBoundingBoxCorner": {
+			Draw:  func(tgt *softdraw.Target) { tgt.FillCircle(geometry.V(5, 5), 3, red) },
+			At:    geometry.V(7.5, 7.5),
+			Color: black,
+		},
+		"Stroked/Center": {
+			Draw:  func(tgt *softdraw.Target) { tgt.StrokeCircle(geometry.V(5, 5), 3, 1, red) },
+			At:    geometry.V(5, 5),
+			Color: black,
+		},
+		"Stroked/OnTheCircle": {
+			Draw:  func(tgt *softdraw.Target) { tgt.StrokeCircle(geometry.V(5, 5), 3, 1, red) },
+			At:    geometry.V(7.5, 5),
+			Color: red,
+		},
+		"Stretched/InsideTheEllipse": {
+			Draw: func(tgt *softdraw.Target) {
+				tgt.SetMatrix(geometry.Mat{{2, 0, 0}, {0, 1, 0}})
+				tgt.FillCircle(geometry.V(2.5, 5), 2, red)
+			},
+			At:    geometry.V(8.5, 5),
+			Color: red,
+		},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			tgt := softdraw.NewTarget(10, 10)
+			tgt.Clear(black)
+
+			// when
+			tt.Draw(tgt)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(tgt.At(tt.At), tt.Color))
+		})
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	kases := map[string]struct {
+		Matrix  geometry.Mat
+		Anchor  geometry.Vec
+		Options draw.Options
+		At      geometry.Vec
+		Color   color.RGBA
+	}{
+		"TopLeftPixel":           {Matrix: geometry.Identity(), At: geometry.V(0, 1), Color: red},
+		"BottomLeftPixel":        {Matrix: geometry.Identity(), At: geometry.V(0, 0), Color: green},
+		"BottomRightPixel":       {Matrix: geometry.Identity(), At: geometry.V(1, 0), Color: blue},
+		"OutsideTheImage":        {Matrix: geometry.Identity(), At: geometry.V(2, 0), Color: black},
+		"Translated":             {Matrix: geometry.Translation(geometry.V(3, 3)), At: geometry.V(3, 4), Color: red},
+		"Anchored":               {Matrix: geometry.Translation(geometry.V(3, 3)), Anchor: geometry.V(1, 1), At: geometry.V(2, 3), Color: red},
+		"Scaled":                 {Matrix: geometry.Scale(2), At: geometry.V(1, 3), Color: red},
+		"Tinted":                 {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithTint(color.RGBA{R: 0xff, A: 0xff}), At: geometry.V(0, 0), Color: black},
+		"Faded":                  {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithAlpha(0), At: geometry.V(0, 1), Color: black},
+		"Source/Inside":          {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithSource(geometry.R(1, 0, 1, 1)), At: geometry.V(1, 0), Color: blue},
+		"Source/Outside":         {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithSource(geometry.R(1, 0, 1, 1)), At: geometry.V(0, 0), Color: black},
+		"Blend/Add":              {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithBlend(draw.BlendAdd), At: geometry.V(0, 1), Color: red},
+		"Blend/MultiplyByBlack":  {Matrix: geometry.Identity(), Options: draw.DefaultOptions().WithBlend(draw.BlendMultiply), At: geometry.V(0, 1), Color: black},
+		"SubImage/StaysInBounds": {Matrix: geometry.Identity(), At: geometry.V(0, 0), Color: green},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			tgt := softdraw.NewTarget(10, 10)
+			tgt.Clear(black)
+			img := tgt.Import(twoByTwo())
+
+			// when
+			img.DrawWith(tt.Matrix, tt.Anchor, tt.Options)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(tgt.At(tt.At), tt.Color))
+		})
+	}
+}
+
+func TestDrawSubImage(t *testing.T) {
+	// given
+	tgt := softdraw.NewTarget(10, 10)
+	tgt.Clear(black)
+	img := tgt.Import(twoByTwo())
+
+	// when
+	sub := img.SubImage(geometry.R(0, 1, 2, 1))
+	sub.Draw(geometry.Identity(), geometry.Vec{})
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(sub.Bounds(), geometry.R(0, 0, 2, 1))).
+		That(theval.Equal(tgt.At(geometry.V(0, 0)), red)).
+		That(theval.Equal(tgt.At(geometry.V(1, 0)), red)).
+		That(theval.Equal(tgt.At(geometry.V(0, 1)), black))
+}
+
+// twoByTwo is an image with a red top row, and green then blue pixel in the bottom row.
+func twoByTwo() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, red)
+	img.SetRGBA(1, 0, red)
+	img.SetRGBA(0, 1, green)
+	img.SetRGBA(1, 1, blue)
+	return img
+}
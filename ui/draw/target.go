@@ -15,13 +15,41 @@ type Target interface {
 	Clear(c color.Color)
 	SetMatrix(m geometry.Mat)
 	Import(img image.Image) Image
+
+	Shapes
+}
+
+// Shapes can be drawn without importing any images.
+//
+// All coordinates get transformed by the current target-wide matrix.
+// Stroke widths use the same units as the coordinates do, so they scale along with the shapes.
+type Shapes interface {
+	FillRect(r geometry.Rect, c color.Color)
+	StrokeRect(r geometry.Rect, width float64, c color.Color)
+
+	Line(from, to geometry.Vec, width float64, c color.Color)
+	// Polyline draws lines connecting consecutive points.
+	Polyline(points []geometry.Vec, width float64, c color.Color)
+
+	FillCircle(center geometry.Vec, radius float64, c color.Color)
+	StrokeCircle(center geometry.Vec, radius, width float64, c color.Color)
 }
 
 type Image interface {
+	// Bounds of the image.
+	// The Y axis points up, so the bottom-left corner of the image is the minimum.
 	Bounds() geometry.Rect
 
 	// Draw the image on the target that imported it.
 	// The drawn image is transforming by the matrix m and then by the current target-wide matrix.
 	// The anchor is understood to use the same coordinate system as the bounds do.
 	Draw(m geometry.Mat, anchor geometry.Vec)
+
+	// DrawWith works like Draw, but lets the options change how the image is drawn.
+	DrawWith(m geometry.Mat, anchor geometry.Vec, opts Options)
+
+	// SubImage returns the part of the image within r.
+	// The rectangle uses the same coordinate system as the bounds do.
+	// The bounds of the returned image have their minimum at the origin.
+	SubImage(r geometry.Rect) Image
 }
@@ -12,31 +12,138 @@ import (
 	"github.com/szabba/tob-cob/ui/geometry"
 )
 
-type Target struct{}
+// A Target records what gets drawn on it, without drawing anything.
+//
+// The zero value is ready to use.
+type Target struct {
+	// Draws are the images drawn so far, in order.
+	Draws []Draw
+	// Shapes are the shapes drawn so far, in order.
+	Shapes []Shape
+
+	matrix geometry.Mat
+}
+
+// A Draw records an image being drawn.
+type Draw struct {
+	Image draw.Image
+	// Matrix is the target-wide matrix composed with the one the image was drawn with.
+	Matrix  geometry.Mat
+	Anchor  geometry.Vec
+	Options draw.Options
+}
+
+// A Shape records a shape being drawn.
+type Shape struct {
+	Kind ShapeKind
+	// Points are the corners of the rectangle (minimum first), the points of the line, or the center of the circle.
+	Points []geometry.Vec
+	Radius float64
+	// Width of the stroke, zero for filled shapes.
+	Width float64
+	Color color.Color
+	// Matrix is the target-wide matrix at the time of drawing.
+	Matrix geometry.Mat
+}
+
+// A ShapeKind says what method was used to draw a shape.
+type ShapeKind int
+
+const (
+	FillRectShape ShapeKind = iota
+	StrokeRectShape
+	LineShape
+	PolylineShape
+	FillCircleShape
+	StrokeCircleShape
+)
 
 var _ draw.Target = new(Target)
 
-func (*Target) Clear(_ color.Color)      {}
-func (*Target) SetMatrix(_ geometry.Mat) {}
+func (*Target) Clear(_ color.Color) {}
 
-func (*Target) Import(img image.Image) draw.Image {
+func (t *Target) SetMatrix(m geometry.Mat) { t.matrix = m }
+
+func (t *Target) Import(img image.Image) draw.Image {
+	stdlib := img.Bounds()
 	return _Image{
+		dst: t,
 		img: img,
+		region: geometry.R(
+			0, 0,
+			float64(stdlib.Dx()),
+			float64(stdlib.Dy())),
 	}
 }
 
+func (t *Target) FillRect(r geometry.Rect, c color.Color) {
+	t.record(Shape{Kind: FillRectShape, Points: []geometry.Vec{r.Min, r.Max}, Color: c})
+}
+
+func (t *Target) StrokeRect(r geometry.Rect, width float64, c color.Color) {
+	t.record(Shape{Kind: StrokeRectShape, Points: []geometry.Vec{r.Min, r.Max}, Width: width, Color: c})
+}
+
+func (t *Target) Line(from, to geometry.Vec, width float64, c color.Color) {
+	t.record(Shape{Kind: LineShape, Points: []geometry.Vec{from, to}, Width: width, Color: c})
+}
+
+func (t *Target) Polyline(points []geometry.Vec, width float64, c color.Color) {
+	points = append([]geometry.Vec(nil), points...)
+	t.record(Shape{Kind: PolylineShape, Points: points, Width: width, Color: c})
+}
+
+func (t *Target) FillCircle(center geometry.Vec, radius float64, c color.Color) {
+	t.record(Shape{Kind: FillCircleShape, Points: []geometry.Vec{center}, Radius: radius, Color: c})
+}
+
+func (t *Target) StrokeCircle(center geometry.Vec, radius, width float64, c color.Color) {
+	t.record(Shape{Kind: StrokeCircleShape, Points: []geometry.Vec{center}, Radius: radius, Width: width, Color: c})
+}
+
+func (t *Target) record(s Shape) {
+	s.Matrix = t.currentMatrix()
+	t.Shapes = append(t.Shapes, s)
+}
+
+func (t *Target) currentMatrix() geometry.Mat {
+	if t.matrix.Zero() {
+		return geometry.Identity()
+	}
+	return t.matrix
+}
+
 type _Image struct {
+	dst *Target
 	img image.Image
+	// The part of img that is used, in the coordinate system of the bounds of img.
+	region geometry.Rect
 }
 
 var _ draw.Image = new(_Image)
 
 func (img _Image) Bounds() geometry.Rect {
-	stdlib := img.img.Bounds()
-	return geometry.R(
-		0, 0,
-		float64(stdlib.Dx()),
-		float64(stdlib.Dy()))
+	return geometry.R(0, 0, img.region.W(), img.region.H())
+}
+
+func (img _Image) Draw(m geometry.Mat, anchor geometry.Vec) {
+	img.DrawWith(m, anchor, draw.DefaultOptions())
 }
 
-func (_Image) Draw(_ geometry.Mat, _ geometry.Vec) {}
+func (img _Image) DrawWith(m geometry.Mat, anchor geometry.Vec, opts draw.Options) {
+	img.dst.Draws = append(img.dst.Draws, Draw{
+		Image:   img,
+		Matrix:  img.dst.currentMatrix().Compose(m),
+		Anchor:  anchor,
+		Options: opts,
+	})
+}
+
+func (img _Image) SubImage(r geometry.Rect) draw.Image {
+	offset := img.region.Min
+	img.region = geometry.Rect{
+		Min: img.region.Clamp(r.Min.Add(offset)),
+		Max: img.region.Clamp(r.Max.Add(offset)),
+	}
+	return img
+}
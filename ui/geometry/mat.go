@@ -4,7 +4,10 @@
 
 package geometry
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 type Mat [2][3]float64
 
@@ -28,6 +31,15 @@ func Scale(a float64) Mat {
 	return s
 }
 
+// Rotation is a counter-clockwise rotation around the origin by the given angle in radians.
+func Rotation(radians float64) Mat {
+	sin, cos := math.Sincos(radians)
+	return Mat{
+		{cos, -sin, 0},
+		{sin, cos, 0},
+	}
+}
+
 func (m Mat) Compose(o Mat) Mat {
 	return Mat{
 		{
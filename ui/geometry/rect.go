@@ -4,12 +4,28 @@
 
 package geometry
 
+import "math"
+
 type Rect struct{ Min, Max Vec }
 
 func R(x, y, w, h float64) Rect {
 	return Rect{Vec{x, y}, Vec{x + w, y + h}}
 }
 
+// Bounding is the smallest rectangle containing all the points.
+// It is the zero rectangle when there are no points.
+func Bounding(points ...Vec) Rect {
+	if len(points) == 0 {
+		return Rect{}
+	}
+	r := Rect{points[0], points[0]}
+	for _, p := range points[1:] {
+		r.Min = Vec{math.Min(r.Min.X, p.X), math.Min(r.Min.Y, p.Y)}
+		r.Max = Vec{math.Max(r.Max.X, p.X), math.Max(r.Max.Y, p.Y)}
+	}
+	return r
+}
+
 func (r Rect) Center() Vec {
 	return Vec{
 		(r.Max.X + r.Min.X) / 2,
@@ -20,3 +36,17 @@ func (r Rect) Center() Vec {
 func (r Rect) W() float64 { return r.Max.X - r.Min.X }
 
 func (r Rect) H() float64 { return r.Max.Y - r.Min.Y }
+
+// Clamp returns the point within the rectangle closest to v.
+func (r Rect) Clamp(v Vec) Vec {
+	return Vec{
+		math.Max(r.Min.X, math.Min(r.Max.X, v.X)),
+		math.Max(r.Min.Y, math.Min(r.Max.Y, v.Y)),
+	}
+}
+
+// Contains says whether v lies within the rectangle or on its edge.
+func (r Rect) Contains(v Vec) bool {
+	return r.Min.X <= v.X && v.X <= r.Max.X &&
+		r.Min.Y <= v.Y && v.Y <= r.Max.Y
+}
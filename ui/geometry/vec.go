@@ -4,6 +4,8 @@
 
 package geometry
 
+import "math"
+
 type Vec struct{ X, Y float64 }
 
 func V(x, y float64) Vec { return Vec{x, y} }
@@ -19,3 +21,10 @@ func (v Vec) Sub(o Vec) Vec {
 func (v Vec) Scaled(s float64) Vec {
 	return Vec{s * v.X, s * v.Y}
 }
+
+func (v Vec) Len() float64 { return math.Hypot(v.X, v.Y) }
+
+// Lerp interpolates linearly between v (at t == 0) and o (at t == 1).
+func (v Vec) Lerp(o Vec, t float64) Vec {
+	return v.Add(o.Sub(v).Scaled(t))
+}
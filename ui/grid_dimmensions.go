@@ -12,33 +12,150 @@ import (
 	"github.com/szabba/tob-cob/ui/input"
 )
 
+// A Projection says how the grid is laid out in world coordinates.
+type Projection int
+
+const (
+	// TopDown lays out cells as axis-aligned rectangles.
+	// Columns go right and rows go up.
+	TopDown Projection = iota
+
+	// Isometric lays out cells as diamonds.
+	// Columns go up and right, rows go up and left.
+	//
+	// The cell width and height are the width and height of the diamond.
+	Isometric
+)
+
+// GridDimensions describe how big cells are and how they are laid out in world coordinates.
 type GridDimensions struct {
 	CellWidth  float64
 	CellHeight float64
+	Projection Projection
+}
+
+// IsometricDimensions creates grid dimensions for a true isometric projection with diamonds cellWidth wide.
+// The diamonds' sides meet at 120 and 60 degree angles.
+func IsometricDimensions(cellWidth float64) GridDimensions {
+	return GridDimensions{
+		CellWidth:  cellWidth,
+		CellHeight: cellWidth / math.Sqrt(3),
+		Projection: Isometric,
+	}
+}
+
+// DimetricDimensions creates grid dimensions for the 2:1 projection common in pixel art with diamonds cellWidth wide.
+func DimetricDimensions(cellWidth float64) GridDimensions {
+	return GridDimensions{
+		CellWidth:  cellWidth,
+		CellHeight: cellWidth / 2,
+		Projection: Isometric,
+	}
 }
 
+// Matrix transforms a cell's own coordinate system into world coordinates.
+//
+// In the cell's own coordinate system the cell is an axis-aligned rectangle centered at the origin.
+// Top-down it is CellWidth wide and CellHeight high.
+// Isometric it is a square with CellWidth long sides that the matrix turns into a diamond.
 func (d GridDimensions) Matrix(col, row int) geometry.Mat {
 	x, y := float64(col), float64(row)
-	dx := d.CellWidth * x
-	dy := d.CellHeight * y
+	size := d.cellSize()
+	dx := size.X * x
+	dy := size.Y * y
 	dr := geometry.V(dx, dy)
-	return geometry.Translation(dr)
+	return d.projection().Compose(geometry.Translation(dr))
 }
 
-func (d GridDimensions) UnderCursor(src input.Source, cam Camera) grid.Point {
-	onScreen := src.MousePosition()
-	toWorld := cam.Matrix(src.Bounds()).Invert()
-	inWorld := toWorld.Apply(onScreen)
-	column := d.underCursor(inWorld.X, d.CellWidth)
-	row := d.underCursor(inWorld.Y, d.CellWidth)
+// Footing is where things standing in a cell touch the ground, relative to the cell center in world coordinates.
+// Top-down it is the middle of the cell's bottom edge, moved up by margin.
+// Isometric it is the middle of the diamond.
+func (d GridDimensions) Footing(margin float64) geometry.Vec {
+	if d.Projection == Isometric {
+		return geometry.Vec{}
+	}
+	return geometry.V(0, -d.CellHeight/2+math.Abs(margin))
+}
+
+// cellSize is the size of a cell in its own coordinate system.
+func (d GridDimensions) cellSize() geometry.Vec {
+	if d.Projection == Isometric {
+		return geometry.V(d.CellWidth, d.CellWidth)
+	}
+	return geometry.V(d.CellWidth, d.CellHeight)
+}
+
+// projection transforms cells laid out next to each other in their own coordinate systems into world coordinates.
+func (d GridDimensions) projection() geometry.Mat {
+	if d.Projection == Isometric {
+		squash := d.CellHeight / d.CellWidth
+		return geometry.Mat{
+			{0.5, -0.5, 0},
+			{0.5 * squash, 0.5 * squash, 0},
+		}
+	}
+	return geometry.Identity()
+}
+
+// CellToWorld is the center of the cell at pt, in world coordinates.
+func (d GridDimensions) CellToWorld(pt grid.Point) geometry.Vec {
+	return d.Matrix(pt.Column, pt.Row).Apply(geometry.Vec{})
+}
+
+// WorldToCell finds the cell that contains the point inWorld.
+//
+// Each cell contains its bottom and left edges, but not its top and right ones.
+// The edges are understood as they are in the cell's own coordinate system.
+func (d GridDimensions) WorldToCell(inWorld geometry.Vec) grid.Point {
+	inLayout := d.projection().Invert().Apply(inWorld)
+	size := d.cellSize()
+	column := d.nearestCell(inLayout.X, size.X)
+	row := d.nearestCell(inLayout.Y, size.Y)
 	return grid.P(row, column)
 }
 
-func (d GridDimensions) underCursor(cursor, dim float64) int {
-	if cursor < 0 {
-		return -d.underCursor(-cursor, dim)
+func (d GridDimensions) nearestCell(coord, dim float64) int {
+	return int(math.Floor(coord/dim + 0.5))
+}
+
+// CellRect is the smallest axis-aligned rectangle in world coordinates that contains the cell at pt.
+//
+// Top-down that is the cell itself.
+// Isometric it is the rectangle around the cell's diamond.
+func (d GridDimensions) CellRect(pt grid.Point) geometry.Rect {
+	return d.rectAround(pt, pt)
+}
+
+// Extent is the area in world coordinates covered by the cells between the space's Min and Max.
+// For an empty space it is the area of the cell at the origin.
+func (d GridDimensions) Extent(space *grid.Space) geometry.Rect {
+	return d.rectAround(space.Min(), space.Max())
+}
+
+func (d GridDimensions) rectAround(min, max grid.Point) geometry.Rect {
+	half := d.cellSize().Scaled(0.5)
+	lowest := d.Matrix(min.Column, min.Row).Apply(half.Scaled(-1))
+	highest := d.Matrix(max.Column, max.Row).Apply(half)
+	lowestHighest := d.Matrix(min.Column, max.Row).Apply(geometry.V(-half.X, half.Y))
+	highestLowest := d.Matrix(max.Column, min.Row).Apply(geometry.V(half.X, -half.Y))
+	return geometry.Bounding(lowest, highest, lowestHighest, highestLowest)
+}
+
+// PlacementCenter is the center of the cell a placement is at, in world coordinates.
+// While the placement is headed somewhere, the point moves towards the center of that cell as progress is made.
+func (d GridDimensions) PlacementCenter(placement *grid.HeadedPlacement) geometry.Vec {
+	at := d.CellToWorld(placement.AtPoint())
+	if !placement.Headed() {
+		return at
 	}
+	headedTo := d.CellToWorld(placement.Heading())
+	return at.Lerp(headedTo, placement.Progress())
+}
 
-	cell := math.Floor((cursor + dim/2) / dim)
-	return int(cell)
+// UnderCursor finds the cell under the mouse cursor when the world is seen through the camera.
+func (d GridDimensions) UnderCursor(src input.Source, cam Camera) grid.Point {
+	onScreen := src.MousePosition()
+	toWorld := cam.Matrix(src.Bounds()).Invert()
+	inWorld := toWorld.Apply(onScreen)
+	return d.WorldToCell(inWorld)
 }
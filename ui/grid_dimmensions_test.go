@@ -5,7 +5,12 @@
 package ui_test
 
 import (
+	"math"
+	"math/rand"
+	"reflect"
 	"testing"
+	"testing/quick"
+	"time"
 
 	"github.com/szabba/assert"
 	"github.com/szabba/tob-cob/game/grid"
@@ -86,13 +91,21 @@ func TestGridDimmensionsUnderCursor(t *testing.T) {
 		"AtOrigin": {},
 
 		"AtLeftEdgeOfOriginCell": {
-			MouseAt: func() geometry.Vec { return geometry.V(-dims.CellHeight/2, 0) },
+			MouseAt: func() geometry.Vec { return geometry.V(-dims.CellWidth/2, 0) },
 		},
 		"AtRightEdgeOfOriginCell": {
-			MouseAt: func() geometry.Vec { return geometry.V(dims.CellHeight/2, 0) },
+			MouseAt: func() geometry.Vec { return geometry.V(dims.CellWidth/2, 0) },
+			Cell:    grid.P(0, 1),
+		},
+		"JustLeftOfRightEdgeOfOriginCell": {
+			MouseAt: func() geometry.Vec { return geometry.V(dims.CellWidth/2-0.5, 0) },
 		},
 		"AtTopEdgeOfOriginCell": {
 			MouseAt: func() geometry.Vec { return geometry.V(0, dims.CellHeight/2) },
+			Cell:    grid.P(1, 0),
+		},
+		"JustBelowTopEdgeOfOriginCell": {
+			MouseAt: func() geometry.Vec { return geometry.V(0, dims.CellHeight/2-0.5) },
 		},
 		"AtBottomEdgeOfOriginCell": {
 			MouseAt: func() geometry.Vec { return geometry.V(0, -dims.CellHeight/2) },
@@ -110,19 +123,25 @@ func TestGridDimmensionsUnderCursor(t *testing.T) {
 			MouseAt: func() geometry.Vec { return geometry.V(0, dims.CellHeight) },
 			Cell:    grid.P(1, 0),
 		},
+		"AtMiddleOfSecondCellAboveOrigin": {
+			MouseAt: func() geometry.Vec { return geometry.V(0, 2*dims.CellHeight) },
+			Cell:    grid.P(2, 0),
+		},
 		"AtMiddleOfCellBellowOrigin": {
 			MouseAt: func() geometry.Vec { return geometry.V(0, -dims.CellHeight) },
 			Cell:    grid.P(-1, 0),
 		},
 
 		"LookingAtLeftEdgeOfOriginCell": {
-			LookingAt: geometry.V(-dims.CellHeight/2, 0),
+			LookingAt: geometry.V(-dims.CellWidth/2, 0),
 		},
 		"LookingAtRightEdgeOfOriginCell": {
-			LookingAt: geometry.V(dims.CellHeight/2, 0),
+			LookingAt: geometry.V(dims.CellWidth/2, 0),
+			Cell:      grid.P(0, 1),
 		},
 		"LookingAtTopEdgeOfOriginCell": {
 			LookingAt: geometry.V(0, dims.CellHeight/2),
+			Cell:      grid.P(1, 0),
 		},
 		"LookingAtBottomEdgeOfOriginCell": {
 			LookingAt: geometry.V(0, -dims.CellHeight/2),
@@ -162,3 +181,316 @@ func TestGridDimmensionsUnderCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestGridDimmensionsUnderCursorWithZoomedCamera(t *testing.T) {
+	// given
+	dims := ui.GridDimensions{CellWidth: 20, CellHeight: 20}
+
+	input := testinput.Source{}
+	input.Mock.MousePosition = func() geometry.Vec { return geometry.V(2*dims.CellWidth, 0) }
+
+	cam := ui.Camera{}
+	cam.ZoomBy(2)
+
+	// when
+	cell := dims.UnderCursor(input, cam)
+
+	// then
+	want := grid.P(0, 1)
+	assert.That(cell == want, t.Errorf, "got cell %#v, want %#v", cell, want)
+}
+
+func TestGridDimmensionsExtentCoversAllCells(t *testing.T) {
+	// given
+	dims := ui.GridDimensions{CellWidth: 20, CellHeight: 10}
+	space := grid.NewSpace()
+	space.At(grid.P(-1, 2)).Create()
+	space.At(grid.P(3, -4)).Create()
+
+	// when
+	extent := dims.Extent(space)
+
+	// then
+	want := geometry.Rect{Min: geometry.V(-90, -15), Max: geometry.V(50, 35)}
+	assert.That(extent == want, t.Errorf, "got extent %#v, want %#v", extent, want)
+}
+
+func TestGridDimmensionsPlacementCenter(t *testing.T) {
+	dims := ui.GridDimensions{CellWidth: 20, CellHeight: 10}
+	tests := map[string]struct {
+		Elapsed time.Duration
+		Center  geometry.Vec
+	}{
+		"BeforeMoving":  {Center: geometry.V(20, 0)},
+		"HalfwayMoving": {Elapsed: time.Second / 2, Center: geometry.V(30, 0)},
+		"AfterMoving":   {Elapsed: time.Second, Center: geometry.V(40, 0)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space := grid.NewSpace()
+			src, dst := space.At(grid.P(0, 1)), space.At(grid.P(0, 2))
+			src.Create()
+			dst.Create()
+
+			placement := grid.HeadedPlacement{}
+			placement.Place(src)
+			action := placement.MoveTo(dst, time.Second)
+
+			// when
+			if tt.Elapsed > 0 {
+				action.Run(tt.Elapsed)
+			}
+
+			// then
+			center := dims.PlacementCenter(&placement)
+			assert.That(center == tt.Center, t.Errorf, "got center %#v, want %#v", center, tt.Center)
+		})
+	}
+}
+
+func TestIsometricGridDimmensionsMatrix(t *testing.T) {
+	dims := ui.DimetricDimensions(20)
+	tests := map[string]struct {
+		Column, Row int
+
+		RelativeToCell  geometry.Vec
+		RelativeToWorld geometry.Vec
+	}{
+		"MiddleOfOriginCell": {},
+
+		"TopCornerOfOriginCell": {
+			RelativeToCell:  geometry.V(10, 10),
+			RelativeToWorld: geometry.V(0, 5),
+		},
+		"RightCornerOfOriginCell": {
+			RelativeToCell:  geometry.V(10, -10),
+			RelativeToWorld: geometry.V(10, 0),
+		},
+		"BottomCornerOfOriginCell": {
+			RelativeToCell:  geometry.V(-10, -10),
+			RelativeToWorld: geometry.V(0, -5),
+		},
+		"LeftCornerOfOriginCell": {
+			RelativeToCell:  geometry.V(-10, 10),
+			RelativeToWorld: geometry.V(-10, 0),
+		},
+
+		"MiddleOfNextColumn": {
+			Column:          1,
+			RelativeToWorld: geometry.V(10, 5),
+		},
+		"MiddleOfNextRow": {
+			Row:             1,
+			RelativeToWorld: geometry.V(-10, 5),
+		},
+		"MiddleOfCellStraightAbove": {
+			Column:          1,
+			Row:             1,
+			RelativeToWorld: geometry.V(0, 10),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+
+			// when
+			matrix := dims.Matrix(tt.Column, tt.Row)
+
+			// then
+			relativeToWorld := matrix.Apply(tt.RelativeToCell)
+			assert.That(
+				relativeToWorld == tt.RelativeToWorld,
+				t.Errorf, "got %#v world-coordinate, want %#v", relativeToWorld, tt.RelativeToWorld)
+		})
+	}
+}
+
+func TestTrueIsometricCellsHaveSidesMeetingAt120Degrees(t *testing.T) {
+	// given
+	dims := ui.IsometricDimensions(30)
+
+	// when
+	matrix := dims.Matrix(0, 0)
+
+	// then
+	left := matrix.Apply(geometry.V(-15, 15))
+	top := matrix.Apply(geometry.V(15, 15))
+	right := matrix.Apply(geometry.V(15, -15))
+	toLeft, toRight := left.Sub(top), right.Sub(top)
+	cos := (toLeft.X*toRight.X + toLeft.Y*toRight.Y) / (toLeft.Len() * toRight.Len())
+	angle := math.Acos(cos) * 180 / math.Pi
+	assert.That(math.Abs(angle-120) < 1e-9, t.Errorf, "got angle %v at the top corner, want %v", angle, 120)
+}
+
+func TestIsometricGridDimmensionsUnderCursor(t *testing.T) {
+	dims := ui.DimetricDimensions(20)
+	tests := map[string]struct {
+		MouseAt geometry.Vec
+		Cell    grid.Point
+	}{
+		"AtOrigin": {},
+
+		"NearTopCornerOfOriginCell":    {MouseAt: geometry.V(0, 4.5)},
+		"NearRightCornerOfOriginCell":  {MouseAt: geometry.V(9, 0)},
+		"NearBottomCornerOfOriginCell": {MouseAt: geometry.V(0, -4.5)},
+		"NearLeftCornerOfOriginCell":   {MouseAt: geometry.V(-9, 0)},
+
+		"PastTopCornerOfOriginCell":    {MouseAt: geometry.V(0, 5.5), Cell: grid.P(1, 1)},
+		"PastRightCornerOfOriginCell":  {MouseAt: geometry.V(11, 0), Cell: grid.P(-1, 1)},
+		"PastBottomCornerOfOriginCell": {MouseAt: geometry.V(0, -5.5), Cell: grid.P(-1, -1)},
+		"PastLeftCornerOfOriginCell":   {MouseAt: geometry.V(-11, 0), Cell: grid.P(1, -1)},
+
+		"UpAndRightOfOriginCell": {MouseAt: geometry.V(6, 3), Cell: grid.P(0, 1)},
+		"UpAndLeftOfOriginCell":  {MouseAt: geometry.V(-6, 3), Cell: grid.P(1, 0)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			input := testinput.Source{}
+			input.Mock.MousePosition = func() geometry.Vec { return tt.MouseAt }
+
+			// when
+			cell := dims.UnderCursor(input, ui.Camera{})
+
+			// then
+			assert.That(cell == tt.Cell, t.Errorf, "got cell %#v, want %#v", cell, tt.Cell)
+		})
+	}
+}
+
+func TestIsometricGridDimmensionsExtentCoversAllCells(t *testing.T) {
+	// given
+	dims := ui.DimetricDimensions(20)
+	space := grid.NewSpace()
+	space.At(grid.P(0, 0)).Create()
+	space.At(grid.P(1, 2)).Create()
+
+	// when
+	extent := dims.Extent(space)
+
+	// then
+	want := geometry.Rect{Min: geometry.V(-20, -5), Max: geometry.V(30, 20)}
+	assert.That(extent == want, t.Errorf, "got extent %#v, want %#v", extent, want)
+}
+
+func TestGridDimmensionsFooting(t *testing.T) {
+	tests := map[string]struct {
+		Dims    ui.GridDimensions
+		Footing geometry.Vec
+	}{
+		"TopDown":   {Dims: ui.GridDimensions{CellWidth: 20, CellHeight: 10}, Footing: geometry.V(0, -3)},
+		"Isometric": {Dims: ui.DimetricDimensions(20)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			footing := tt.Dims.Footing(-2)
+
+			// then
+			assert.That(footing == tt.Footing, t.Errorf, "got footing %#v, want %#v", footing, tt.Footing)
+		})
+	}
+}
+
+func TestGridDimmensionsCellRect(t *testing.T) {
+	// given
+	dims := ui.GridDimensions{CellWidth: 20, CellHeight: 10}
+
+	// when
+	rect := dims.CellRect(grid.P(2, -1))
+
+	// then
+	want := geometry.Rect{Min: geometry.V(-30, 15), Max: geometry.V(-10, 25)}
+	assert.That(rect == want, t.Errorf, "got rect %#v, want %#v", rect, want)
+}
+
+func TestGridDimmensionsWorldToCellInvertsCellToWorld(t *testing.T) {
+	property := func(sc PickingScenario) bool {
+		center := sc.Dims.CellToWorld(sc.Cell)
+		return sc.Dims.WorldToCell(center) == sc.Cell
+	}
+
+	err := quick.Check(property, nil)
+
+	assert.That(err == nil, t.Errorf, "%v", err)
+}
+
+func TestGridDimmensionsCellRectContainsTheWholeCell(t *testing.T) {
+	property := func(sc PickingScenario) bool {
+		rect := sc.Dims.CellRect(sc.Cell)
+		matrix := sc.Dims.Matrix(sc.Cell.Column, sc.Cell.Row)
+		return rect.Contains(matrix.Apply(sc.InCell)) &&
+			rect.Contains(sc.Dims.CellToWorld(sc.Cell))
+	}
+
+	err := quick.Check(property, nil)
+
+	assert.That(err == nil, t.Errorf, "%v", err)
+}
+
+func TestGridDimmensionsUnderCursorInvertsMatrix(t *testing.T) {
+	property := func(sc PickingScenario) bool {
+		bounds := geometry.R(0, 0, 800, 600)
+		onScreen := sc.Camera.Matrix(bounds).
+			Compose(sc.Dims.Matrix(sc.Cell.Column, sc.Cell.Row)).
+			Apply(sc.InCell)
+
+		input := testinput.Source{}
+		input.Mock.Bounds = func() geometry.Rect { return bounds }
+		input.Mock.MousePosition = func() geometry.Vec { return onScreen }
+
+		return sc.Dims.UnderCursor(input, sc.Camera) == sc.Cell
+	}
+
+	err := quick.Check(property, nil)
+
+	assert.That(err == nil, t.Errorf, "%v", err)
+}
+
+// A PickingScenario is a random point inside a random cell, seen through a random camera.
+type PickingScenario struct {
+	Dims   ui.GridDimensions
+	Camera ui.Camera
+	Cell   grid.Point
+	// InCell is a point strictly inside the cell, in the cell's own coordinate system.
+	InCell geometry.Vec
+}
+
+var _ quick.Generator = PickingScenario{}
+
+func (PickingScenario) Generate(rnd *rand.Rand, _ int) reflect.Value {
+	sc := PickingScenario{}
+
+	between := func(min, max float64) float64 { return min + rnd.Float64()*(max-min) }
+
+	sc.Dims = ui.GridDimensions{
+		CellWidth:  between(1, 100),
+		CellHeight: between(1, 100),
+	}
+	if rnd.Intn(2) == 0 {
+		sc.Dims = ui.DimetricDimensions(between(1, 100))
+		sc.Dims.CellHeight = between(1, 100)
+	}
+
+	sc.Camera = ui.NewCamera(geometry.V(between(-1000, 1000), between(-1000, 1000)))
+	sc.Camera.ZoomBy(between(ui.DefaultMinZoom, ui.DefaultMaxZoom))
+
+	sc.Cell = grid.P(rnd.Intn(201)-100, rnd.Intn(201)-100)
+
+	// Stay clear of the edges so that rounding errors do not move the point to a neighbouring cell.
+	size := sc.Dims.CellWidth
+	height := sc.Dims.CellHeight
+	if sc.Dims.Projection == ui.Isometric {
+		height = size
+	}
+	sc.InCell = geometry.V(
+		between(-0.49, 0.49)*size,
+		between(-0.49, 0.49)*height)
+
+	return reflect.ValueOf(sc)
+}
@@ -5,6 +5,8 @@
 package ui
 
 import (
+	"sort"
+
 	"github.com/szabba/tob-cob/game/grid"
 	"github.com/szabba/tob-cob/ui/draw"
 	"github.com/szabba/tob-cob/ui/geometry"
@@ -15,11 +17,52 @@ type GridOutline struct {
 	Space   *grid.Space
 	Dims    GridDimensions
 	Margins Margins
+
+	cells *_OutlineCells
 }
 
 type Margins struct{ X, Y float64 }
 
+// NewGridOutline creates an outline that follows the positions being created and destroyed in the space.
+// Unlike one that is not created with it, it does not look through all the points between the space's Min and Max to draw.
+//
+// Close stops it from following the space.
+func NewGridOutline(sprite Sprite, space *grid.Space, dims GridDimensions, margins Margins) GridOutline {
+	cells := &_OutlineCells{exist: map[grid.Point]bool{}}
+	for _, pos := range space.Positions() {
+		cells.exist[pos.AtPoint()] = true
+	}
+	cells.sorted = make([]grid.Point, 0, len(cells.exist))
+	cells.dirty = true
+	cells.sub = space.Subscribe(cells.update)
+
+	return GridOutline{
+		Sprite:  sprite,
+		Space:   space,
+		Dims:    dims,
+		Margins: margins,
+		cells:   cells,
+	}
+}
+
+// Close stops the outline from following the changes to its space.
+// It then goes back to looking through all the points between the space's Min and Max.
+func (o GridOutline) Close() {
+	if o.cells == nil {
+		return
+	}
+	o.cells.sub.Cancel()
+	o.cells.closed = true
+}
+
 func (o GridOutline) Draw(dst draw.Target) {
+	if o.cells != nil && !o.cells.closed {
+		for _, pt := range o.cells.points() {
+			o.drawCell(dst, pt)
+		}
+		return
+	}
+
 	min, max := o.Space.Min(), o.Space.Max()
 	var pt grid.Point
 	for pt.Row = min.Row; pt.Row <= max.Row; pt.Row++ {
@@ -50,3 +93,43 @@ func (o GridOutline) drawCell(dst draw.Target, pt grid.Point) {
 func (o GridOutline) cellMatrix(pt grid.Point) geometry.Mat {
 	return o.Dims.Matrix(pt.Column, pt.Row)
 }
+
+// _OutlineCells are the points where positions exist in the space, kept up to date with its events.
+type _OutlineCells struct {
+	sub    *grid.Subscription
+	exist  map[grid.Point]bool
+	sorted []grid.Point
+	dirty  bool
+	closed bool
+}
+
+func (cells *_OutlineCells) update(ev grid.Event) {
+	switch ev.Kind {
+	case grid.PositionCreated:
+		cells.exist[ev.At] = true
+		cells.dirty = true
+	case grid.PositionDestroyed:
+		delete(cells.exist, ev.At)
+		cells.dirty = true
+	}
+}
+
+// points are ordered by row and then by column, the same as when looking through the space.
+func (cells *_OutlineCells) points() []grid.Point {
+	if !cells.dirty {
+		return cells.sorted
+	}
+	cells.sorted = cells.sorted[:0]
+	for pt := range cells.exist {
+		cells.sorted = append(cells.sorted, pt)
+	}
+	sort.Slice(cells.sorted, func(i, j int) bool {
+		a, b := cells.sorted[i], cells.sorted[j]
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Column < b.Column
+	})
+	cells.dirty = false
+	return cells.sorted
+}
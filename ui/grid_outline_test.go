@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theslice"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw/testdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+func TestGridOutlineDrawsTheCellsThatExist(t *testing.T) {
+	// given
+	kases := map[string]func(space *grid.Space, sprite ui.Sprite, dims ui.GridDimensions) ui.GridOutline{
+		"Literal": func(space *grid.Space, sprite ui.Sprite, dims ui.GridDimensions) ui.GridOutline {
+			return ui.GridOutline{Sprite: sprite, Space: space, Dims: dims}
+		},
+		"Following": func(space *grid.Space, sprite ui.Sprite, dims ui.GridDimensions) ui.GridOutline {
+			return ui.NewGridOutline(sprite, space, dims, ui.Margins{})
+		},
+	}
+
+	for name, newOutline := range kases {
+		t.Run(name, func(t *testing.T) {
+			dst := &testdraw.Target{}
+			dims := ui.GridDimensions{CellWidth: 10, CellHeight: 10}
+			sprite := ui.NewSprite(dst.Import(image.NewRGBA(image.Rect(0, 0, 10, 10))), ui.AnchorCenter())
+
+			space := grid.NewSpace()
+			for _, pt := range []grid.Point{grid.P(1, 1), grid.P(0, 2), grid.P(0, 0)} {
+				space.At(pt).Create()
+			}
+
+			outline := newOutline(space, sprite, dims)
+
+			// when
+			space.At(grid.P(0, 2)).Destroy()
+			space.At(grid.P(1, 0)).Create()
+			outline.Draw(dst)
+
+			// then
+			want := []geometry.Vec{
+				dims.CellToWorld(grid.P(0, 0)),
+				dims.CellToWorld(grid.P(1, 0)),
+				dims.CellToWorld(grid.P(1, 1)),
+			}
+			assert.Using(t.Errorf).That(theslice.Equal(drawnAt(dst), want))
+		})
+	}
+}
+
+func TestClosedGridOutlineStillDrawsTheCellsThatExist(t *testing.T) {
+	// given
+	dst := &testdraw.Target{}
+	dims := ui.GridDimensions{CellWidth: 10, CellHeight: 10}
+	sprite := ui.NewSprite(dst.Import(image.NewRGBA(image.Rect(0, 0, 10, 10))), ui.AnchorCenter())
+
+	space := grid.NewSpace()
+	space.At(grid.P(0, 0)).Create()
+
+	outline := ui.NewGridOutline(sprite, space, dims, ui.Margins{})
+	outline.Close()
+
+	// when
+	space.At(grid.P(0, 1)).Create()
+	outline.Draw(dst)
+
+	// then
+	want := []geometry.Vec{
+		dims.CellToWorld(grid.P(0, 0)),
+		dims.CellToWorld(grid.P(0, 1)),
+	}
+	assert.Using(t.Errorf).That(theslice.Equal(drawnAt(dst), want))
+}
+
+// drawnAt are the points the drawn images were moved to.
+func drawnAt(dst *testdraw.Target) []geometry.Vec {
+	var at []geometry.Vec
+	for _, d := range dst.Draws {
+		at = append(at, d.Matrix.Apply(geometry.Vec{}))
+	}
+	return at
+}
@@ -19,22 +19,35 @@ func btn() Button {
 
 var lastID int
 
-func MouseButtonLeft() Button { return mbLeft }
+func MouseButtonLeft() Button  { return mbLeft }
+func MouseButtonRight() Button { return mbRight }
 
 func KeyF() Button { return keyF }
 
+// KeyShift is either of the shift keys.
+func KeyShift() Button { return keyShift }
+
 func KeyLeft() Button  { return keyLeft }
 func KeyUp() Button    { return keyUp }
 func KeyRight() Button { return keyRight }
 func KeyDown() Button  { return keyDown }
 
+func KeyEqual() Button { return keyEqual }
+func KeyMinus() Button { return keyMinus }
+
 var (
-	mbLeft = btn()
+	mbLeft  = btn()
+	mbRight = btn()
 
 	keyF = btn()
 
+	keyShift = btn()
+
 	keyLeft  = btn()
 	keyUp    = btn()
 	keyRight = btn()
 	keyDown  = btn()
+
+	keyEqual = btn()
+	keyMinus = btn()
 )
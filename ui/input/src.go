@@ -15,5 +15,6 @@ type Source interface {
 	Pressed(btn Button) bool
 	MousePosition() geometry.Vec
 	MouseInsideWindow() bool
+	Wheel() geometry.Vec
 	Bounds() geometry.Rect
 }
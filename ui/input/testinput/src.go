@@ -11,21 +11,46 @@ import (
 
 type Source struct {
 	Mock struct {
+		Focused           func() bool
+		JustPressed       func(btn input.Button) bool
+		JustReleased      func(btn input.Button) bool
+		Pressed           func(btn input.Button) bool
 		Bounds            func() geometry.Rect
 		MousePosition     func() geometry.Vec
 		MouseInsideWindow func() bool
+		Wheel             func() geometry.Vec
 	}
 }
 
 var _ input.Source = Source{}
 
-func (Source) Focused() bool { return false }
+func (src Source) Focused() bool {
+	if src.Mock.Focused == nil {
+		return false
+	}
+	return src.Mock.Focused()
+}
 
-func (Source) JustReleased(_ input.Button) bool { return false }
+func (src Source) JustReleased(btn input.Button) bool {
+	if src.Mock.JustReleased == nil {
+		return false
+	}
+	return src.Mock.JustReleased(btn)
+}
 
-func (Source) JustPressed(_ input.Button) bool { return false }
+func (src Source) JustPressed(btn input.Button) bool {
+	if src.Mock.JustPressed == nil {
+		return false
+	}
+	return src.Mock.JustPressed(btn)
+}
 
-func (Source) Pressed(_ input.Button) bool { return false }
+func (src Source) Pressed(btn input.Button) bool {
+	if src.Mock.Pressed == nil {
+		return false
+	}
+	return src.Mock.Pressed(btn)
+}
 
 func (src Source) Bounds() geometry.Rect {
 	if src.Mock.Bounds == nil {
@@ -47,3 +72,10 @@ func (src Source) MousePosition() geometry.Vec {
 	}
 	return src.Mock.MousePosition()
 }
+
+func (src Source) Wheel() geometry.Vec {
+	if src.Mock.Wheel == nil {
+		return geometry.Vec{}
+	}
+	return src.Mock.Wheel()
+}
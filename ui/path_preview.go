@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/input"
+)
+
+// DefaultReachableColor is what color a path preview uses for paths that reach the hovered cell.
+var DefaultReachableColor color.Color = color.RGBA{R: 0x40, G: 0xc0, B: 0x40, A: 0xff}
+
+// DefaultUnreachableColor is what color a path preview uses to mark hovered cells that cannot be reached.
+var DefaultUnreachableColor color.Color = color.RGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0xff}
+
+// A PathPreview shows the path a placement would take to reach the hovered cell.
+//
+// The path only gets searched for again when the hovered cell or the cell the path starts at changes, or when the space changes.
+type PathPreview struct {
+	Dims GridDimensions
+
+	// FindPath searches for paths the same way grid.PathFinder does.
+	FindPath func(src, dst grid.Position) (grid.Path, bool)
+
+	ReachableColor   color.Color
+	UnreachableColor color.Color
+
+	space   *grid.Space
+	sub     *grid.Subscription
+	stale   bool
+	showing bool
+	from    grid.Point
+	to      grid.Point
+	path    grid.Path
+	reaches bool
+}
+
+// NewPathPreview creates a path preview for placements within the space.
+// It follows the changes to the space, so that the path it shows does not go stale as things move around.
+//
+// Close stops it from following the space.
+func NewPathPreview(space *grid.Space, dims GridDimensions) *PathPreview {
+	if space == nil {
+		panic("nil space")
+	}
+	p := &PathPreview{
+		Dims:             dims,
+		FindPath:         grid.NewPathFinder(space).FindPath,
+		ReachableColor:   DefaultReachableColor,
+		UnreachableColor: DefaultUnreachableColor,
+		space:            space,
+	}
+	p.sub = space.Subscribe(func(grid.Event) { p.Invalidate() })
+	return p
+}
+
+// Close stops the preview from following the changes to its space.
+// After that, only Invalidate makes it search for the path to the same cell again.
+func (p *PathPreview) Close() {
+	if p.sub != nil {
+		p.sub.Cancel()
+	}
+}
+
+// Update previews the path to the cell under the mouse cursor.
+func (p *PathPreview) Update(placement *grid.HeadedPlacement, src input.Source, cam Camera) {
+	p.Hover(placement, p.Dims.UnderCursor(src, cam))
+}
+
+// Hover previews the path the placement would take to the hovered cell.
+//
+// A placement that is on its way somewhere would start from where it is heading to.
+// Nothing gets shown when the placement is not placed or when it is already at the hovered cell.
+func (p *PathPreview) Hover(placement *grid.HeadedPlacement, hovered grid.Point) {
+	if !placement.Placed() {
+		p.Hide()
+		return
+	}
+
+	from := placement.AtPoint()
+	if placement.Headed() {
+		from = placement.Heading()
+	}
+
+	if from == hovered {
+		p.Hide()
+		return
+	}
+
+	if p.showing && !p.stale && p.from == from && p.to == hovered {
+		return
+	}
+
+	p.showing, p.stale = true, false
+	p.from, p.to = from, hovered
+	p.path, p.reaches = p.FindPath(p.space.At(from), p.space.At(hovered))
+}
+
+// Hide stops showing the path until the next call to Hover.
+func (p *PathPreview) Hide() {
+	p.showing = false
+	p.path = nil
+	p.reaches = false
+}
+
+// Invalidate makes the path get searched for again on the next call to Hover, even when the hovered cell does not change.
+// Until then, the old path keeps being shown.
+func (p *PathPreview) Invalidate() { p.stale = true }
+
+// Path is the previewed path and whether it reaches the hovered cell.
+// The path is empty when nothing is shown.
+func (p *PathPreview) Path() (path grid.Path, reaches bool) { return p.path, p.reaches }
+
+// Draw the preview on the target, which is expected to use world coordinates.
+//
+// A path that reaches the hovered cell is drawn as dots along a line ending with an arrow.
+// Otherwise the hovered cell is crossed out.
+func (p *PathPreview) Draw(dst draw.Target) {
+	if !p.showing {
+		return
+	}
+
+	if !p.reaches {
+		p.drawCross(dst, p.Dims.CellToWorld(p.to))
+		return
+	}
+
+	points := make([]geometry.Vec, len(p.path))
+	for i, pos := range p.path {
+		points[i] = p.Dims.CellToWorld(pos.AtPoint())
+	}
+
+	if len(points) < 2 {
+		return
+	}
+
+	unit := p.unit()
+	dst.Polyline(points, unit/8, p.ReachableColor)
+	for _, pt := range points[1 : len(points)-1] {
+		dst.FillCircle(pt, unit/8, p.ReachableColor)
+	}
+	p.drawArrowHead(dst, points[len(points)-2], points[len(points)-1])
+}
+
+func (p *PathPreview) drawArrowHead(dst draw.Target, from, to geometry.Vec) {
+	unit := p.unit()
+	back := from.Sub(to)
+	back = back.Scaled(unit / 3 / back.Len())
+
+	for _, angle := range []float64{math.Pi / 6, -math.Pi / 6} {
+		wing := geometry.Rotation(angle).Apply(back)
+		dst.Line(to, to.Add(wing), unit/8, p.ReachableColor)
+	}
+}
+
+func (p *PathPreview) drawCross(dst draw.Target, at geometry.Vec) {
+	arm := p.unit() / 4
+	dst.Line(at.Add(geometry.V(-arm, -arm)), at.Add(geometry.V(arm, arm)), arm/2, p.UnreachableColor)
+	dst.Line(at.Add(geometry.V(-arm, arm)), at.Add(geometry.V(arm, -arm)), arm/2, p.UnreachableColor)
+}
+
+// unit is the size preview marks are scaled by, so that they fit within cells.
+func (p *PathPreview) unit() float64 {
+	return math.Min(p.Dims.CellWidth, p.Dims.CellHeight)
+}
@@ -0,0 +1,251 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theslice"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw/testdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+func TestPathPreviewFindsPathToHoveredCell(t *testing.T) {
+	// given
+	space, placement := previewSpace()
+	preview := ui.NewPathPreview(space, previewDims())
+
+	// when
+	preview.Hover(placement, grid.P(0, 3))
+
+	// then
+	path, reaches := preview.Path()
+	assert.Using(t.Errorf).
+		That(reaches, "the path does not reach the hovered cell").
+		That(theslice.Length(path, 4))
+}
+
+func TestPathPreviewDoesNotReachMissingCell(t *testing.T) {
+	// given
+	space, placement := previewSpace()
+	preview := ui.NewPathPreview(space, previewDims())
+
+	// when
+	preview.Hover(placement, grid.P(5, 5))
+
+	// then
+	_, reaches := preview.Path()
+	assert.Using(t.Errorf).That(!reaches, "the path reaches a cell that does not exist")
+}
+
+func TestPathPreviewSearchesAgainOnlyWhenItHasTo(t *testing.T) {
+	kases := map[string]struct {
+		Then     func(preview *ui.PathPreview, placement *grid.HeadedPlacement, space *grid.Space)
+		Searches int
+	}{
+		"SameCellHoveredAgain": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, _ *grid.Space) {
+				preview.Hover(placement, grid.P(0, 3))
+			},
+			Searches: 1,
+		},
+		"OtherCellHovered": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, _ *grid.Space) {
+				preview.Hover(placement, grid.P(0, 2))
+			},
+			Searches: 2,
+		},
+		"PlacementMoved": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, space *grid.Space) {
+				placement.Place(space.At(grid.P(0, 1)))
+				preview.Hover(placement, grid.P(0, 3))
+			},
+			Searches: 2,
+		},
+		"Invalidated": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, _ *grid.Space) {
+				preview.Invalidate()
+				preview.Hover(placement, grid.P(0, 3))
+			},
+			Searches: 2,
+		},
+		"PositionOnTheWayTaken": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, space *grid.Space) {
+				space.At(grid.P(0, 2)).Take(grid.DummyTaker())
+				preview.Hover(placement, grid.P(0, 3))
+			},
+			Searches: 2,
+		},
+		"PositionOnTheWayTakenAfterClose": {
+			Then: func(preview *ui.PathPreview, placement *grid.HeadedPlacement, space *grid.Space) {
+				preview.Close()
+				space.At(grid.P(0, 2)).Take(grid.DummyTaker())
+				preview.Hover(placement, grid.P(0, 3))
+			},
+			Searches: 1,
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space, placement := previewSpace()
+			preview := ui.NewPathPreview(space, previewDims())
+
+			searches := 0
+			finder := grid.NewPathFinder(space)
+			preview.FindPath = func(src, dst grid.Position) (grid.Path, bool) {
+				searches++
+				return finder.FindPath(src, dst)
+			}
+
+			preview.Hover(placement, grid.P(0, 3))
+
+			// when
+			kase.Then(preview, placement, space)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(searches, kase.Searches))
+		})
+	}
+}
+
+func TestPathPreviewDrawsReachablePath(t *testing.T) {
+	// given
+	space, placement := previewSpace()
+	preview := ui.NewPathPreview(space, previewDims())
+	preview.Hover(placement, grid.P(0, 2))
+
+	dst := &testdraw.Target{}
+
+	// when
+	preview.Draw(dst)
+
+	// then
+	kinds := shapeKinds(dst.Shapes)
+	assert.Using(t.Errorf).That(theslice.Equal(kinds, []testdraw.ShapeKind{
+		testdraw.PolylineShape,
+		testdraw.FillCircleShape,
+		testdraw.LineShape,
+		testdraw.LineShape,
+	}))
+	if len(dst.Shapes) == 0 {
+		return
+	}
+	assert.Using(t.Errorf).
+		That(theslice.Equal(dst.Shapes[0].Points, []geometry.Vec{
+			geometry.V(0, 0), geometry.V(10, 0), geometry.V(20, 0),
+		})).
+		That(theval.Equal(dst.Shapes[0].Color, ui.DefaultReachableColor))
+}
+
+func TestPathPreviewCrossesOutUnreachableCell(t *testing.T) {
+	// given
+	space, placement := previewSpace()
+	preview := ui.NewPathPreview(space, previewDims())
+	preview.Hover(placement, grid.P(5, 5))
+
+	dst := &testdraw.Target{}
+
+	// when
+	preview.Draw(dst)
+
+	// then
+	kinds := shapeKinds(dst.Shapes)
+	assert.Using(t.Errorf).That(theslice.Equal(kinds, []testdraw.ShapeKind{
+		testdraw.LineShape,
+		testdraw.LineShape,
+	}))
+	if len(dst.Shapes) == 0 {
+		return
+	}
+	assert.Using(t.Errorf).That(theval.Equal(dst.Shapes[0].Color, ui.DefaultUnreachableColor))
+}
+
+func TestPathPreviewStopsReachingCellThatGetsTaken(t *testing.T) {
+	// given
+	space, placement := previewSpace()
+	preview := ui.NewPathPreview(space, previewDims())
+	preview.Hover(placement, grid.P(0, 3))
+
+	// when
+	space.At(grid.P(0, 3)).Take(grid.DummyTaker())
+	preview.Hover(placement, grid.P(0, 3))
+
+	// then
+	_, reaches := preview.Path()
+	assert.Using(t.Errorf).That(!reaches, "the path reaches a cell that got taken")
+}
+
+func TestPathPreviewDrawsNothing(t *testing.T) {
+	kases := map[string]struct {
+		Setup func(preview *ui.PathPreview, placement *grid.HeadedPlacement)
+	}{
+		"NothingHovered": {
+			Setup: func(_ *ui.PathPreview, _ *grid.HeadedPlacement) {},
+		},
+		"OwnCellHovered": {
+			Setup: func(preview *ui.PathPreview, placement *grid.HeadedPlacement) {
+				preview.Hover(placement, placement.AtPoint())
+			},
+		},
+		"NotPlaced": {
+			Setup: func(preview *ui.PathPreview, _ *grid.HeadedPlacement) {
+				preview.Hover(&grid.HeadedPlacement{}, grid.P(0, 3))
+			},
+		},
+		"Hidden": {
+			Setup: func(preview *ui.PathPreview, placement *grid.HeadedPlacement) {
+				preview.Hover(placement, grid.P(0, 3))
+				preview.Hide()
+			},
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			space, placement := previewSpace()
+			preview := ui.NewPathPreview(space, previewDims())
+			kase.Setup(preview, placement)
+
+			dst := &testdraw.Target{}
+
+			// when
+			preview.Draw(dst)
+
+			// then
+			assert.Using(t.Errorf).That(theval.Equal(len(dst.Shapes), 0))
+		})
+	}
+}
+
+// previewSpace is a single row of four cells, with a placement in the leftmost one.
+func previewSpace() (*grid.Space, *grid.HeadedPlacement) {
+	space := grid.NewSpace()
+	for column := 0; column < 4; column++ {
+		space.At(grid.P(0, column)).Create()
+	}
+	placement := &grid.HeadedPlacement{}
+	placement.Place(space.At(grid.P(0, 0)))
+	return space, placement
+}
+
+func previewDims() ui.GridDimensions {
+	return ui.GridDimensions{CellWidth: 10, CellHeight: 10}
+}
+
+func shapeKinds(shapes []testdraw.Shape) []testdraw.ShapeKind {
+	kinds := make([]testdraw.ShapeKind, len(shapes))
+	for i, s := range shapes {
+		kinds[i] = s.Kind
+	}
+	return kinds
+}
@@ -0,0 +1,213 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/input"
+)
+
+// DefaultSelectionColor is what color selected placements and the selection box get highlighted with.
+var DefaultSelectionColor color.Color = color.RGBA{R: 0xf0, G: 0xe0, B: 0x40, A: 0xff}
+
+// A Selection is the set of placements that the player gives orders to.
+// It keeps them in the order they were selected in.
+//
+// The zero value is an empty selection.
+type Selection struct {
+	selected []*grid.HeadedPlacement
+}
+
+// Selected are the selected placements.
+// The returned slice must not be modified.
+func (s *Selection) Selected() []*grid.HeadedPlacement { return s.selected }
+
+// Empty says whether nothing is selected.
+func (s *Selection) Empty() bool { return len(s.selected) == 0 }
+
+// Contains says whether p is selected.
+func (s *Selection) Contains(p *grid.HeadedPlacement) bool { return s.index(p) >= 0 }
+
+// Set selects exactly the given placements.
+func (s *Selection) Set(ps ...*grid.HeadedPlacement) {
+	s.Clear()
+	s.Add(ps...)
+}
+
+// Add selects the given placements, in addition to the ones already selected.
+func (s *Selection) Add(ps ...*grid.HeadedPlacement) {
+	for _, p := range ps {
+		if !s.Contains(p) {
+			s.selected = append(s.selected, p)
+		}
+	}
+}
+
+// Toggle selects p when it is not selected and deselects it otherwise.
+func (s *Selection) Toggle(p *grid.HeadedPlacement) {
+	i := s.index(p)
+	if i < 0 {
+		s.selected = append(s.selected, p)
+		return
+	}
+	s.selected = append(s.selected[:i], s.selected[i+1:]...)
+}
+
+// Clear deselects everything.
+func (s *Selection) Clear() { s.selected = nil }
+
+// Keep deselects the placements keep says no to.
+// The rest stay selected in the same order.
+func (s *Selection) Keep(keep func(p *grid.HeadedPlacement) bool) {
+	kept := s.selected[:0]
+	for _, p := range s.selected {
+		if keep(p) {
+			kept = append(kept, p)
+		}
+	}
+	s.selected = kept
+}
+
+func (s *Selection) index(p *grid.HeadedPlacement) int {
+	for i, selected := range s.selected {
+		if selected == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// Draw a ring around each selected placement.
+// The target is expected to use world coordinates.
+func (s *Selection) Draw(dst draw.Target, dims GridDimensions, c color.Color) {
+	unit := math.Min(dims.CellWidth, dims.CellHeight)
+	for _, p := range s.selected {
+		if !p.Placed() {
+			continue
+		}
+		dst.StrokeCircle(dims.PlacementCenter(p), unit*0.4, unit/15, c)
+	}
+}
+
+// _DragThreshold is how far the mouse has to move, in pixels, for a click to become a drag.
+const _DragThreshold = 4
+
+// A SelectionController changes a selection based on player input.
+//
+// Clicking selects the placement in the clicked cell, or clears the selection when there is none.
+// Dragging selects the placements within the dragged out box.
+// With shift held, a click toggles the clicked placement and a box adds to the selection.
+type SelectionController struct {
+	selection *Selection
+	dims      GridDimensions
+
+	pressed  bool
+	from, to geometry.Vec
+	dragging bool
+}
+
+// NewSelectionController creates a controller for the selection.
+func NewSelectionController(selection *Selection, dims GridDimensions) *SelectionController {
+	if selection == nil {
+		panic("nil selection")
+	}
+	return &SelectionController{
+		selection: selection,
+		dims:      dims,
+	}
+}
+
+// Process the input, picking placements from the candidates.
+func (c *SelectionController) Process(src input.Source, cam Camera, candidates []*grid.HeadedPlacement) {
+	mouse := src.MousePosition()
+
+	if src.JustPressed(input.MouseButtonLeft()) {
+		c.pressed = true
+		c.dragging = false
+		c.from, c.to = mouse, mouse
+	}
+
+	if !c.pressed {
+		return
+	}
+
+	c.to = mouse
+	if c.to.Sub(c.from).Len() >= _DragThreshold {
+		c.dragging = true
+	}
+
+	if !src.JustReleased(input.MouseButtonLeft()) {
+		return
+	}
+	c.pressed = false
+
+	shift := src.Pressed(input.KeyShift())
+	if c.dragging {
+		c.dragging = false
+		c.selectInBox(src, cam, candidates, shift)
+		return
+	}
+	c.selectClicked(src, cam, candidates, shift)
+}
+
+func (c *SelectionController) selectClicked(src input.Source, cam Camera, candidates []*grid.HeadedPlacement, shift bool) {
+	clicked := c.dims.UnderCursor(src, cam)
+
+	var found *grid.HeadedPlacement
+	for _, p := range candidates {
+		if p.Placed() && c.dims.WorldToCell(c.dims.PlacementCenter(p)) == clicked {
+			found = p
+			break
+		}
+	}
+
+	switch {
+	case found == nil && !shift:
+		c.selection.Clear()
+	case found == nil:
+	case shift:
+		c.selection.Toggle(found)
+	default:
+		c.selection.Set(found)
+	}
+}
+
+func (c *SelectionController) selectInBox(src input.Source, cam Camera, candidates []*grid.HeadedPlacement, shift bool) {
+	box := geometry.Bounding(c.from, c.to)
+	toScreen := cam.Matrix(src.Bounds())
+
+	if !shift {
+		c.selection.Clear()
+	}
+	for _, p := range candidates {
+		if p.Placed() && box.Contains(toScreen.Apply(c.dims.PlacementCenter(p))) {
+			c.selection.Add(p)
+		}
+	}
+}
+
+// Box is the selection box being dragged out, on screen.
+// It reports false when nothing is being dragged.
+func (c *SelectionController) Box() (geometry.Rect, bool) {
+	if !c.dragging {
+		return geometry.Rect{}, false
+	}
+	return geometry.Bounding(c.from, c.to), true
+}
+
+// DrawBox draws the selection box being dragged out, if any.
+// The target is expected to use screen coordinates.
+func (c *SelectionController) DrawBox(dst draw.Target, col color.Color) {
+	box, ok := c.Box()
+	if !ok {
+		return
+	}
+	dst.StrokeRect(box, 1, col)
+}
@@ -0,0 +1,254 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theslice"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/game/grid"
+	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw/testdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/input"
+	"github.com/szabba/tob-cob/ui/input/testinput"
+)
+
+func TestSelectionAddSkipsDuplicates(t *testing.T) {
+	// given
+	a, b := &grid.HeadedPlacement{}, &grid.HeadedPlacement{}
+	sel := ui.Selection{}
+	sel.Add(a)
+
+	// when
+	sel.Add(b, a)
+
+	// then
+	assert.Using(t.Errorf).That(theslice.Equal(sel.Selected(), []*grid.HeadedPlacement{a, b}))
+}
+
+func TestSelectionToggle(t *testing.T) {
+	// given
+	a, b := &grid.HeadedPlacement{}, &grid.HeadedPlacement{}
+	sel := ui.Selection{}
+	sel.Set(a, b)
+
+	// when
+	sel.Toggle(a)
+
+	// then
+	assert.Using(t.Errorf).
+		That(!sel.Contains(a), "the toggled placement is still selected").
+		That(sel.Contains(b), "the other placement got deselected")
+
+	// when
+	sel.Toggle(a)
+
+	// then
+	assert.Using(t.Errorf).That(theslice.Equal(sel.Selected(), []*grid.HeadedPlacement{b, a}))
+}
+
+func TestSelectionSetReplacesSelection(t *testing.T) {
+	// given
+	a, b := &grid.HeadedPlacement{}, &grid.HeadedPlacement{}
+	sel := ui.Selection{}
+	sel.Set(a)
+
+	// when
+	sel.Set(b)
+
+	// then
+	assert.Using(t.Errorf).That(theslice.Equal(sel.Selected(), []*grid.HeadedPlacement{b}))
+}
+
+func TestSelectionKeep(t *testing.T) {
+	// given
+	a, b, c := &grid.HeadedPlacement{}, &grid.HeadedPlacement{}, &grid.HeadedPlacement{}
+	sel := ui.Selection{}
+	sel.Set(a, b, c)
+
+	// when
+	sel.Keep(func(p *grid.HeadedPlacement) bool { return p != b })
+
+	// then
+	assert.Using(t.Errorf).That(theslice.Equal(sel.Selected(), []*grid.HeadedPlacement{a, c}))
+}
+
+func TestSelectionDrawsRingsAroundSelected(t *testing.T) {
+	// given
+	_, placements := selectionScene()
+	sel := ui.Selection{}
+	sel.Set(placements[1], &grid.HeadedPlacement{})
+
+	dst := &testdraw.Target{}
+
+	// when
+	sel.Draw(dst, selectionDims(), ui.DefaultSelectionColor)
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(len(dst.Shapes), 1))
+	if len(dst.Shapes) != 1 {
+		return
+	}
+	assert.Using(t.Errorf).
+		That(theval.Equal(dst.Shapes[0].Kind, testdraw.StrokeCircleShape)).
+		That(theslice.Equal(dst.Shapes[0].Points, []geometry.Vec{geometry.V(10, 0)}))
+}
+
+func TestSelectionController(t *testing.T) {
+	kases := map[string]struct {
+		Before []int
+		Frames []selectionFrame
+		After  []int
+	}{
+		"ClickSelects": {
+			Before: []int{0},
+			Frames: click(geometry.V(10, 0), false),
+			After:  []int{1},
+		},
+		"ClickOnEmptyCellClears": {
+			Before: []int{0},
+			Frames: click(geometry.V(20, 0), false),
+			After:  nil,
+		},
+		"ShiftClickAdds": {
+			Before: []int{0},
+			Frames: click(geometry.V(10, 0), true),
+			After:  []int{0, 1},
+		},
+		"ShiftClickRemoves": {
+			Before: []int{0, 1},
+			Frames: click(geometry.V(10, 0), true),
+			After:  []int{0},
+		},
+		"ShiftClickOnEmptyCellKeeps": {
+			Before: []int{0},
+			Frames: click(geometry.V(20, 0), true),
+			After:  []int{0},
+		},
+		"SlightMoveIsStillAClick": {
+			Frames: drag(geometry.V(10, 0), geometry.V(12, 1), false),
+			After:  []int{1},
+		},
+		"DragSelectsWithinTheBox": {
+			Before: []int{2},
+			Frames: drag(geometry.V(-5, -5), geometry.V(15, 5), false),
+			After:  []int{0, 1},
+		},
+		"ShiftDragAdds": {
+			Before: []int{2},
+			Frames: drag(geometry.V(15, 5), geometry.V(5, -5), true),
+			After:  []int{2, 1},
+		},
+		"NothingHappensWithoutRelease": {
+			Before: []int{2},
+			Frames: click(geometry.V(10, 0), false)[:1],
+			After:  []int{2},
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			_, placements := selectionScene()
+			sel := &ui.Selection{}
+			for _, i := range kase.Before {
+				sel.Add(placements[i])
+			}
+			cont := ui.NewSelectionController(sel, selectionDims())
+			cam := ui.NewCamera(geometry.Vec{})
+
+			// when
+			for _, frame := range kase.Frames {
+				cont.Process(frame.source(), cam, placements)
+			}
+
+			// then
+			var want []*grid.HeadedPlacement
+			for _, i := range kase.After {
+				want = append(want, placements[i])
+			}
+			assert.Using(t.Errorf).That(theslice.Equal(sel.Selected(), want))
+		})
+	}
+}
+
+func TestSelectionControllerBox(t *testing.T) {
+	// given
+	_, placements := selectionScene()
+	cont := ui.NewSelectionController(&ui.Selection{}, selectionDims())
+	cam := ui.NewCamera(geometry.Vec{})
+
+	frames := drag(geometry.V(15, 5), geometry.V(-5, -5), false)
+
+	// when
+	for _, frame := range frames[:len(frames)-1] {
+		cont.Process(frame.source(), cam, placements)
+	}
+
+	// then
+	box, ok := cont.Box()
+	assert.Using(t.Errorf).
+		That(ok, "there is no box while dragging").
+		That(theval.Equal(box, geometry.R(5, 0, 10, 5)))
+
+	// when
+	last := frames[len(frames)-1]
+	cont.Process(last.source(), cam, placements)
+
+	// then
+	_, ok = cont.Box()
+	assert.Using(t.Errorf).That(!ok, "there is a box after the drag ended")
+}
+
+// selectionScene has placements in the cells at columns 0, 1 and 3 of the row 0.
+func selectionScene() (*grid.Space, []*grid.HeadedPlacement) {
+	space := grid.NewSpace()
+	for column := 0; column < 4; column++ {
+		space.At(grid.P(0, column)).Create()
+	}
+	placements := []*grid.HeadedPlacement{{}, {}, {}}
+	placements[0].Place(space.At(grid.P(0, 0)))
+	placements[1].Place(space.At(grid.P(0, 1)))
+	placements[2].Place(space.At(grid.P(0, 3)))
+	return space, placements
+}
+
+func selectionDims() ui.GridDimensions {
+	return ui.GridDimensions{CellWidth: 10, CellHeight: 10}
+}
+
+type selectionFrame struct {
+	Mouse                     geometry.Vec
+	JustPressed, JustReleased bool
+	Shift                     bool
+}
+
+func (f selectionFrame) source() input.Source {
+	src := testinput.Source{}
+	src.Mock.MousePosition = func() geometry.Vec { return f.Mouse }
+	src.Mock.JustPressed = func(btn input.Button) bool { return f.JustPressed && btn == input.MouseButtonLeft() }
+	src.Mock.JustReleased = func(btn input.Button) bool { return f.JustReleased && btn == input.MouseButtonLeft() }
+	src.Mock.Pressed = func(btn input.Button) bool { return f.Shift && btn == input.KeyShift() }
+	return src
+}
+
+func click(at geometry.Vec, shift bool) []selectionFrame {
+	return []selectionFrame{
+		{Mouse: at, JustPressed: true, Shift: shift},
+		{Mouse: at, JustReleased: true, Shift: shift},
+	}
+}
+
+func drag(from, to geometry.Vec, shift bool) []selectionFrame {
+	return []selectionFrame{
+		{Mouse: from, JustPressed: true, Shift: shift},
+		{Mouse: from.Lerp(to, 0.5), Shift: shift},
+		{Mouse: to, JustReleased: true, Shift: shift},
+	}
+}
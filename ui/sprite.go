@@ -50,6 +50,7 @@ type Sprite struct {
 	img       draw.Image
 	anchor    geometry.Vec // in the image bounds coordinate system
 	transform geometry.Mat
+	opts      draw.Options
 }
 
 func NewSprite(img draw.Image, anchor Anchor) Sprite {
@@ -68,9 +69,18 @@ func NewSprite(img draw.Image, anchor Anchor) Sprite {
 }
 
 func (s Sprite) Draw() {
-	s.img.Draw(s.transform, s.anchor)
+	s.img.DrawWith(s.transform, s.anchor, s.opts)
 }
 
+// WithOptions changes how the sprite gets drawn, eg. to tint it or fade it out.
+func (s Sprite) WithOptions(opts draw.Options) Sprite {
+	s.opts = opts
+	return s
+}
+
+// Options say how the sprite gets drawn.
+func (s Sprite) Options() draw.Options { return s.opts }
+
 func (s Sprite) Transform(m geometry.Mat) Sprite {
 	s.transform = m.Compose(s.transform)
 	return s
@@ -78,6 +88,9 @@ func (s Sprite) Transform(m geometry.Mat) Sprite {
 
 // An OrderedSpriteGroup keeps track of a bunch of sprites and knows how to draw in the correct order.
 // This assumes all the sprites are anchored at their bottom.
+//
+// Sprites standing further up in world coordinates get drawn first.
+// That puts them behind the ones in front both on top-down and isometric grids.
 type OrderedSpriteGroup struct {
 	order []Sprite
 }
@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// A SpriteSheet is an image divided into a grid of equally sized frames.
+//
+// Frames are numbered by column from the left and by row from the top, starting at zero.
+type SpriteSheet struct {
+	img                     draw.Image
+	frameWidth, frameHeight float64
+	columns, rows           int
+}
+
+// NewSpriteSheet divides img into frames of the given size.
+// Any part of the image that is too small to fit a whole frame is left out.
+func NewSpriteSheet(img draw.Image, frameWidth, frameHeight float64) SpriteSheet {
+	if img == nil {
+		panic("nil image")
+	}
+	if frameWidth <= 0 || frameHeight <= 0 {
+		panic(fmt.Sprintf("invalid frame size %vx%v", frameWidth, frameHeight))
+	}
+	bounds := img.Bounds()
+	return SpriteSheet{
+		img:         img,
+		frameWidth:  frameWidth,
+		frameHeight: frameHeight,
+		columns:     int(math.Floor(bounds.W() / frameWidth)),
+		rows:        int(math.Floor(bounds.H() / frameHeight)),
+	}
+}
+
+// Size says how many columns and rows of frames the sheet has.
+func (sheet SpriteSheet) Size() (columns, rows int) { return sheet.columns, sheet.rows }
+
+// Frame returns the frame at the given column and row.
+//
+// It panics when there is no such frame.
+func (sheet SpriteSheet) Frame(column, row int) draw.Image {
+	if column < 0 || sheet.columns <= column || row < 0 || sheet.rows <= row {
+		panic(fmt.Sprintf(
+			"frame (%d, %d) outside of %dx%d sprite sheet",
+			column, row, sheet.columns, sheet.rows))
+	}
+
+	bounds := sheet.img.Bounds()
+	// Rows are counted from the top, while the Y axis of the bounds points up.
+	left := bounds.Min.X + float64(column)*sheet.frameWidth
+	bottom := bounds.Max.Y - float64(row+1)*sheet.frameHeight
+	r := geometry.R(left, bottom, sheet.frameWidth, sheet.frameHeight)
+	return sheet.img.SubImage(r)
+}
+
+// Row returns all the frames in a row, from left to right.
+func (sheet SpriteSheet) Row(row int) []draw.Image {
+	frames := make([]draw.Image, sheet.columns)
+	for col := range frames {
+		frames[col] = sheet.Frame(col, row)
+	}
+	return frames
+}
@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+func TestSpriteSheetSize(t *testing.T) {
+	// given
+	img := &SheetImage{Size: geometry.V(100, 50)}
+
+	// when
+	sheet := ui.NewSpriteSheet(img, 32, 16)
+
+	// then
+	cols, rows := sheet.Size()
+	assert.Using(t.Errorf).
+		That(theval.Equal(cols, 3)).
+		That(theval.Equal(rows, 3))
+}
+
+func TestSpriteSheetFrame(t *testing.T) {
+	kases := map[string]struct {
+		Column, Row int
+		Region      geometry.Rect
+	}{
+		"TopLeft":     {Region: geometry.R(0, 32, 32, 16)},
+		"TopRight":    {Column: 1, Region: geometry.R(32, 32, 32, 16)},
+		"BottomLeft":  {Row: 2, Region: geometry.R(0, 0, 32, 16)},
+		"MiddleRight": {Column: 1, Row: 1, Region: geometry.R(32, 16, 32, 16)},
+	}
+
+	for name, tt := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			img := &SheetImage{Size: geometry.V(64, 48)}
+			sheet := ui.NewSpriteSheet(img, 32, 16)
+
+			// when
+			frame := sheet.Frame(tt.Column, tt.Row)
+
+			// then
+			assert.Using(t.Errorf).
+				That(theval.Equal(frame.(*SheetImage).Region, tt.Region)).
+				That(theval.Equal(frame.Bounds(), geometry.R(0, 0, 32, 16)))
+		})
+	}
+}
+
+func TestSpriteSheetRow(t *testing.T) {
+	// given
+	img := &SheetImage{Size: geometry.V(96, 16)}
+	sheet := ui.NewSpriteSheet(img, 32, 16)
+
+	// when
+	row := sheet.Row(0)
+
+	// then
+	assert.Using(t.Fatalf).That(theval.Equal(len(row), 3))
+	for i, frame := range row {
+		region := geometry.R(float64(32*i), 0, 32, 16)
+		assert.Using(t.Errorf).That(theval.Equal(frame.(*SheetImage).Region, region))
+	}
+}
+
+// A SheetImage remembers what part of the original image it is.
+type SheetImage struct {
+	Size   geometry.Vec
+	Region geometry.Rect
+}
+
+func (img *SheetImage) Bounds() geometry.Rect { return geometry.R(0, 0, img.Size.X, img.Size.Y) }
+
+func (*SheetImage) Draw(_ geometry.Mat, _ geometry.Vec) {}
+
+func (*SheetImage) DrawWith(_ geometry.Mat, _ geometry.Vec, _ draw.Options) {}
+
+func (img *SheetImage) SubImage(r geometry.Rect) draw.Image {
+	offset := img.Region.Min
+	return &SheetImage{
+		Size:   geometry.V(r.W(), r.H()),
+		Region: geometry.Rect{Min: r.Min.Add(offset), Max: r.Max.Add(offset)},
+	}
+}
@@ -5,12 +5,14 @@
 package ui_test
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/szabba/assert/v2"
 	"github.com/szabba/assert/v2/assertions/theval"
 
 	"github.com/szabba/tob-cob/ui"
+	"github.com/szabba/tob-cob/ui/draw"
 	"github.com/szabba/tob-cob/ui/geometry"
 )
 
@@ -52,3 +54,71 @@ func TestAnchor(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderedSpriteGroupDrawsIsometricCellsFromBackToFront(t *testing.T) {
+	// given
+	dims := ui.DimetricDimensions(20)
+	drawn := []string{}
+
+	group := ui.OrderedSpriteGroup{}
+	for _, cell := range []struct {
+		Name        string
+		Column, Row int
+	}{
+		{"front", 0, 0},
+		{"back", 1, 1},
+		{"left", 0, 1},
+		{"far-back", 2, 2},
+	} {
+		img := &RecordingImage{Name: cell.Name, Drawn: &drawn}
+		center := dims.Matrix(cell.Column, cell.Row).Apply(geometry.Vec{})
+		sprite := ui.NewSprite(img, ui.AnchorSouth()).Transform(geometry.Translation(center))
+		group.Add(sprite)
+	}
+
+	// when
+	group.Draw()
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(len(drawn), 4)).
+		That(theval.Equal(drawn[0], "far-back")).
+		That(theval.Equal(drawn[1], "back")).
+		That(theval.Equal(drawn[2], "left")).
+		That(theval.Equal(drawn[3], "front"))
+}
+
+type RecordingImage struct {
+	Name    string
+	Drawn   *[]string
+	Options []draw.Options
+}
+
+func (img *RecordingImage) Bounds() geometry.Rect { return geometry.R(0, 0, 10, 20) }
+
+func (img *RecordingImage) Draw(_ geometry.Mat, _ geometry.Vec) {
+	img.DrawWith(geometry.Mat{}, geometry.Vec{}, draw.DefaultOptions())
+}
+
+func (img *RecordingImage) DrawWith(_ geometry.Mat, _ geometry.Vec, opts draw.Options) {
+	*img.Drawn = append(*img.Drawn, img.Name)
+	img.Options = append(img.Options, opts)
+}
+
+func (img *RecordingImage) SubImage(_ geometry.Rect) draw.Image { return img }
+
+func TestSpriteIsDrawnWithItsOptions(t *testing.T) {
+	// given
+	drawn := []string{}
+	img := &RecordingImage{Name: "img", Drawn: &drawn}
+	opts := draw.DefaultOptions().WithTint(color.NRGBA{R: 0xff, A: 0xff}).WithAlpha(0.25)
+
+	sprite := ui.NewSprite(img, ui.AnchorSouth()).WithOptions(opts)
+
+	// when
+	sprite.Draw()
+
+	// then
+	assert.Using(t.Fatalf).That(theval.Equal(len(img.Options), 1))
+	assert.Using(t.Errorf).That(theval.Equal(img.Options[0], opts))
+}
@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text
+
+import (
+	"fmt"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// PrintableASCII are the printable ASCII characters, from space to tilde.
+const PrintableASCII = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+// A BitmapFont is a monospace Font with glyphs cut out of a single image.
+//
+// The baseline runs along the bottom edge of the glyphs.
+type BitmapFont struct {
+	glyphs        map[rune]draw.Image
+	width, height float64
+}
+
+var _ Font = new(BitmapFont)
+
+// NewBitmapFont cuts glyphs of the given size out of sheet.
+// The glyphs are laid out left to right and then top to bottom, in the order of the runes in chars.
+// Runes that do not fit in the sheet are left out.
+func NewBitmapFont(sheet draw.Image, glyphWidth, glyphHeight float64, chars string) *BitmapFont {
+	if sheet == nil {
+		panic("nil image")
+	}
+	if glyphWidth <= 0 || glyphHeight <= 0 {
+		panic(fmt.Sprintf("invalid glyph size %vx%v", glyphWidth, glyphHeight))
+	}
+
+	bounds := sheet.Bounds()
+	columns := int(bounds.W() / glyphWidth)
+	rows := int(bounds.H() / glyphHeight)
+
+	font := &BitmapFont{
+		glyphs: map[rune]draw.Image{},
+		width:  glyphWidth,
+		height: glyphHeight,
+	}
+
+	if columns == 0 {
+		return font
+	}
+
+	i := 0
+	for _, r := range chars {
+		column, row := i%columns, i/columns
+		if row >= rows {
+			break
+		}
+		// Rows are counted from the top, while the Y axis of the bounds points up.
+		left := bounds.Min.X + float64(column)*glyphWidth
+		bottom := bounds.Max.Y - float64(row+1)*glyphHeight
+		font.glyphs[r] = sheet.SubImage(geometry.R(left, bottom, glyphWidth, glyphHeight))
+		i++
+	}
+
+	return font
+}
+
+func (f *BitmapFont) Metrics() Metrics {
+	return Metrics{Ascent: f.height, LineHeight: f.height}
+}
+
+// Advance is the same for every rune, even the ones the font has no glyph for.
+func (f *BitmapFont) Advance(_ rune) float64 { return f.width }
+
+func (f *BitmapFont) Kern(_, _ rune) float64 { return 0 }
+
+func (f *BitmapFont) Glyph(_ draw.Target, r rune) (Glyph, bool) {
+	img, ok := f.glyphs[r]
+	return Glyph{Image: img}, ok
+}
@@ -0,0 +1,145 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text
+
+import (
+	"fmt"
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// A Face is a Font that rasterizes glyphs from a font.Face the first time they get drawn.
+//
+// The rasterized glyphs are kept for as long as the face keeps drawing on the same target.
+type Face struct {
+	face font.Face
+
+	importedTo draw.Target
+	glyphs     map[rune]_CachedGlyph
+}
+
+type _CachedGlyph struct {
+	glyph Glyph
+	ok    bool
+}
+
+var _ Font = new(Face)
+
+// NewFace creates a font that draws glyphs from face.
+func NewFace(face font.Face) *Face {
+	if face == nil {
+		panic("nil face")
+	}
+	return &Face{face: face}
+}
+
+// ParseFace reads a TrueType or OpenType font from data and prepares it to draw glyphs of the given size.
+// The size is in the units that the text gets drawn with.
+func ParseFace(data []byte, size float64) (*Face, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid font size %v", size)
+	}
+
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse font: %w", err)
+	}
+
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create font face: %w", err)
+	}
+
+	return NewFace(face), nil
+}
+
+// Basic is a small bitmap font covering ASCII, good enough for debug overlays.
+func Basic() *Face { return NewFace(basicfont.Face7x13) }
+
+func (f *Face) Metrics() Metrics {
+	m := f.face.Metrics()
+	return Metrics{
+		Ascent:     fromFixed(m.Ascent),
+		Descent:    fromFixed(m.Descent),
+		LineHeight: fromFixed(m.Height),
+	}
+}
+
+func (f *Face) Advance(r rune) float64 {
+	advance, ok := f.face.GlyphAdvance(r)
+	if !ok {
+		return 0
+	}
+	return fromFixed(advance)
+}
+
+func (f *Face) Kern(prev, r rune) float64 { return fromFixed(f.face.Kern(prev, r)) }
+
+func (f *Face) Glyph(dst draw.Target, r rune) (Glyph, bool) {
+	if f.importedTo != dst {
+		f.importedTo = dst
+		f.glyphs = map[rune]_CachedGlyph{}
+	}
+
+	cached, ok := f.glyphs[r]
+	if !ok {
+		cached.glyph, cached.ok = f.rasterize(dst, r)
+		f.glyphs[r] = cached
+	}
+	return cached.glyph, cached.ok
+}
+
+func (f *Face) rasterize(dst draw.Target, r rune) (Glyph, bool) {
+	bounds, _, ok := f.face.GlyphBounds(r)
+	if !ok {
+		return Glyph{}, false
+	}
+
+	// The bounds are relative to the pen position and have the Y axis pointing down.
+	left, top := bounds.Min.X.Floor(), bounds.Min.Y.Floor()
+	right, bottom := bounds.Max.X.Ceil(), bounds.Max.Y.Ceil()
+	if left >= right || top >= bottom {
+		return Glyph{}, false
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, right-left, bottom-top))
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.White,
+		Face: f.face,
+		Dot:  fixed.P(-left, -top),
+	}
+	drawer.DrawString(string(r))
+	if blank(img) {
+		return Glyph{}, false
+	}
+
+	return Glyph{
+		Image:  dst.Import(img),
+		Origin: geometry.V(float64(-left), float64(bottom)),
+	}, true
+}
+
+func blank(img *image.RGBA) bool {
+	for _, b := range img.Pix {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func fromFixed(x fixed.Int26_6) float64 { return float64(x) / 64 }
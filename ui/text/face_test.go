@@ -0,0 +1,124 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theerr"
+	"github.com/szabba/assert/v2/assertions/theval"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/szabba/tob-cob/ui/draw/softdraw"
+	"github.com/szabba/tob-cob/ui/draw/testdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/text"
+)
+
+func TestBasicMetrics(t *testing.T) {
+	// given
+	font := text.Basic()
+
+	// when
+	metrics := font.Metrics()
+
+	// then
+	assert.Using(t.Errorf).
+		That(theval.Equal(metrics, text.Metrics{Ascent: 11, Descent: 2, LineHeight: 13})).
+		That(theval.Equal(font.Advance('W'), 7.0))
+}
+
+func TestFaceGlyphIsImportedOncePerTarget(t *testing.T) {
+	// given
+	font := text.Basic()
+	first, second := &testdraw.Target{}, &testdraw.Target{}
+
+	// when
+	a, aOK := font.Glyph(first, 'x')
+	again, againOK := font.Glyph(first, 'x')
+	other, otherOK := font.Glyph(second, 'x')
+
+	// then
+	assert.Using(t.Errorf).
+		That(aOK && againOK && otherOK, "some glyph is missing").
+		That(a == again, "the glyph was imported twice into the same target").
+		That(a != other, "the glyph was not imported into the second target")
+}
+
+func TestFaceHasNoGlyphForSpace(t *testing.T) {
+	// given
+	font := text.Basic()
+
+	// when
+	_, ok := font.Glyph(&testdraw.Target{}, ' ')
+
+	// then
+	assert.Using(t.Errorf).
+		That(!ok, "there is a glyph for space").
+		That(font.Advance(' ') > 0, "space does not move the pen")
+}
+
+func TestFaceDrawsText(t *testing.T) {
+	// given
+	red := color.RGBA{R: 0xff, A: 0xff}
+	dst := softdraw.NewTarget(40, 20)
+	layout := text.NewLayout(text.Basic(), "#", text.Style{})
+
+	// when
+	layout.Draw(dst, geometry.Translation(geometry.V(5, 5)), geometry.Vec{}, red)
+
+	// then
+	reds := 0
+	for x := 0.5; x < 40; x++ {
+		for y := 0.5; y < 20; y++ {
+			if dst.At(geometry.V(x, y)) == red {
+				reds++
+			}
+		}
+	}
+	assert.Using(t.Errorf).That(reds > 0, "no pixels were drawn")
+}
+
+func TestParseFace(t *testing.T) {
+	// given
+	data := goregular.TTF
+
+	// when
+	font, err := text.ParseFace(data, 16)
+
+	// then
+	assert.Using(t.Errorf).That(theerr.IsNil(err))
+	if err != nil {
+		return
+	}
+	metrics := font.Metrics()
+	assert.Using(t.Errorf).
+		That(metrics.Ascent > 0, "ascent %v is not positive", metrics.Ascent).
+		That(metrics.LineHeight >= metrics.Ascent, "line height %v is below the ascent %v", metrics.LineHeight, metrics.Ascent)
+}
+
+func TestParseFaceFails(t *testing.T) {
+	kases := map[string]struct {
+		Data []byte
+		Size float64
+	}{
+		"NotAFont":    {Data: []byte("not a font"), Size: 16},
+		"InvalidSize": {Data: goregular.TTF, Size: 0},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+
+			// when
+			_, err := text.ParseFace(kase.Data, kase.Size)
+
+			// then
+			assert.Using(t.Errorf).That(err != nil, "error is nil")
+		})
+	}
+}
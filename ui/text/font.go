@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package text lays out and draws text.
+//
+// Like everything else that gets drawn, text uses coordinates with the Y axis pointing up.
+package text
+
+import (
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// A Font knows how big glyphs are and what they look like.
+type Font interface {
+	// Metrics of the font as a whole.
+	Metrics() Metrics
+
+	// Advance is how far right the pen moves after drawing r.
+	Advance(r rune) float64
+
+	// Kern is how much the advance between prev and r needs to be adjusted.
+	Kern(prev, r rune) float64
+
+	// Glyph finds the image of r, importing it into dst when needed.
+	// It reports false when there is nothing to draw for r.
+	Glyph(dst draw.Target, r rune) (Glyph, bool)
+}
+
+// Metrics describe a font in the vertical direction.
+type Metrics struct {
+	// Ascent is how far above the baseline glyphs reach.
+	Ascent float64
+	// Descent is how far below the baseline glyphs reach.
+	Descent float64
+	// LineHeight is the distance between the baselines of consecutive lines.
+	LineHeight float64
+}
+
+// A Glyph is the image of a single rune.
+type Glyph struct {
+	Image draw.Image
+	// Origin is the point of the image that gets put at the pen position on the baseline.
+	// It uses the same coordinate system as the bounds of the image do.
+	Origin geometry.Vec
+}
+
+// Measure says how wide s is when drawn on a single line.
+func Measure(font Font, s string) float64 {
+	width := 0.0
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			width += font.Kern(prev, r)
+		}
+		width += font.Advance(r)
+		prev = r
+	}
+	return width
+}
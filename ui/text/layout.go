@@ -0,0 +1,167 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text
+
+import (
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/szabba/tob-cob/ui/draw"
+	"github.com/szabba/tob-cob/ui/geometry"
+)
+
+// Align says where lines go horizontally when they are narrower than the text as a whole.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// A Style says how text gets laid out.
+type Style struct {
+	Align Align
+
+	// MaxWidth is how wide a line can get before it is wrapped.
+	// Lines are wrapped between words when possible, and within words that do not fit on a line of their own.
+	// Zero means lines only break at newlines.
+	MaxWidth float64
+}
+
+// A Layout is text broken into lines and positioned, ready to be drawn.
+type Layout struct {
+	font  Font
+	lines []Line
+	size  geometry.Vec
+}
+
+// A Line is a single line of a layout.
+type Line struct {
+	Text string
+	// Start is where the pen starts drawing the line, on its baseline.
+	Start geometry.Vec
+	Width float64
+}
+
+// NewLayout lays out s drawn with font in the given style.
+func NewLayout(font Font, s string, style Style) Layout {
+	if font == nil {
+		panic("nil font")
+	}
+
+	var texts []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		texts = append(texts, wrap(font, paragraph, style.MaxWidth)...)
+	}
+
+	lines := make([]Line, len(texts))
+	width := style.MaxWidth
+	for i, text := range texts {
+		lines[i] = Line{Text: text, Width: Measure(font, text)}
+		if style.MaxWidth <= 0 && lines[i].Width > width {
+			width = lines[i].Width
+		}
+	}
+
+	metrics := font.Metrics()
+	height := metrics.Ascent + metrics.Descent + float64(len(lines)-1)*metrics.LineHeight
+
+	for i := range lines {
+		lines[i].Start = geometry.V(
+			style.Align.offset(width, lines[i].Width),
+			height-metrics.Ascent-float64(i)*metrics.LineHeight)
+	}
+
+	return Layout{
+		font:  font,
+		lines: lines,
+		size:  geometry.V(width, height),
+	}
+}
+
+func (a Align) offset(width, lineWidth float64) float64 {
+	switch a {
+	case AlignCenter:
+		return (width - lineWidth) / 2
+	case AlignRight:
+		return width - lineWidth
+	default:
+		return 0
+	}
+}
+
+func wrap(font Font, paragraph string, maxWidth float64) []string {
+	if maxWidth <= 0 {
+		return []string{paragraph}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(paragraph) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if Measure(font, candidate) <= maxWidth {
+			line = candidate
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		for utf8.RuneCountInString(line) > 1 && Measure(font, line) > maxWidth {
+			var head string
+			head, line = breakWord(font, line, maxWidth)
+			lines = append(lines, head)
+		}
+	}
+	return append(lines, line)
+}
+
+// breakWord splits off as much of the start of word as fits in maxWidth.
+// The start is at least one rune long, even when that rune alone does not fit.
+func breakWord(font Font, word string, maxWidth float64) (head, tail string) {
+	for i, r := range word {
+		end := i + utf8.RuneLen(r)
+		if i > 0 && Measure(font, word[:end]) > maxWidth {
+			return word[:i], word[i:]
+		}
+	}
+	return word, ""
+}
+
+// Lines of the layout, from the top down.
+func (l Layout) Lines() []Line { return l.lines }
+
+// Bounds of the laid out text.
+// Just like with images, the bottom-left corner is the minimum and it is at the origin.
+func (l Layout) Bounds() geometry.Rect { return geometry.R(0, 0, l.size.X, l.size.Y) }
+
+// Draw the text on dst in the color c.
+// The anchor and the matrix m work just like they do when drawing an image.
+func (l Layout) Draw(dst draw.Target, m geometry.Mat, anchor geometry.Vec, c color.Color) {
+	opts := draw.DefaultOptions().WithTint(c)
+
+	for _, line := range l.lines {
+		pen := line.Start.Sub(anchor)
+		prev := rune(-1)
+
+		for _, r := range line.Text {
+			if prev >= 0 {
+				pen.X += l.font.Kern(prev, r)
+			}
+			if glyph, ok := l.font.Glyph(dst, r); ok {
+				glyphM := m.Compose(geometry.Translation(pen))
+				glyph.Image.DrawWith(glyphM, glyph.Origin, opts)
+			}
+			pen.X += l.font.Advance(r)
+			prev = r
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/szabba/assert/v2"
+	"github.com/szabba/assert/v2/assertions/theval"
+
+	"github.com/szabba/tob-cob/ui/draw/testdraw"
+	"github.com/szabba/tob-cob/ui/geometry"
+	"github.com/szabba/tob-cob/ui/text"
+)
+
+func TestMeasure(t *testing.T) {
+	// given
+	font := tinyFont(&testdraw.Target{})
+
+	// when
+	width := text.Measure(font, "abc")
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(width, 6.0))
+}
+
+func TestLayoutLines(t *testing.T) {
+	kases := map[string]struct {
+		Text   string
+		Style  text.Style
+		Lines  []text.Line
+		Bounds geometry.Rect
+	}{
+		"Empty": {
+			Text:   "",
+			Lines:  []text.Line{{Text: ""}},
+			Bounds: geometry.R(0, 0, 0, 3),
+		},
+		"SingleLine": {
+			Text:   "abc",
+			Lines:  []text.Line{{Text: "abc", Width: 6}},
+			Bounds: geometry.R(0, 0, 6, 3),
+		},
+		"Newline": {
+			Text: "ab\nc",
+			Lines: []text.Line{
+				{Text: "ab", Start: geometry.V(0, 3), Width: 4},
+				{Text: "c", Width: 2},
+			},
+			Bounds: geometry.R(0, 0, 4, 6),
+		},
+		"WrappedBetweenWords": {
+			Text:  "ab cd ef",
+			Style: text.Style{MaxWidth: 10},
+			Lines: []text.Line{
+				{Text: "ab cd", Start: geometry.V(0, 3), Width: 10},
+				{Text: "ef", Width: 4},
+			},
+			Bounds: geometry.R(0, 0, 10, 6),
+		},
+		"WrappedWithinAWord": {
+			Text:  "abcdefg",
+			Style: text.Style{MaxWidth: 5},
+			Lines: []text.Line{
+				{Text: "ab", Start: geometry.V(0, 9), Width: 4},
+				{Text: "cd", Start: geometry.V(0, 6), Width: 4},
+				{Text: "ef", Start: geometry.V(0, 3), Width: 4},
+				{Text: "g", Width: 2},
+			},
+			Bounds: geometry.R(0, 0, 5, 12),
+		},
+		"WrappedWithARuneWiderThanTheLine": {
+			Text:  "ab",
+			Style: text.Style{MaxWidth: 1},
+			Lines: []text.Line{
+				{Text: "a", Start: geometry.V(0, 3), Width: 2},
+				{Text: "b", Width: 2},
+			},
+			Bounds: geometry.R(0, 0, 1, 6),
+		},
+		"AlignedToCenter": {
+			Text:  "abcd\nab",
+			Style: text.Style{Align: text.AlignCenter},
+			Lines: []text.Line{
+				{Text: "abcd", Start: geometry.V(0, 3), Width: 8},
+				{Text: "ab", Start: geometry.V(2, 0), Width: 4},
+			},
+			Bounds: geometry.R(0, 0, 8, 6),
+		},
+		"AlignedToTheRight": {
+			Text:  "abcd\nab",
+			Style: text.Style{Align: text.AlignRight},
+			Lines: []text.Line{
+				{Text: "abcd", Start: geometry.V(0, 3), Width: 8},
+				{Text: "ab", Start: geometry.V(4, 0), Width: 4},
+			},
+			Bounds: geometry.R(0, 0, 8, 6),
+		},
+		"AlignedToTheRightOfMaxWidth": {
+			Text:  "ab",
+			Style: text.Style{Align: text.AlignRight, MaxWidth: 10},
+			Lines: []text.Line{
+				{Text: "ab", Start: geometry.V(6, 0), Width: 4},
+			},
+			Bounds: geometry.R(0, 0, 10, 3),
+		},
+	}
+
+	for name, kase := range kases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			font := tinyFont(&testdraw.Target{})
+
+			// when
+			layout := text.NewLayout(font, kase.Text, kase.Style)
+
+			// then
+			assert.Using(t.Errorf).
+				That(theval.Equal(len(layout.Lines()), len(kase.Lines))).
+				That(theval.Equal(layout.Bounds(), kase.Bounds))
+			for i := 0; i < len(kase.Lines) && i < len(layout.Lines()); i++ {
+				assert.Using(t.Errorf).That(theval.Equal(layout.Lines()[i], kase.Lines[i]))
+			}
+		})
+	}
+}
+
+func TestLayoutDraw(t *testing.T) {
+	// given
+	dst := &testdraw.Target{}
+	font := tinyFont(dst)
+	layout := text.NewLayout(font, "a b\nc", text.Style{})
+
+	m := geometry.Translation(geometry.V(100, 100))
+	anchor := geometry.V(3, 3)
+
+	// when
+	layout.Draw(dst, m, anchor, color.RGBA{R: 0xff, A: 0xff})
+
+	// then
+	assert.Using(t.Errorf).That(theval.Equal(len(dst.Draws), 3))
+	if len(dst.Draws) != 3 {
+		return
+	}
+
+	origins := []geometry.Vec{
+		dst.Draws[0].Matrix.Apply(dst.Draws[0].Anchor),
+		dst.Draws[1].Matrix.Apply(dst.Draws[1].Anchor),
+		dst.Draws[2].Matrix.Apply(dst.Draws[2].Anchor),
+	}
+	assert.Using(t.Errorf).
+		That(theval.Equal(origins[0], geometry.V(97, 100))).
+		That(theval.Equal(origins[1], geometry.V(101, 100))).
+		That(theval.Equal(origins[2], geometry.V(97, 97))).
+		That(theval.Equal(dst.Draws[0].Options.Tint(), color.Color(color.RGBA{R: 0xff, A: 0xff})))
+}
+
+// tinyFont has 2x3 glyphs for the runes a to h.
+func tinyFont(dst *testdraw.Target) *text.BitmapFont {
+	sheet := dst.Import(image.NewRGBA(image.Rect(0, 0, 8, 6)))
+	return text.NewBitmapFont(sheet, 2, 3, "abcdefgh")
+}